@@ -5,19 +5,31 @@ import (
 	"Restaurant/database"
 	_ "Restaurant/docs"
 	"Restaurant/internal/controller"
+	appMiddleware "Restaurant/internal/middleware"
+	"Restaurant/internal/payment"
+	"Restaurant/internal/receipt"
 	"Restaurant/internal/repository"
 	"Restaurant/internal/response"
 	"Restaurant/internal/service"
+	"Restaurant/internal/storage"
 	"Restaurant/utils/enums"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/swaggo/echo-swagger"
 	"log"
+	"math"
 	"net/http"
+	"time"
 )
 
 func main() {
 	cfg := config.DBLoadConfig()
+	storageCfg := config.StorageLoadConfig()
+	authCfg := config.AuthLoadConfig()
+	tableSessionCfg := config.TableSessionLoadConfig()
+	paymentCfg := config.PaymentLoadConfig()
+	billCfg := config.BillLoadConfig()
+	reservationCfg := config.ReservationLoadConfig()
 	config.SetTimeZone("Asia/Bangkok")
 	dataSourceName := cfg.DBUser + ":" + cfg.DBPassword + "@tcp(" + cfg.DBHost + ":" + cfg.DBPort + ")/" + cfg.DBName + "?parseTime=true"
 	database.InitDB(dataSourceName)
@@ -25,7 +37,7 @@ func main() {
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"http://localhost:5173"},
 		AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodPatch, http.MethodDelete},
-		AllowHeaders: []string{"Content-Type"},
+		AllowHeaders: []string{echo.HeaderContentType, echo.HeaderAuthorization},
 	}))
 	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -52,21 +64,125 @@ func main() {
 		}
 	})
 	restaurantRepo := &repository.MySQLRestaurantRepository{}
-	restaurantService := &service.RestaurantService{RestaurantRepo: restaurantRepo}
+	userRepo := &repository.MySQLUserRepository{}
+	imageStorage := &storage.LocalImageStorage{Dir: storageCfg.ImageDir, BaseURL: storageCfg.ImageBaseURL}
+	paymentProviders, err := payment.NewRegistry(paymentCfg.Provider)
+	if err != nil {
+		log.Fatalf("Failed to set up payments: %v", err)
+	}
+	var receiptFont *receipt.Font
+	if billCfg.ReceiptFont != "" {
+		receiptFont, err = receipt.LoadFont(billCfg.ReceiptFont)
+		if err != nil {
+			log.Fatalf("Failed to load RECEIPT_FONT: %v", err)
+		}
+	}
+	restaurantService := &service.RestaurantService{
+		RestaurantRepo:   restaurantRepo,
+		ImageStorage:     imageStorage,
+		TokenSecret:      []byte(authCfg.JWTSecret),
+		TableSessionTTL:  tableSessionCfg.SessionTTL,
+		OrderBaseURL:     tableSessionCfg.OrderBaseURL,
+		KitchenFeed:      service.NewKitchenFeed(),
+		PaymentProviders: paymentProviders,
+		BillPolicy: service.BillPolicy{
+			ServiceChargeRate: billCfg.ServiceChargeRate,
+			VatRate:           billCfg.VatRate,
+			VatInclusive:      billCfg.VatInclusive,
+			RoundingStep:      int64(math.Round(billCfg.RoundingStep * 100)),
+		},
+		ReceiptTitle:    billCfg.ReceiptTitle,
+		ReceiptFont:     receiptFont,
+		ReceiptCodePage: billCfg.ReceiptCodePage,
+		Reservations: service.ReservationPolicy{
+			Duration: reservationCfg.Duration,
+			Hold:     reservationCfg.Hold,
+			Grace:    reservationCfg.Grace,
+		},
+	}
+	go restaurantService.RunReservationSweeper(time.Minute)
+	authService := &service.AuthService{UserRepo: userRepo, RestaurantRepo: restaurantRepo, Secret: []byte(authCfg.JWTSecret), TokenTTL: authCfg.TokenTTL}
+	authService.EnsureAdmin(authCfg.AdminUsername, authCfg.AdminPassword)
 	restaurantController := &controller.RestaurantController{RestaurantService: restaurantService}
+	authController := &controller.AuthController{AuthService: authService}
+	auth := &appMiddleware.AuthMiddleware{
+		Secret:            []byte(authCfg.JWTSecret),
+		IsSessionActive:   restaurantRepo.IsTableSessionActive,
+		FindActiveSession: restaurantRepo.FindActiveTableSession,
+		FindUser:          userRepo.FindUserById,
+	}
+	admin := auth.Require(enums.RoleAdmin)
+	staff := auth.Require(enums.RoleAdmin, enums.RoleCashier)
+	kitchen := auth.Require(enums.RoleAdmin, enums.RoleKitchen)
+	kitchenStaff := auth.Require(enums.RoleAdmin, enums.RoleCashier, enums.RoleKitchen)
+	customer := auth.Require(enums.RoleAdmin, enums.RoleCashier, enums.RoleTable)
+	anyone := auth.Require(enums.RoleAdmin, enums.RoleCashier, enums.RoleKitchen, enums.RoleTable)
 	apiV1 := e.Group("/api/v1/restaurant")
 	apiV1.GET("/swagger/*", echoSwagger.WrapHandler)
 	apiV1.GET("/", restaurantController.Home)
-	apiV1.POST("/table", restaurantController.FindTable)
-	apiV1.PATCH("/table/update", restaurantController.UpdateTable)
+	apiV1.POST("/auth/login", authController.Login)
+	apiV1.GET("/all/user", authController.GetAllUsers, admin)
+	apiV1.POST("/user/create", authController.CreateUser, admin)
+	apiV1.DELETE("/user/delete", authController.DeleteUser, admin)
+	apiV1.POST("/table", restaurantController.FindTable, customer)
+	apiV1.PATCH("/table/update", restaurantController.UpdateTable, staff)
+	apiV1.GET("/all/table", restaurantController.GetAllTables, kitchenStaff)
+	apiV1.POST("/table/number", restaurantController.FindTableByNumber, kitchenStaff)
+	apiV1.POST("/table/create", restaurantController.CreateTable, admin)
+	apiV1.PATCH("/table/renumber", restaurantController.RenumberTable, admin)
+	apiV1.DELETE("/table/delete", restaurantController.DeleteTable, admin)
+	apiV1.PATCH("/table/layout", restaurantController.UpdateTableLayout, admin)
+	apiV1.GET("/table/floor-plan", restaurantController.GetFloorPlan, staff)
+	apiV1.GET("/table/suggest", restaurantController.SuggestTables, staff)
+	apiV1.POST("/table/transfer", restaurantController.TransferTable, staff)
+	apiV1.POST("/table/merge", restaurantController.MergeTables, staff)
+	apiV1.POST("/table/split", restaurantController.SplitTables, staff)
+	apiV1.POST("/table/checkout", restaurantController.CheckoutTable, staff)
 	apiV1.GET("/all/menu", restaurantController.GetAllMenu)
-	apiV1.POST("/order/menu", restaurantController.OrderMenu)
-	apiV1.PATCH("/order/update", restaurantController.UpdateOrder)
-	apiV1.DELETE("/order/delete", restaurantController.DeleteOrder)
-	apiV1.DELETE("/order/delete/all", restaurantController.DeleteAllOrderWhenCheckOut)
-	apiV1.POST("/order/pay", restaurantController.PayOrder)
-	apiV1.POST("/order/review", restaurantController.ReviewOrder)
-	apiV1.POST("/order/details", restaurantController.OrderDetails)
-	apiV1.POST("/order/history", restaurantController.OrderHistory)
+	apiV1.POST("/menu/create", restaurantController.CreateMenu, admin)
+	apiV1.PATCH("/menu/update", restaurantController.UpdateMenu, admin)
+	apiV1.PATCH("/menu/availability", restaurantController.UpdateMenuAvailability, kitchen)
+	apiV1.DELETE("/menu/delete", restaurantController.DeleteMenu, admin)
+	apiV1.POST("/menu/image", restaurantController.UploadMenuImage, admin)
+	apiV1.GET("/images/:fileName", restaurantController.GetMenuImage)
+	apiV1.GET("/all/category", restaurantController.GetAllCategories)
+	apiV1.POST("/category/create", restaurantController.CreateCategory, admin)
+	apiV1.PATCH("/category/update", restaurantController.UpdateCategory, admin)
+	apiV1.DELETE("/category/delete", restaurantController.DeleteCategory, admin)
+	apiV1.GET("/all/promotion", restaurantController.GetAllPromotions, staff)
+	apiV1.POST("/promotion/create", restaurantController.CreatePromotion, admin)
+	apiV1.PATCH("/promotion/update", restaurantController.UpdatePromotion, admin)
+	apiV1.DELETE("/promotion/delete", restaurantController.DeletePromotion, admin)
+	apiV1.POST("/order/menu", restaurantController.OrderMenu, customer)
+	apiV1.PATCH("/order/update", restaurantController.UpdateOrder, kitchenStaff)
+	apiV1.PATCH("/order/items", restaurantController.AmendOrder, customer)
+	apiV1.PATCH("/order/item/update", restaurantController.UpdateOrderItem, kitchenStaff)
+	apiV1.DELETE("/order/delete", restaurantController.DeleteOrder, staff)
+	apiV1.POST("/order/pay", restaurantController.PayOrder, staff)
+	apiV1.GET("/order/bill", restaurantController.GetBill, staff)
+	apiV1.POST("/order/bill/split", restaurantController.SplitBill, staff)
+	apiV1.POST("/order/bill/pay", restaurantController.PayBillShare, staff)
+	apiV1.POST("/order/refund", restaurantController.RefundOrder, admin)
+	apiV1.POST("/order/refund/retry", restaurantController.RetryRefund, admin)
+	apiV1.GET("/order/receipt", restaurantController.GetReceipt, customer)
+	apiV1.POST("/order/review", restaurantController.ReviewOrder, customer)
+	apiV1.GET("/all/review", restaurantController.GetAllReviews, staff)
+	apiV1.GET("/review/summary", restaurantController.GetReviewSummary, staff)
+	apiV1.POST("/review/reply", restaurantController.ReplyReview, staff)
+	apiV1.PATCH("/review/hide", restaurantController.ModerateReview, admin)
+	apiV1.DELETE("/review/delete", restaurantController.DeleteReview, admin)
+	apiV1.GET("/all/reservation", restaurantController.GetAllReservations, staff)
+	apiV1.POST("/reservation/create", restaurantController.CreateReservation, staff)
+	apiV1.PATCH("/reservation/update", restaurantController.UpdateReservation, staff)
+	apiV1.PATCH("/reservation/status", restaurantController.UpdateReservationStatus, staff)
+	apiV1.GET("/all/waitlist", restaurantController.GetWaitlist, staff)
+	apiV1.POST("/waitlist/create", restaurantController.AddToWaitlist, staff)
+	apiV1.PATCH("/waitlist/status", restaurantController.UpdateWaitlistStatus, staff)
+	apiV1.POST("/order/details", restaurantController.OrderDetails, anyone)
+	apiV1.POST("/order/history", restaurantController.OrderHistory, anyone)
+	apiV1.GET("/kitchen/queue", restaurantController.KitchenQueue, kitchenStaff)
+	apiV1.GET("/kitchen/events", restaurantController.KitchenEvents, kitchenStaff)
+	apiV1.GET("/report/sales", restaurantController.GetSalesReport, admin)
+	apiV1.GET("/report/menu", restaurantController.GetMenuItemReport, admin)
 	e.Logger.Fatal(e.Start(":1323"))
 }
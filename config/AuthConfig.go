@@ -0,0 +1,35 @@
+package config
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+type AuthConfig struct {
+	JWTSecret     string
+	TokenTTL      time.Duration
+	AdminUsername string
+	AdminPassword string
+}
+
+func AuthLoadConfig() AuthConfig {
+	secret := os.Getenv("JWT_SECRET")
+	if len(secret) < 32 {
+		log.Fatal("JWT_SECRET must be set to at least 32 characters")
+	}
+	tokenTTL := 12 * time.Hour
+	if value := os.Getenv("TOKEN_TTL"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl <= 0 {
+			log.Fatalf("Invalid TOKEN_TTL %q: %v", value, err)
+		}
+		tokenTTL = ttl
+	}
+	return AuthConfig{
+		JWTSecret:     secret,
+		TokenTTL:      tokenTTL,
+		AdminUsername: os.Getenv("ADMIN_USERNAME"),
+		AdminPassword: os.Getenv("ADMIN_PASSWORD"),
+	}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+)
+
+type BillConfig struct {
+	ServiceChargeRate float64
+	VatRate           float64
+	VatInclusive      bool
+	RoundingStep      float64
+	ReceiptTitle      string
+	ReceiptFont       string
+	ReceiptCodePage   byte
+}
+
+func BillLoadConfig() BillConfig {
+	cfg := BillConfig{
+		ServiceChargeRate: loadRate("SERVICE_CHARGE_RATE", 10),
+		VatRate:           loadRate("VAT_RATE", 7),
+		ReceiptTitle:      os.Getenv("RECEIPT_TITLE"),
+		ReceiptFont:       os.Getenv("RECEIPT_FONT"),
+		// ESC t 21 is Thai character code 11 on Epson compatible printers
+		ReceiptCodePage: 21,
+	}
+	if cfg.ReceiptTitle == "" {
+		cfg.ReceiptTitle = "Restaurant"
+	}
+	if cfg.ReceiptFont == "" {
+		log.Println("Warning: RECEIPT_FONT is not set, PDF receipts print Thai as \"?\"")
+	}
+	if value := os.Getenv("RECEIPT_CODE_PAGE"); value != "" {
+		codePage, err := strconv.ParseUint(value, 10, 8)
+		if err != nil {
+			log.Fatalf("Invalid RECEIPT_CODE_PAGE %q: %v", value, err)
+		}
+		cfg.ReceiptCodePage = byte(codePage)
+	}
+	if value := os.Getenv("VAT_INCLUSIVE"); value != "" {
+		inclusive, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Fatalf("Invalid VAT_INCLUSIVE %q: %v", value, err)
+		}
+		cfg.VatInclusive = inclusive
+	}
+	if value := os.Getenv("BILL_ROUNDING"); value != "" {
+		step, err := strconv.ParseFloat(value, 64)
+		if err != nil || step < 0 {
+			log.Fatalf("Invalid BILL_ROUNDING %q: %v", value, err)
+		}
+		cfg.RoundingStep = step
+	}
+	return cfg
+}
+
+// loadRate reads a percentage such as "7" for 7%.
+func loadRate(name string, defaultRate float64) float64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultRate
+	}
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil || rate < 0 || rate > 100 {
+		log.Fatalf("Invalid %s %q: must be a percentage between 0 and 100", name, value)
+	}
+	return rate
+}
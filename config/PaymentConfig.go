@@ -0,0 +1,28 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+)
+
+type PaymentConfig struct {
+	Provider string
+}
+
+// PaymentLoadConfig requires the card and PromptPay provider to be named. The fake provider approves
+// every payment, so it also needs PAYMENT_ALLOW_FAKE to be set.
+func PaymentLoadConfig() PaymentConfig {
+	provider := os.Getenv("PAYMENT_PROVIDER")
+	if provider == "" {
+		log.Fatal("PAYMENT_PROVIDER must be set")
+	}
+	if provider == "fake" {
+		allowFake, err := strconv.ParseBool(os.Getenv("PAYMENT_ALLOW_FAKE"))
+		if err != nil || !allowFake {
+			log.Fatal("PAYMENT_PROVIDER fake approves every payment, set PAYMENT_ALLOW_FAKE=true to use it")
+		}
+		log.Println("Payments use the fake provider, card and PromptPay payments are not collected")
+	}
+	return PaymentConfig{Provider: provider}
+}
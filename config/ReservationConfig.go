@@ -0,0 +1,33 @@
+package config
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+type ReservationConfig struct {
+	Duration time.Duration
+	Hold     time.Duration
+	Grace    time.Duration
+}
+
+func ReservationLoadConfig() ReservationConfig {
+	return ReservationConfig{
+		Duration: loadDuration("RESERVATION_DURATION", 90*time.Minute),
+		Hold:     loadDuration("RESERVATION_HOLD", 30*time.Minute),
+		Grace:    loadDuration("RESERVATION_GRACE", 15*time.Minute),
+	}
+}
+
+func loadDuration(name string, defaultDuration time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultDuration
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Fatalf("Invalid %s %q: %v", name, value, err)
+	}
+	return duration
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"os"
+)
+
+type StorageConfig struct {
+	ImageDir     string
+	ImageBaseURL string
+}
+
+func StorageLoadConfig() StorageConfig {
+	imageDir := os.Getenv("IMAGE_DIR")
+	if imageDir == "" {
+		imageDir = "assets/images"
+	}
+	imageBaseURL := os.Getenv("IMAGE_BASE_URL")
+	if imageBaseURL == "" {
+		imageBaseURL = "/api/v1/restaurant/images"
+	}
+	return StorageConfig{
+		ImageDir:     imageDir,
+		ImageBaseURL: imageBaseURL,
+	}
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+type TableSessionConfig struct {
+	SessionTTL   time.Duration
+	OrderBaseURL string
+}
+
+func TableSessionLoadConfig() TableSessionConfig {
+	sessionTTL := 3 * time.Hour
+	if value := os.Getenv("TABLE_SESSION_TTL"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl <= 0 {
+			log.Fatalf("Invalid TABLE_SESSION_TTL %q: %v", value, err)
+		}
+		sessionTTL = ttl
+	}
+	orderBaseURL := os.Getenv("ORDER_BASE_URL")
+	if orderBaseURL == "" {
+		orderBaseURL = "http://localhost:5173/order"
+	}
+	return TableSessionConfig{
+		SessionTTL:   sessionTTL,
+		OrderBaseURL: orderBaseURL,
+	}
+}
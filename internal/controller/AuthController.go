@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"Restaurant/internal/middleware"
+	"Restaurant/internal/request"
+	"Restaurant/internal/response"
+	"Restaurant/internal/service"
+	"Restaurant/utils/enums"
+	"github.com/labstack/echo/v4"
+	"log"
+	"net/http"
+)
+
+type AuthController struct {
+	AuthService *service.AuthService
+}
+
+// @Summary Login
+// @Description Exchange a username and password for a bearer token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param loginRequest body request.LoginRequest true "Login Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 401 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/auth/login [post]
+func (ac *AuthController) Login(c echo.Context) error {
+	log.Println("AuthController -> Login")
+	var loginRequest request.LoginRequest
+	if err := c.Bind(&loginRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("Username :", loginRequest.Username)
+	responses, status := ac.AuthService.Login(&loginRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Get all users
+// @Description Retrieve all staff and table accounts
+// @Tags auth
+// @Security BearerAuth
+// @Success 200 {object} response.CustomResponse
+// @Failure 401 {object} response.CustomResponse
+// @Failure 403 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/all/user [get]
+func (ac *AuthController) GetAllUsers(c echo.Context) error {
+	log.Println("AuthController -> GetAllUsers")
+	responses, status := ac.AuthService.GetAllUsers()
+	return c.JSON(status, responses)
+}
+
+// @Summary Create user
+// @Description Create a staff or table account with a role
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param userRequest body request.UserRequest true "User Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 401 {object} response.CustomResponse
+// @Failure 403 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/user/create [post]
+func (ac *AuthController) CreateUser(c echo.Context) error {
+	log.Println("AuthController -> CreateUser")
+	var userRequest request.UserRequest
+	if err := c.Bind(&userRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("Username :", userRequest.Username)
+	log.Println("Role :", userRequest.Role)
+	responses, status := ac.AuthService.CreateUser(&userRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Delete user
+// @Description Deactivate a staff or table account
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param userRequest body request.UserRequest true "User Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 401 {object} response.CustomResponse
+// @Failure 403 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/user/delete [delete]
+func (ac *AuthController) DeleteUser(c echo.Context) error {
+	log.Println("AuthController -> DeleteUser")
+	var userRequest request.UserRequest
+	if err := c.Bind(&userRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("UserID :", userRequest.UserId)
+	responses, status := ac.AuthService.DeleteUser(&userRequest, middleware.Claims(c).UserId)
+	return c.JSON(status, responses)
+}
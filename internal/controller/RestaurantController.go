@@ -1,13 +1,18 @@
 package controller
 
 import (
+	"Restaurant/internal/middleware"
 	"Restaurant/internal/request"
 	"Restaurant/internal/response"
 	"Restaurant/internal/service"
 	"Restaurant/utils/enums"
+	"encoding/json"
+	"fmt"
 	"github.com/labstack/echo/v4"
 	"log"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 type RestaurantController struct {
@@ -44,21 +49,41 @@ func (rc *RestaurantController) FindTable(c echo.Context) error {
 			Message: enums.Invalid.GetMessage(),
 		})
 	}
+	if !middleware.CanAccessTable(c, tableRequest.TableId) {
+		return forbiddenTable(c, tableRequest.TableId)
+	}
 	log.Println("TableID :", tableRequest.TableId)
 	responses, status := rc.RestaurantService.FindTable(&tableRequest)
 	return c.JSON(status, responses)
 }
 
 // @Summary Get all menu
-// @Description Retrieve a list of all menu items
+// @Description Retrieve menu items filtered, sorted and paginated, images are returned as URLs unless includeBase64 is set
 // @Tags restaurant
+// @Param categoryId query int false "Category ID"
+// @Param isAvailable query bool false "Availability"
+// @Param minPrice query number false "Minimum price"
+// @Param maxPrice query number false "Maximum price"
+// @Param name query string false "Name contains"
+// @Param sort query string false "category, name, price, newest or rating"
+// @Param order query string false "asc or desc, newest and rating default to desc"
+// @Param page query int false "Page number, starting at 1"
+// @Param pageSize query int false "Items per page (max 100), all items when omitted"
+// @Param includeBase64 query bool false "Inline images as base64"
 // @Success 200 {object} response.CustomResponse
 // @Failure 400 {object} response.CustomResponse
 // @Failure 500 {object} response.CustomResponse
 // @Router /api/v1/restaurant/all/menu [get]
 func (rc *RestaurantController) GetAllMenu(c echo.Context) error {
 	log.Println("RestController -> GetAllMenu")
-	responses, status := rc.RestaurantService.GetAllMenu()
+	var menuFilterRequest request.MenuFilterRequest
+	if err := c.Bind(&menuFilterRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	responses, status := rc.RestaurantService.GetAllMenu(&menuFilterRequest)
 	return c.JSON(status, responses)
 }
 
@@ -82,6 +107,10 @@ func (rc *RestaurantController) OrderMenu(c echo.Context) error {
 			Message: enums.Invalid.GetMessage(),
 		})
 	}
+	if !middleware.CanAccessTable(c, orderRequest.TableId) {
+		return forbiddenTable(c, orderRequest.TableId)
+	}
+	orderRequest.SessionId = middleware.SessionId(c)
 	log.Println("TableID :", orderRequest.TableId)
 	for _, menuItem := range orderRequest.MenuItems {
 		log.Println("MenuItemID :", menuItem.MenuItemID, "Quantity :", menuItem.Quantity)
@@ -91,13 +120,15 @@ func (rc *RestaurantController) OrderMenu(c echo.Context) error {
 }
 
 // @Summary Update order
-// @Description Update the order status
+// @Description Update the order status. Orders with items move to prepare and completed with their items through /order/item/update
 // @Tags restaurant
 // @Accept json
 // @Produce json
 // @Param orderRequest body request.OrderRequest true "Order Request"
 // @Success 200 {object} response.CustomResponse
 // @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 409 {object} response.CustomResponse
 // @Failure 500 {object} response.CustomResponse
 // @Router /api/v1/restaurant/order/update [patch]
 func (rc *RestaurantController) UpdateOrder(c echo.Context) error {
@@ -142,14 +173,17 @@ func (rc *RestaurantController) DeleteOrder(c echo.Context) error {
 }
 
 // @Summary Pay for order
-// @Description Process payment for the order by its ID and Table ID
+// @Description Pay whatever is left on the bill of the order in one payment, dropping any unpaid split shares. Tenders may mix cash, card and PromptPay, cash tendered above the amount gets change. Automatic promotions and the given coupon codes are applied when the bill is priced. Returns the bill with subtotal, service charge, VAT, rounding and grand total
 // @Tags restaurant
 // @Accept json
 // @Produce json
 // @Param orderRequest body request.OrderRequest true "Order Request"
 // @Success 200 {object} response.CustomResponse
 // @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 409 {object} response.CustomResponse
 // @Failure 500 {object} response.CustomResponse
+// @Failure 402 {object} response.CustomResponse
 // @Router /api/v1/restaurant/order/pay [post]
 func (rc *RestaurantController) PayOrder(c echo.Context) error {
 	log.Println("RestController -> PayOrder")
@@ -167,13 +201,16 @@ func (rc *RestaurantController) PayOrder(c echo.Context) error {
 }
 
 // @Summary Submit a review for an order
-// @Description Add a rating and comment for the specified order
+// @Description Rate a paid order of the table as a whole, each of its dishes through itemReviews, or both. Only dishes on the order can be rated, once each. Customers can only review orders of their own table session
 // @Tags restaurant
 // @Accept json
 // @Produce json
 // @Param orderRequest body request.OrderRequest true "Order Request"
 // @Success 200 {object} response.CustomResponse
 // @Failure 400 {object} response.CustomResponse
+// @Failure 403 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 409 {object} response.CustomResponse
 // @Failure 500 {object} response.CustomResponse
 // @Router /api/v1/restaurant/order/review [post]
 func (rc *RestaurantController) ReviewOrder(c echo.Context) error {
@@ -185,9 +222,15 @@ func (rc *RestaurantController) ReviewOrder(c echo.Context) error {
 			Message: enums.Invalid.GetMessage(),
 		})
 	}
+	if !middleware.CanAccessTable(c, orderRequest.TableId) {
+		return forbiddenTable(c, orderRequest.TableId)
+	}
+	orderRequest.SessionId = middleware.SessionId(c)
+	log.Println("TableID :", orderRequest.TableId)
 	log.Println("OrderID :", orderRequest.OrderId)
 	log.Println("Rating :", orderRequest.Rating)
 	log.Println("Comment :", orderRequest.Comment)
+	log.Println("ItemReviews :", len(orderRequest.ItemReviews))
 	responses, status := rc.RestaurantService.ReviewOrder(&orderRequest)
 	return c.JSON(status, responses)
 }
@@ -200,6 +243,7 @@ func (rc *RestaurantController) ReviewOrder(c echo.Context) error {
 // @Param order body request.OrderRequest true "Order Request"
 // @Success 200 {object} response.CustomResponse
 // @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
 // @Failure 500 {object} response.CustomResponse
 // @Router /api/v1/restaurant/order/details [post]
 func (rc *RestaurantController) OrderDetails(c echo.Context) error {
@@ -211,6 +255,10 @@ func (rc *RestaurantController) OrderDetails(c echo.Context) error {
 			Message: enums.Invalid.GetMessage(),
 		})
 	}
+	if !middleware.CanAccessTable(c, orderRequest.TableId) {
+		return forbiddenTable(c, orderRequest.TableId)
+	}
+	orderRequest.SessionId = middleware.SessionId(c)
 	log.Println("TableID :", orderRequest.TableId)
 	log.Println("OrderID :", orderRequest.OrderId)
 	responses, status := rc.RestaurantService.OrderDetails(&orderRequest)
@@ -226,6 +274,10 @@ func (rc *RestaurantController) OrderHistory(c echo.Context) error {
 			Message: enums.Invalid.GetMessage(),
 		})
 	}
+	if !middleware.CanAccessTable(c, orderRequest.TableId) {
+		return forbiddenTable(c, orderRequest.TableId)
+	}
+	orderRequest.SessionId = middleware.SessionId(c)
 	log.Println("TableID :", orderRequest.TableId)
 	responses, status := rc.RestaurantService.OrderHistory(&orderRequest)
 	return c.JSON(status, responses)
@@ -246,8 +298,355 @@ func (rc *RestaurantController) UpdateTable(c echo.Context) error {
 	return c.JSON(status, responses)
 }
 
-func (rc *RestaurantController) DeleteAllOrderWhenCheckOut(c echo.Context) error {
-	log.Println("RestController -> DeleteAllOrderWhenCheckOut")
+// @Summary Create menu item
+// @Description Add a new dish to the menu
+// @Tags menu
+// @Accept json
+// @Produce json
+// @Param menuRequest body request.MenuRequest true "Menu Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/menu/create [post]
+func (rc *RestaurantController) CreateMenu(c echo.Context) error {
+	log.Println("RestController -> CreateMenu")
+	var menuRequest request.MenuRequest
+	if err := c.Bind(&menuRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("Name :", menuRequest.Name)
+	log.Println("Price :", menuRequest.Price)
+	responses, status := rc.RestaurantService.CreateMenu(&menuRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Update menu item
+// @Description Update the name, description and price of a menu item
+// @Tags menu
+// @Accept json
+// @Produce json
+// @Param menuRequest body request.MenuRequest true "Menu Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/menu/update [patch]
+func (rc *RestaurantController) UpdateMenu(c echo.Context) error {
+	log.Println("RestController -> UpdateMenu")
+	var menuRequest request.MenuRequest
+	if err := c.Bind(&menuRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("MenuItemID :", menuRequest.MenuItemsId)
+	log.Println("Name :", menuRequest.Name)
+	log.Println("Price :", menuRequest.Price)
+	responses, status := rc.RestaurantService.UpdateMenu(&menuRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Toggle menu item availability
+// @Description Mark a menu item as available or unavailable
+// @Tags menu
+// @Accept json
+// @Produce json
+// @Param menuRequest body request.MenuRequest true "Menu Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/menu/availability [patch]
+func (rc *RestaurantController) UpdateMenuAvailability(c echo.Context) error {
+	log.Println("RestController -> UpdateMenuAvailability")
+	var menuRequest request.MenuRequest
+	if err := c.Bind(&menuRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("MenuItemID :", menuRequest.MenuItemsId)
+	responses, status := rc.RestaurantService.UpdateMenuAvailability(&menuRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Delete menu item
+// @Description Soft-delete a menu item by its ID
+// @Tags menu
+// @Accept json
+// @Produce json
+// @Param menuRequest body request.MenuRequest true "Menu Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/menu/delete [delete]
+func (rc *RestaurantController) DeleteMenu(c echo.Context) error {
+	log.Println("RestController -> DeleteMenu")
+	var menuRequest request.MenuRequest
+	if err := c.Bind(&menuRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("MenuItemID :", menuRequest.MenuItemsId)
+	responses, status := rc.RestaurantService.DeleteMenu(&menuRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Upload menu image
+// @Description Upload a JPEG, PNG or WebP image (max 5 MB) for a menu item
+// @Tags menu
+// @Accept multipart/form-data
+// @Produce json
+// @Param menuItemsId formData int true "Menu item ID"
+// @Param file formData file true "Image file"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/menu/image [post]
+func (rc *RestaurantController) UploadMenuImage(c echo.Context) error {
+	log.Println("RestController -> UploadMenuImage")
+	menuItemsId, err := strconv.Atoi(c.FormValue("menuItemsId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("MenuItemID :", menuItemsId)
+	log.Println("FileName :", file.Filename)
+	responses, status := rc.RestaurantService.UploadMenuImage(&request.MenuImageRequest{MenuItemsId: menuItemsId, File: file})
+	return c.JSON(status, responses)
+}
+
+// @Summary Get menu image
+// @Description Stream a menu image with ETag and cache headers
+// @Tags menu
+// @Produce image/jpeg,image/png,image/webp
+// @Param fileName path string true "Image file name"
+// @Success 200 {file} file
+// @Success 304 "Not Modified"
+// @Failure 404 {object} response.CustomResponse
+// @Router /api/v1/restaurant/images/{fileName} [get]
+func (rc *RestaurantController) GetMenuImage(c echo.Context) error {
+	log.Println("RestController -> GetMenuImage")
+	fileName := c.Param("fileName")
+	image, responses, status := rc.RestaurantService.GetMenuImage(fileName)
+	if image == nil {
+		return c.JSON(status, responses)
+	}
+	defer image.Content.Close()
+	c.Response().Header().Set(echo.HeaderCacheControl, "public, max-age=86400")
+	c.Response().Header().Set("ETag", fmt.Sprintf(`"%x-%x"`, image.ModTime.UnixNano(), image.Size))
+	http.ServeContent(c.Response(), c.Request(), fileName, image.ModTime, image.Content)
+	return nil
+}
+
+// @Summary Get all categories
+// @Description Retrieve the menu categories in display order
+// @Tags menu
+// @Success 200 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/all/category [get]
+func (rc *RestaurantController) GetAllCategories(c echo.Context) error {
+	log.Println("RestController -> GetAllCategories")
+	responses, status := rc.RestaurantService.GetAllCategories()
+	return c.JSON(status, responses)
+}
+
+// @Summary Create category
+// @Description Add a new menu category
+// @Tags menu
+// @Accept json
+// @Produce json
+// @Param categoryRequest body request.CategoryRequest true "Category Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/category/create [post]
+func (rc *RestaurantController) CreateCategory(c echo.Context) error {
+	log.Println("RestController -> CreateCategory")
+	var categoryRequest request.CategoryRequest
+	if err := c.Bind(&categoryRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("Name :", categoryRequest.Name)
+	responses, status := rc.RestaurantService.CreateCategory(&categoryRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Update category
+// @Description Rename or reorder a menu category
+// @Tags menu
+// @Accept json
+// @Produce json
+// @Param categoryRequest body request.CategoryRequest true "Category Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/category/update [patch]
+func (rc *RestaurantController) UpdateCategory(c echo.Context) error {
+	log.Println("RestController -> UpdateCategory")
+	var categoryRequest request.CategoryRequest
+	if err := c.Bind(&categoryRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("CategoryID :", categoryRequest.CategoryId)
+	log.Println("Name :", categoryRequest.Name)
+	responses, status := rc.RestaurantService.UpdateCategory(&categoryRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Delete category
+// @Description Soft-delete a category, its menu items become uncategorized
+// @Tags menu
+// @Accept json
+// @Produce json
+// @Param categoryRequest body request.CategoryRequest true "Category Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/category/delete [delete]
+func (rc *RestaurantController) DeleteCategory(c echo.Context) error {
+	log.Println("RestController -> DeleteCategory")
+	var categoryRequest request.CategoryRequest
+	if err := c.Bind(&categoryRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("CategoryID :", categoryRequest.CategoryId)
+	responses, status := rc.RestaurantService.DeleteCategory(&categoryRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Get all tables
+// @Description Retrieve all active tables with their live status and open order counts
+// @Tags table
+// @Success 200 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/all/table [get]
+func (rc *RestaurantController) GetAllTables(c echo.Context) error {
+	log.Println("RestController -> GetAllTables")
+	responses, status := rc.RestaurantService.GetAllTables()
+	return c.JSON(status, responses)
+}
+
+// @Summary Find table by number
+// @Description Find a table by the number shown to staff
+// @Tags table
+// @Accept json
+// @Produce json
+// @Param table body request.TableRequest true "Table Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/table/number [post]
+func (rc *RestaurantController) FindTableByNumber(c echo.Context) error {
+	log.Println("RestController -> FindTableByNumber")
+	var tableRequest request.TableRequest
+	if err := c.Bind(&tableRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("TableNumber :", tableRequest.TableNumber)
+	responses, status := rc.RestaurantService.FindTableByNumber(&tableRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Create table
+// @Description Add a table with its seats, zone and floor plan position (4 seats indoors by default), a retired table with the same number is restored
+// @Tags table
+// @Accept json
+// @Produce json
+// @Param table body request.TableRequest true "Table Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/table/create [post]
+func (rc *RestaurantController) CreateTable(c echo.Context) error {
+	log.Println("RestController -> CreateTable")
+	var tableRequest request.TableRequest
+	if err := c.Bind(&tableRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("TableNumber :", tableRequest.TableNumber)
+	responses, status := rc.RestaurantService.CreateTable(&tableRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Renumber table
+// @Description Change the number of a table
+// @Tags table
+// @Accept json
+// @Produce json
+// @Param table body request.TableRequest true "Table Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/table/renumber [patch]
+func (rc *RestaurantController) RenumberTable(c echo.Context) error {
+	log.Println("RestController -> RenumberTable")
+	var tableRequest request.TableRequest
+	if err := c.Bind(&tableRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("TableID :", tableRequest.TableId)
+	log.Println("TableNumber :", tableRequest.TableNumber)
+	responses, status := rc.RestaurantService.RenumberTable(&tableRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Retire table
+// @Description Retire a table that has no open orders
+// @Tags table
+// @Accept json
+// @Produce json
+// @Param table body request.TableRequest true "Table Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/table/delete [delete]
+func (rc *RestaurantController) DeleteTable(c echo.Context) error {
+	log.Println("RestController -> DeleteTable")
 	var tableRequest request.TableRequest
 	if err := c.Bind(&tableRequest); err != nil {
 		return c.JSON(http.StatusBadRequest, response.CustomResponse{
@@ -256,6 +655,1002 @@ func (rc *RestaurantController) DeleteAllOrderWhenCheckOut(c echo.Context) error
 		})
 	}
 	log.Println("TableID :", tableRequest.TableId)
-	responses, status := rc.RestaurantService.DeleteAllOrderWhenCheckOut(&tableRequest)
+	responses, status := rc.RestaurantService.DeleteTable(&tableRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Kitchen queue
+// @Description List open orders (created or prepare) with their items, oldest first
+// @Tags kitchen
+// @Security BearerAuth
+// @Success 200 {object} response.CustomResponse
+// @Failure 401 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/kitchen/queue [get]
+func (rc *RestaurantController) KitchenQueue(c echo.Context) error {
+	log.Println("RestController -> KitchenQueue")
+	responses, status := rc.RestaurantService.KitchenQueue()
 	return c.JSON(status, responses)
 }
+
+// @Summary Kitchen event stream
+// @Description Server-sent events for order created, status changed and canceled. Browsers may pass the token as ?token=
+// @Tags kitchen
+// @Security BearerAuth
+// @Produce text/event-stream
+// @Success 200 {string} string "event stream"
+// @Failure 401 {object} response.CustomResponse
+// @Router /api/v1/restaurant/kitchen/events [get]
+func (rc *RestaurantController) KitchenEvents(c echo.Context) error {
+	log.Println("RestController -> KitchenEvents")
+	events, unsubscribe := rc.RestaurantService.KitchenFeed.Subscribe()
+	defer unsubscribe()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	heartbeat := time.NewTicker(15 * time.Second)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": ping\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case event := <-events:
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Println("RestController -> Error encoding kitchen event:", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
+
+// @Summary Update order item status
+// @Description Advance a single dish (queued, cooking, ready, served, voided), the order status follows its items
+// @Tags kitchen
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param orderItemRequest body request.OrderItemRequest true "Order Item Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 409 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/order/item/update [patch]
+func (rc *RestaurantController) UpdateOrderItem(c echo.Context) error {
+	log.Println("RestController -> UpdateOrderItem")
+	var orderItemRequest request.OrderItemRequest
+	if err := c.Bind(&orderItemRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("OrderItemID :", orderItemRequest.OrderItemId)
+	log.Println("Status :", orderItemRequest.Status)
+	responses, status := rc.RestaurantService.UpdateOrderItem(&orderItemRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Change an open order
+// @Description Add dishes to, change quantities on, or remove queued dishes from an order that is still created or prepare
+// @Tags restaurant
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param orderAmendRequest body request.OrderAmendRequest true "Order Amend Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 409 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/order/items [patch]
+func (rc *RestaurantController) AmendOrder(c echo.Context) error {
+	log.Println("RestController -> AmendOrder")
+	var orderAmendRequest request.OrderAmendRequest
+	if err := c.Bind(&orderAmendRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	if !middleware.CanAccessTable(c, orderAmendRequest.TableId) {
+		return forbiddenTable(c, orderAmendRequest.TableId)
+	}
+	orderAmendRequest.SessionId = middleware.SessionId(c)
+	log.Println("TableID :", orderAmendRequest.TableId)
+	log.Println("OrderID :", orderAmendRequest.OrderId)
+	responses, status := rc.RestaurantService.AmendOrder(&orderAmendRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Get the bill of an order
+// @Description Show the bill total, amount paid, amount left and every live payment share of the order
+// @Tags restaurant
+// @Security BearerAuth
+// @Produce json
+// @Param orderId query int true "Order ID"
+// @Param tableId query int true "Table ID"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/order/bill [get]
+func (rc *RestaurantController) GetBill(c echo.Context) error {
+	log.Println("RestController -> GetBill")
+	var billRequest request.BillRequest
+	if err := c.Bind(&billRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("TableID :", billRequest.TableId)
+	log.Println("OrderID :", billRequest.OrderId)
+	responses, status := rc.RestaurantService.GetBill(&billRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Split the bill of an order
+// @Description Split what is left on the bill by item, evenly into N parts, or into custom amounts. Splitting again replaces the shares not paid yet, coupon codes given before the first payment price the bill again
+// @Tags restaurant
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param billSplitRequest body request.BillSplitRequest true "Bill Split Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 409 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/order/bill/split [post]
+func (rc *RestaurantController) SplitBill(c echo.Context) error {
+	log.Println("RestController -> SplitBill")
+	var billSplitRequest request.BillSplitRequest
+	if err := c.Bind(&billSplitRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("TableID :", billSplitRequest.TableId)
+	log.Println("OrderID :", billSplitRequest.OrderId)
+	log.Println("Mode :", billSplitRequest.Mode)
+	responses, status := rc.RestaurantService.SplitBill(&billSplitRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Pay one share of a split bill
+// @Description Settle a pending payment share with one or more tenders, the order becomes paid once the whole bill is settled
+// @Tags restaurant
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param billPaymentRequest body request.BillPaymentRequest true "Bill Payment Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 409 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Failure 402 {object} response.CustomResponse
+// @Router /api/v1/restaurant/order/bill/pay [post]
+func (rc *RestaurantController) PayBillShare(c echo.Context) error {
+	log.Println("RestController -> PayBillShare")
+	var billPaymentRequest request.BillPaymentRequest
+	if err := c.Bind(&billPaymentRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("TableID :", billPaymentRequest.TableId)
+	log.Println("OrderID :", billPaymentRequest.OrderId)
+	log.Println("PaymentID :", billPaymentRequest.PaymentId)
+	responses, status := rc.RestaurantService.PayBillShare(&billPaymentRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Refund or void a paid order
+// @Description Give back the whole bill or chosen items with a reason code, authorized by the signed-in admin. A void reverses every charge and is only possible before any refund. The refund is saved as pending before any money moves, a fully declined refund is undone and one declined in part stays pending until it is retried
+// @Tags restaurant
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param refundRequest body request.RefundRequest true "Refund Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 402 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 409 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/order/refund [post]
+func (rc *RestaurantController) RefundOrder(c echo.Context) error {
+	log.Println("RestController -> RefundOrder")
+	var refundRequest request.RefundRequest
+	if err := c.Bind(&refundRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	refundRequest.UserId = middleware.Claims(c).UserId
+	log.Println("TableID :", refundRequest.TableId)
+	log.Println("OrderID :", refundRequest.OrderId)
+	log.Println("Kind :", refundRequest.Kind)
+	log.Println("ReasonCode :", refundRequest.ReasonCode)
+	responses, status := rc.RestaurantService.RefundOrder(&refundRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Retry a pending refund
+// @Description Ask the providers again for the tenders of a refund that stays pending, because part of it was declined or its outcome could not be recorded. Tenders already returned are left alone and each refund is only made once
+// @Tags restaurant
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param refundRetryRequest body request.RefundRetryRequest true "Refund Retry Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 402 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 409 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/order/refund/retry [post]
+func (rc *RestaurantController) RetryRefund(c echo.Context) error {
+	log.Println("RestController -> RetryRefund")
+	var refundRetryRequest request.RefundRetryRequest
+	if err := c.Bind(&refundRetryRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("TableID :", refundRetryRequest.TableId)
+	log.Println("OrderID :", refundRetryRequest.OrderId)
+	log.Println("RefundID :", refundRetryRequest.RefundId)
+	responses, status := rc.RestaurantService.RetryRefund(&refundRetryRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Get all promotions
+// @Description Retrieve every promotion and coupon with its usage count
+// @Tags promotion
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/all/promotion [get]
+func (rc *RestaurantController) GetAllPromotions(c echo.Context) error {
+	log.Println("RestController -> GetAllPromotions")
+	responses, status := rc.RestaurantService.GetAllPromotions()
+	return c.JSON(status, responses)
+}
+
+// @Summary Create promotion
+// @Description Add a percent, fixed, item-level or BOGO promotion, optionally limited to a date range, a happy hour or a coupon code
+// @Tags promotion
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param promotionRequest body request.PromotionRequest true "Promotion Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/promotion/create [post]
+func (rc *RestaurantController) CreatePromotion(c echo.Context) error {
+	log.Println("RestController -> CreatePromotion")
+	var promotionRequest request.PromotionRequest
+	if err := c.Bind(&promotionRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("Name :", promotionRequest.Name)
+	log.Println("RuleType :", promotionRequest.RuleType)
+	responses, status := rc.RestaurantService.CreatePromotion(&promotionRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Update promotion
+// @Description Change the rule, window, coupon code or usage limit of a promotion
+// @Tags promotion
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param promotionRequest body request.PromotionRequest true "Promotion Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/promotion/update [patch]
+func (rc *RestaurantController) UpdatePromotion(c echo.Context) error {
+	log.Println("RestController -> UpdatePromotion")
+	var promotionRequest request.PromotionRequest
+	if err := c.Bind(&promotionRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("PromotionID :", promotionRequest.PromotionId)
+	log.Println("Name :", promotionRequest.Name)
+	responses, status := rc.RestaurantService.UpdatePromotion(&promotionRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Delete promotion
+// @Description Remove a promotion, bills it was already applied to keep their discount
+// @Tags promotion
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param promotionRequest body request.PromotionRequest true "Promotion Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/promotion/delete [delete]
+func (rc *RestaurantController) DeletePromotion(c echo.Context) error {
+	log.Println("RestController -> DeletePromotion")
+	var promotionRequest request.PromotionRequest
+	if err := c.Bind(&promotionRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("PromotionID :", promotionRequest.PromotionId)
+	responses, status := rc.RestaurantService.DeletePromotion(&promotionRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Print the receipt of an order
+// @Description Render the paid bill with items, service charge, VAT, payment methods and table number as a PDF or as ESC/POS text for thermal printers
+// @Tags restaurant
+// @Security BearerAuth
+// @Produce application/pdf
+// @Produce text/plain
+// @Param orderId query int true "Order ID"
+// @Param tableId query int true "Table ID"
+// @Param format query string false "pdf (default) or escpos"
+// @Param width query int false "Characters per line, 32 to 64 (default 42)"
+// @Success 200 {file} file
+// @Failure 400 {object} response.CustomResponse
+// @Failure 403 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/order/receipt [get]
+func (rc *RestaurantController) GetReceipt(c echo.Context) error {
+	log.Println("RestController -> GetReceipt")
+	var receiptRequest request.ReceiptRequest
+	if err := c.Bind(&receiptRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	if !middleware.CanAccessTable(c, receiptRequest.TableId) {
+		return forbiddenTable(c, receiptRequest.TableId)
+	}
+	receiptRequest.SessionId = middleware.SessionId(c)
+	log.Println("TableID :", receiptRequest.TableId)
+	log.Println("OrderID :", receiptRequest.OrderId)
+	log.Println("Format :", receiptRequest.Format)
+	document, responses, status := rc.RestaurantService.GetReceipt(&receiptRequest)
+	if document == nil {
+		return c.JSON(status, responses)
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, `inline; filename="`+document.FileName+`"`)
+	return c.Blob(http.StatusOK, document.ContentType, document.Content)
+}
+
+// @Summary Sales report
+// @Description Revenue, refunds, order counts and average ticket of settled bills by day, week, month, hour of day or table. Dates are in the restaurant's time zone
+// @Tags report
+// @Security BearerAuth
+// @Produce json
+// @Produce text/csv
+// @Param from query string false "First day, YYYY-MM-DD (default 29 days before to)"
+// @Param to query string false "Last day, YYYY-MM-DD (default today)"
+// @Param groupBy query string false "day (default), week, month, hour or table"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/report/sales [get]
+func (rc *RestaurantController) GetSalesReport(c echo.Context) error {
+	log.Println("RestController -> GetSalesReport")
+	var reportRequest request.ReportRequest
+	if err := c.Bind(&reportRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("From :", reportRequest.From)
+	log.Println("To :", reportRequest.To)
+	log.Println("GroupBy :", reportRequest.GroupBy)
+	document, responses, status := rc.RestaurantService.GetSalesReport(&reportRequest)
+	if document == nil {
+		return c.JSON(status, responses)
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+document.FileName+`"`)
+	return c.Blob(http.StatusOK, document.ContentType, document.Content)
+}
+
+// @Summary Menu item report
+// @Description Units ordered, sold, canceled and refunded, revenue, cancellation rate and average rating of each dish on the orders placed in the date range
+// @Tags report
+// @Security BearerAuth
+// @Produce json
+// @Produce text/csv
+// @Param from query string false "First day, YYYY-MM-DD (default 29 days before to)"
+// @Param to query string false "Last day, YYYY-MM-DD (default today)"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/report/menu [get]
+func (rc *RestaurantController) GetMenuItemReport(c echo.Context) error {
+	log.Println("RestController -> GetMenuItemReport")
+	var reportRequest request.ReportRequest
+	if err := c.Bind(&reportRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("From :", reportRequest.From)
+	log.Println("To :", reportRequest.To)
+	document, responses, status := rc.RestaurantService.GetMenuItemReport(&reportRequest)
+	if document == nil {
+		return c.JSON(status, responses)
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+document.FileName+`"`)
+	return c.Blob(http.StatusOK, document.ContentType, document.Content)
+}
+
+// @Summary Get all reviews
+// @Description List reviews newest first, filtered by rating, date, order, table, visibility and reply
+// @Tags review
+// @Security BearerAuth
+// @Produce json
+// @Param rating query int false "Exact star rating"
+// @Param minRating query int false "Lowest star rating"
+// @Param maxRating query int false "Highest star rating"
+// @Param from query string false "First day, YYYY-MM-DD"
+// @Param to query string false "Last day, YYYY-MM-DD"
+// @Param orderId query int false "Order ID"
+// @Param tableId query int false "Table ID"
+// @Param menuItemId query int false "Menu item ID of dish reviews"
+// @Param kind query string false "order or dish, both when empty"
+// @Param visibility query string false "visible (default), hidden or all"
+// @Param replied query bool false "Only reviews with or without a reply"
+// @Param order query string false "desc (default) or asc by review date"
+// @Param page query int false "Page number, starting at 1"
+// @Param pageSize query int false "Reviews per page, at most 100"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/all/review [get]
+func (rc *RestaurantController) GetAllReviews(c echo.Context) error {
+	log.Println("RestController -> GetAllReviews")
+	var reviewFilterRequest request.ReviewFilterRequest
+	if err := c.Bind(&reviewFilterRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	responses, status := rc.RestaurantService.GetAllReviews(&reviewFilterRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Review summary
+// @Description Count, average rating, count per star, replied and hidden reviews matching the same filters as the review list
+// @Tags review
+// @Security BearerAuth
+// @Produce json
+// @Param rating query int false "Exact star rating"
+// @Param minRating query int false "Lowest star rating"
+// @Param maxRating query int false "Highest star rating"
+// @Param from query string false "First day, YYYY-MM-DD"
+// @Param to query string false "Last day, YYYY-MM-DD"
+// @Param orderId query int false "Order ID"
+// @Param tableId query int false "Table ID"
+// @Param menuItemId query int false "Menu item ID of dish reviews"
+// @Param kind query string false "order or dish, both when empty"
+// @Param visibility query string false "visible (default), hidden or all"
+// @Param replied query bool false "Only reviews with or without a reply"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/review/summary [get]
+func (rc *RestaurantController) GetReviewSummary(c echo.Context) error {
+	log.Println("RestController -> GetReviewSummary")
+	var reviewFilterRequest request.ReviewFilterRequest
+	if err := c.Bind(&reviewFilterRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	responses, status := rc.RestaurantService.GetReviewSummary(&reviewFilterRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Reply to a review
+// @Description Set the restaurant's reply to a review, replacing any earlier reply
+// @Tags review
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param reviewReplyRequest body request.ReviewReplyRequest true "Review Reply Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/review/reply [post]
+func (rc *RestaurantController) ReplyReview(c echo.Context) error {
+	log.Println("RestController -> ReplyReview")
+	var reviewReplyRequest request.ReviewReplyRequest
+	if err := c.Bind(&reviewReplyRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	reviewReplyRequest.UserId = middleware.Claims(c).UserId
+	log.Println("ReviewID :", reviewReplyRequest.ReviewId)
+	responses, status := rc.RestaurantService.ReplyReview(&reviewReplyRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Hide or show a review
+// @Description Hide an abusive review from the review list and summary, or show it again
+// @Tags review
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param reviewModerationRequest body request.ReviewModerationRequest true "Review Moderation Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/review/hide [patch]
+func (rc *RestaurantController) ModerateReview(c echo.Context) error {
+	log.Println("RestController -> ModerateReview")
+	var reviewModerationRequest request.ReviewModerationRequest
+	if err := c.Bind(&reviewModerationRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	reviewModerationRequest.UserId = middleware.Claims(c).UserId
+	log.Println("ReviewID :", reviewModerationRequest.ReviewId)
+	log.Println("Hidden :", reviewModerationRequest.Hidden)
+	responses, status := rc.RestaurantService.ModerateReview(&reviewModerationRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Delete a review
+// @Description Soft-delete a review, the order can then be reviewed again
+// @Tags review
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param reviewModerationRequest body request.ReviewModerationRequest true "Review Moderation Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/review/delete [delete]
+func (rc *RestaurantController) DeleteReview(c echo.Context) error {
+	log.Println("RestController -> DeleteReview")
+	var reviewModerationRequest request.ReviewModerationRequest
+	if err := c.Bind(&reviewModerationRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	reviewModerationRequest.UserId = middleware.Claims(c).UserId
+	log.Println("ReviewID :", reviewModerationRequest.ReviewId)
+	responses, status := rc.RestaurantService.DeleteReview(&reviewModerationRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Get all reservations
+// @Description List the bookings of one day in booking order
+// @Tags reservation
+// @Security BearerAuth
+// @Produce json
+// @Param date query string false "Day, YYYY-MM-DD, today when empty"
+// @Param status query string false "booked, seated, completed, canceled or no_show"
+// @Param tableId query int false "Table ID"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/all/reservation [get]
+func (rc *RestaurantController) GetAllReservations(c echo.Context) error {
+	log.Println("RestController -> GetAllReservations")
+	var reservationFilterRequest request.ReservationFilterRequest
+	if err := c.Bind(&reservationFilterRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("Date :", reservationFilterRequest.Date)
+	responses, status := rc.RestaurantService.GetAllReservations(&reservationFilterRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Create a reservation
+// @Description Book a table for a party, the table is held ahead of the booking and released after a no-show
+// @Tags reservation
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param reservationRequest body request.ReservationRequest true "Reservation Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 409 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/reservation/create [post]
+func (rc *RestaurantController) CreateReservation(c echo.Context) error {
+	log.Println("RestController -> CreateReservation")
+	var reservationRequest request.ReservationRequest
+	if err := c.Bind(&reservationRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	reservationRequest.UserId = middleware.Claims(c).UserId
+	log.Println("TableID :", reservationRequest.TableId)
+	log.Println("ReservedAt :", reservationRequest.ReservedAt)
+	responses, status := rc.RestaurantService.CreateReservation(&reservationRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Update a reservation
+// @Description Change the guest, time, party size or table of a booked reservation
+// @Tags reservation
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param reservationRequest body request.ReservationRequest true "Reservation Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 409 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/reservation/update [patch]
+func (rc *RestaurantController) UpdateReservation(c echo.Context) error {
+	log.Println("RestController -> UpdateReservation")
+	var reservationRequest request.ReservationRequest
+	if err := c.Bind(&reservationRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	reservationRequest.UserId = middleware.Claims(c).UserId
+	log.Println("ReservationID :", reservationRequest.ReservationId)
+	log.Println("ReservedAt :", reservationRequest.ReservedAt)
+	responses, status := rc.RestaurantService.UpdateReservation(&reservationRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Update reservation status
+// @Description Seat, complete, cancel or mark a reservation as a no-show, seating occupies the table and returns its QR session
+// @Tags reservation
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param reservationStatusRequest body request.ReservationStatusRequest true "Reservation Status Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 409 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/reservation/status [patch]
+func (rc *RestaurantController) UpdateReservationStatus(c echo.Context) error {
+	log.Println("RestController -> UpdateReservationStatus")
+	var reservationStatusRequest request.ReservationStatusRequest
+	if err := c.Bind(&reservationStatusRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("ReservationID :", reservationStatusRequest.ReservationId)
+	log.Println("Status :", reservationStatusRequest.Status)
+	responses, status := rc.RestaurantService.UpdateReservationStatus(&reservationStatusRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Get the waitlist
+// @Description List walk-in parties still waiting or notified of a free table, with queue position and estimated wait
+// @Tags waitlist
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/all/waitlist [get]
+func (rc *RestaurantController) GetWaitlist(c echo.Context) error {
+	log.Println("RestController -> GetWaitlist")
+	responses, status := rc.RestaurantService.GetWaitlist()
+	return c.JSON(status, responses)
+}
+
+// @Summary Add a party to the waitlist
+// @Description Queue a walk-in party, the wait is estimated from table occupancy and average dining time
+// @Tags waitlist
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param waitlistRequest body request.WaitlistRequest true "Waitlist Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/waitlist/create [post]
+func (rc *RestaurantController) AddToWaitlist(c echo.Context) error {
+	log.Println("RestController -> AddToWaitlist")
+	var waitlistRequest request.WaitlistRequest
+	if err := c.Bind(&waitlistRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	waitlistRequest.UserId = middleware.Claims(c).UserId
+	log.Println("PartySize :", waitlistRequest.PartySize)
+	responses, status := rc.RestaurantService.AddToWaitlist(&waitlistRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Update waitlist status
+// @Description Seat a waiting party at a free table, or take it off the waitlist, seating returns the table QR session
+// @Tags waitlist
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param waitlistStatusRequest body request.WaitlistStatusRequest true "Waitlist Status Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 409 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/waitlist/status [patch]
+func (rc *RestaurantController) UpdateWaitlistStatus(c echo.Context) error {
+	log.Println("RestController -> UpdateWaitlistStatus")
+	var waitlistStatusRequest request.WaitlistStatusRequest
+	if err := c.Bind(&waitlistStatusRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("WaitlistID :", waitlistStatusRequest.WaitlistId)
+	log.Println("Status :", waitlistStatusRequest.Status)
+	log.Println("TableID :", waitlistStatusRequest.TableId)
+	responses, status := rc.RestaurantService.UpdateWaitlistStatus(&waitlistStatusRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Get the floor plan
+// @Description Tables by zone with position, seats, live status, open order value, seated time and next booking today
+// @Tags table
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/table/floor-plan [get]
+func (rc *RestaurantController) GetFloorPlan(c echo.Context) error {
+	log.Println("RestController -> GetFloorPlan")
+	responses, status := rc.RestaurantService.GetFloorPlan()
+	return c.JSON(status, responses)
+}
+
+// @Summary Suggest tables
+// @Description Free tables that seat the party and are not booked soon, smallest first, or the estimated wait when none is free
+// @Tags table
+// @Security BearerAuth
+// @Produce json
+// @Param partySize query int true "Party size"
+// @Param zone query string false "indoor, terrace or bar"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/table/suggest [get]
+func (rc *RestaurantController) SuggestTables(c echo.Context) error {
+	log.Println("RestController -> SuggestTables")
+	var tableSuggestionRequest request.TableSuggestionRequest
+	if err := c.Bind(&tableSuggestionRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("PartySize :", tableSuggestionRequest.PartySize)
+	log.Println("Zone :", tableSuggestionRequest.Zone)
+	responses, status := rc.RestaurantService.SuggestTables(&tableSuggestionRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Update table layout
+// @Description Change the seat count, zone and floor plan position of a table
+// @Tags table
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param table body request.TableRequest true "Table Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/table/layout [patch]
+func (rc *RestaurantController) UpdateTableLayout(c echo.Context) error {
+	log.Println("RestController -> UpdateTableLayout")
+	var tableRequest request.TableRequest
+	if err := c.Bind(&tableRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("TableID :", tableRequest.TableId)
+	log.Println("Capacity :", tableRequest.Capacity)
+	log.Println("Zone :", tableRequest.Zone)
+	responses, status := rc.RestaurantService.UpdateTableLayout(&tableRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Transfer a table
+// @Description Move a party with all its orders and bills to a free table that is not booked within an average sitting, the party gets a new QR session and the old table is freed
+// @Tags table
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param tableTransferRequest body request.TableTransferRequest true "Table Transfer Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 409 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/table/transfer [post]
+func (rc *RestaurantController) TransferTable(c echo.Context) error {
+	log.Println("RestController -> TransferTable")
+	var tableTransferRequest request.TableTransferRequest
+	if err := c.Bind(&tableTransferRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("FromTableID :", tableTransferRequest.FromTableId)
+	log.Println("ToTableID :", tableTransferRequest.ToTableId)
+	responses, status := rc.RestaurantService.TransferTable(&tableTransferRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Merge tables
+// @Description Join tables to an occupied table so they are billed together, their orders move to the main table
+// @Tags table
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param tableGroupRequest body request.TableGroupRequest true "Table Group Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 409 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/table/merge [post]
+func (rc *RestaurantController) MergeTables(c echo.Context) error {
+	log.Println("RestController -> MergeTables")
+	var tableGroupRequest request.TableGroupRequest
+	if err := c.Bind(&tableGroupRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("TableID :", tableGroupRequest.TableId)
+	log.Println("TableIDs :", tableGroupRequest.TableIds)
+	responses, status := rc.RestaurantService.MergeTables(&tableGroupRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Split tables
+// @Description Take tables out of a merged group, each gets back the orders that came from it with a new QR session, tables without orders are freed
+// @Tags table
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param tableGroupRequest body request.TableGroupRequest true "Table Group Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 409 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/table/split [post]
+func (rc *RestaurantController) SplitTables(c echo.Context) error {
+	log.Println("RestController -> SplitTables")
+	var tableGroupRequest request.TableGroupRequest
+	if err := c.Bind(&tableGroupRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	log.Println("TableID :", tableGroupRequest.TableId)
+	log.Println("TableIDs :", tableGroupRequest.TableIds)
+	responses, status := rc.RestaurantService.SplitTables(&tableGroupRequest)
+	return c.JSON(status, responses)
+}
+
+// @Summary Check out a table
+// @Description Close a party's visit once every order is paid, archive its orders and session and free the table with any tables merged into it. Only admins may force a checkout with unpaid orders, which needs a reason
+// @Tags table
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param checkoutRequest body request.CheckoutRequest true "Checkout Request"
+// @Success 200 {object} response.CustomResponse
+// @Failure 400 {object} response.CustomResponse
+// @Failure 403 {object} response.CustomResponse
+// @Failure 404 {object} response.CustomResponse
+// @Failure 409 {object} response.CustomResponse
+// @Failure 500 {object} response.CustomResponse
+// @Router /api/v1/restaurant/table/checkout [post]
+func (rc *RestaurantController) CheckoutTable(c echo.Context) error {
+	log.Println("RestController -> CheckoutTable")
+	var checkoutRequest request.CheckoutRequest
+	if err := c.Bind(&checkoutRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		})
+	}
+	claims := middleware.Claims(c)
+	if checkoutRequest.Force && claims.Role != enums.RoleAdmin {
+		return c.JSON(http.StatusForbidden, response.CustomResponse{
+			Code:    enums.Forbidden.GetCode(),
+			Message: enums.Forbidden.GetMessage() + ", Only admins can force a checkout.",
+		})
+	}
+	checkoutRequest.UserId = claims.UserId
+	log.Println("TableID :", checkoutRequest.TableId)
+	log.Println("Force :", checkoutRequest.Force)
+	responses, status := rc.RestaurantService.CheckoutTable(&checkoutRequest)
+	return c.JSON(status, responses)
+}
+
+func forbiddenTable(c echo.Context, tableId int) error {
+	log.Println("RestController -> Table", tableId, "is not accessible with this token")
+	return c.JSON(http.StatusForbidden, response.CustomResponse{
+		Code:    enums.Forbidden.GetCode(),
+		Message: enums.Forbidden.GetMessage(),
+	})
+}
@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"Restaurant/internal/model"
+	"Restaurant/internal/response"
+	"Restaurant/utils/enums"
+	"Restaurant/utils/security"
+	"github.com/labstack/echo/v4"
+	"log"
+	"net/http"
+	"strings"
+)
+
+const claimsKey = "claims"
+
+type AuthMiddleware struct {
+	Secret []byte
+	// IsSessionActive reports whether a table session is still open, session tokens
+	// stop working as soon as the table is checked out even if they have not expired.
+	IsSessionActive func(sessionId int, tableId int) (bool, error)
+	// FindActiveSession returns the open session of a table, or 0 when nobody is seated there.
+	FindActiveSession func(tableId int) (int, error)
+	// FindUser loads the account behind a login token, so deleted, deactivated or changed
+	// accounts lose access on their next request instead of when the token expires.
+	FindUser func(userId int) (*model.User, error)
+}
+
+// Require accepts requests carrying a valid bearer token whose role is one of roles.
+func (m *AuthMiddleware) Require(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Request().Header.Get(echo.HeaderAuthorization)
+			token, found := strings.CutPrefix(header, "Bearer ")
+			// EventSource cannot set headers, so event streams may pass the token in the query
+			if !found && c.Request().Header.Get(echo.HeaderAccept) == "text/event-stream" {
+				token = c.QueryParam("token")
+				found = true
+			}
+			if !found || token == "" {
+				return c.JSON(http.StatusUnauthorized, response.CustomResponse{
+					Code:    enums.Unauthorized.GetCode(),
+					Message: enums.Unauthorized.GetMessage() + ", Missing bearer token.",
+				})
+			}
+			claims, err := security.ParseToken(token, m.Secret)
+			if err != nil {
+				log.Printf("AuthMiddleware -> %s %s: %v", c.Request().Method, c.Path(), err)
+				return c.JSON(http.StatusUnauthorized, response.CustomResponse{
+					Code:    enums.Unauthorized.GetCode(),
+					Message: enums.Unauthorized.GetMessage() + ", " + err.Error() + ".",
+				})
+			}
+			if claims.UserId > 0 {
+				user, err := m.FindUser(claims.UserId)
+				if err != nil {
+					log.Printf("AuthMiddleware -> Error loading user: %v", err)
+					return c.JSON(http.StatusInternalServerError, response.CustomResponse{
+						Code:    enums.Error.GetCode(),
+						Message: enums.Error.GetMessage(),
+					})
+				}
+				if user == nil || !user.IsActive || user.Role != claims.Role || user.TableId != claims.TableId {
+					log.Printf("AuthMiddleware -> %s %s: user %d is no longer active", c.Request().Method, c.Path(), claims.UserId)
+					return c.JSON(http.StatusUnauthorized, response.CustomResponse{
+						Code:    enums.Unauthorized.GetCode(),
+						Message: enums.Unauthorized.GetMessage() + ", Account is no longer active.",
+					})
+				}
+			}
+			if claims.Role == enums.RoleTable {
+				active, err := m.tableSessionActive(claims)
+				if err != nil {
+					log.Printf("AuthMiddleware -> Error checking table session: %v", err)
+					return c.JSON(http.StatusInternalServerError, response.CustomResponse{
+						Code:    enums.Error.GetCode(),
+						Message: enums.Error.GetMessage(),
+					})
+				}
+				if !active {
+					return c.JSON(http.StatusUnauthorized, response.CustomResponse{
+						Code:    enums.Unauthorized.GetCode(),
+						Message: enums.Unauthorized.GetMessage() + ", Table session has ended.",
+					})
+				}
+			}
+			for _, role := range roles {
+				if claims.Role == role {
+					c.Set(claimsKey, claims)
+					return next(c)
+				}
+			}
+			log.Printf("AuthMiddleware -> %s %s: role %s is not allowed", c.Request().Method, c.Path(), claims.Role)
+			return c.JSON(http.StatusForbidden, response.CustomResponse{
+				Code:    enums.Forbidden.GetCode(),
+				Message: enums.Forbidden.GetMessage(),
+			})
+		}
+	}
+}
+
+// tableSessionActive reports whether a table token belongs to an open table session. Device accounts
+// bound to a table carry no session, they act for the party currently seated at their table.
+func (m *AuthMiddleware) tableSessionActive(claims *security.Claims) (bool, error) {
+	if claims.SessionId == 0 {
+		sessionId, err := m.FindActiveSession(claims.TableId)
+		claims.SessionId = sessionId
+		return sessionId > 0, err
+	}
+	return m.IsSessionActive(claims.SessionId, claims.TableId)
+}
+
+// Claims returns the token claims stored by Require, or nil on public routes.
+func Claims(c echo.Context) *security.Claims {
+	claims, _ := c.Get(claimsKey).(*security.Claims)
+	return claims
+}
+
+// CanAccessTable reports whether the caller may act on tableId. Table accounts are
+// bound to a single table, staff roles may act on any table.
+func CanAccessTable(c echo.Context, tableId int) bool {
+	claims := Claims(c)
+	if claims == nil || claims.Role != enums.RoleTable {
+		return true
+	}
+	return claims.TableId == tableId
+}
+
+// SessionId returns the table session of the caller, or 0 for staff.
+func SessionId(c echo.Context) int {
+	claims := Claims(c)
+	if claims == nil {
+		return 0
+	}
+	return claims.SessionId
+}
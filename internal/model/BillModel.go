@@ -0,0 +1,79 @@
+package model
+
+type Bill struct {
+	BillId            int                `json:"billId"`
+	OrderId           int                `json:"orderId"`
+	TableId           int                `json:"tableId"`
+	Subtotal          float64            `json:"subtotal"`
+	Discount          float64            `json:"discount"`
+	ServiceChargeRate float64            `json:"serviceChargeRate"`
+	ServiceCharge     float64            `json:"serviceCharge"`
+	VatRate           float64            `json:"vatRate"`
+	VatInclusive      bool               `json:"vatInclusive"`
+	VatAmount         float64            `json:"vatAmount"`
+	Rounding          float64            `json:"rounding"`
+	TotalAmount       float64            `json:"totalAmount"`
+	PaidAmount        float64            `json:"paidAmount"`
+	RefundedAmount    float64            `json:"refundedAmount"`
+	Remaining         float64            `json:"remaining"`
+	Status            string             `json:"status"`
+	BillDate          string             `json:"billDate"`
+	Promotions        []AppliedPromotion `json:"promotions"`
+	Payments          []Payment          `json:"payments"`
+	Refunds           []Refund           `json:"refunds,omitempty"`
+}
+
+type Payment struct {
+	PaymentId    int      `json:"paymentId"`
+	BillId       int      `json:"billId"`
+	Label        string   `json:"label"`
+	SplitMode    string   `json:"splitMode"`
+	Amount       float64  `json:"amount"`
+	Status       string   `json:"status"`
+	OrderItemIds []int    `json:"orderItemIds,omitempty"`
+	Tenders      []Tender `json:"tenders,omitempty"`
+	PaidAt       *string  `json:"paidAt,omitempty"`
+}
+
+type Tender struct {
+	TenderId  int     `json:"tenderId"`
+	PaymentId int     `json:"paymentId"`
+	Method    string  `json:"method"`
+	Amount    float64 `json:"amount"`
+	Tendered  float64 `json:"tendered"`
+	Change    float64 `json:"change"`
+	Reference string  `json:"reference,omitempty"`
+	Provider  string  `json:"provider"`
+	RefundOf  int     `json:"refundOf,omitempty"`
+	Status    string  `json:"status"`
+}
+
+// Refund gives back part or all of a paid bill, its negative payment holds the money returned per tender.
+type Refund struct {
+	RefundId     int          `json:"refundId"`
+	BillId       int          `json:"billId"`
+	PaymentId    int          `json:"paymentId"`
+	Kind         string       `json:"kind"`
+	ReasonCode   string       `json:"reasonCode"`
+	Note         string       `json:"note,omitempty"`
+	Amount       float64      `json:"amount"`
+	AuthorizedBy int          `json:"authorizedBy"`
+	Items        []RefundItem `json:"items,omitempty"`
+	CreatedAt    string       `json:"createdAt"`
+}
+
+type RefundItem struct {
+	OrderItemId int     `json:"orderItemId"`
+	Amount      float64 `json:"amount"`
+}
+
+// BillItem is an order item that has not been settled by a paid payment yet.
+type BillItem struct {
+	OrderItemId int
+	MenuItemId  int
+	Quantity    int
+	Price       float64
+	Amount      float64
+	// Charged is the line's share of the bill total, after discounts and with service charge and VAT
+	Charged float64
+}
@@ -0,0 +1,7 @@
+package model
+
+type Category struct {
+	CategoryId int    `json:"categoryId"`
+	Name       string `json:"name"`
+	SortOrder  int    `json:"sortOrder"`
+}
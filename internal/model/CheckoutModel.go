@@ -0,0 +1,37 @@
+package model
+
+// Checkout is the archived summary of a table's visit, from seating to checkout. A forced checkout
+// closed the table with unpaid orders and records why.
+type Checkout struct {
+	CheckoutId      int             `json:"checkoutId"`
+	TableId         int             `json:"tableId"`
+	SessionId       int             `json:"sessionId,omitempty"`
+	StartedAt       string          `json:"startedAt,omitempty"`
+	ClosedAt        string          `json:"closedAt"`
+	DurationMinutes int             `json:"durationMinutes"`
+	OrderCount      int             `json:"orderCount"`
+	ItemCount       int             `json:"itemCount"`
+	TotalAmount     float64         `json:"totalAmount"`
+	PaidAmount      float64         `json:"paidAmount"`
+	RefundedAmount  float64         `json:"refundedAmount"`
+	UnpaidAmount    float64         `json:"unpaidAmount"`
+	Forced          bool            `json:"forced"`
+	Reason          string          `json:"reason,omitempty"`
+	Orders          []CheckoutOrder `json:"orders"`
+	MergedTables    []int           `json:"mergedTables,omitempty"`
+	// NextParty is the waitlist party offered the freed table
+	NextParty *WaitlistEntry `json:"nextParty,omitempty"`
+}
+
+// CheckoutOrder is one order of a checked out visit. Total is the bill total, or the value of the
+// order's items before it was billed, and Due is what an unpaid order still owes.
+type CheckoutOrder struct {
+	OrderId   int     `json:"orderId"`
+	Status    string  `json:"status"`
+	Items     int     `json:"items"`
+	Total     float64 `json:"total"`
+	Paid      float64 `json:"paid"`
+	Refunded  float64 `json:"refunded"`
+	Due       float64 `json:"due"`
+	CreatedAt string  `json:"createdAt"`
+}
@@ -0,0 +1,20 @@
+package model
+
+type KitchenEvent struct {
+	Type        string `json:"type"`
+	OrderId     int    `json:"orderId"`
+	OrderItemId int    `json:"orderItemId,omitempty"`
+	TableId     int    `json:"tableId"`
+	Status      string `json:"status"`
+	OccurredAt  string `json:"occurredAt"`
+}
+
+type KitchenOrder struct {
+	OrderId     int          `json:"orderId"`
+	TableId     int          `json:"tableId"`
+	TableNumber int          `json:"tableNumber"`
+	Status      string       `json:"status"`
+	CreatedAt   string       `json:"createdAt"`
+	AgeMinutes  int          `json:"ageMinutes"`
+	OrderItems  []OrderItems `json:"orderItems"`
+}
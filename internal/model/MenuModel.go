@@ -1,15 +1,20 @@
 package model
 
 type Menus struct {
-	MenuItemsId int          `json:"menuItemsId"`
-	Name        string       `json:"name"`
-	Description string       `json:"description"`
-	Price       float64      `json:"price"`
-	IsAvailable bool         `json:"isAvailable"`
-	FileObjects []FileObject `json:"fileObjects"`
+	MenuItemsId   int          `json:"menuItemsId"`
+	Name          string       `json:"name"`
+	Description   string       `json:"description"`
+	Price         float64      `json:"price"`
+	IsAvailable   bool         `json:"isAvailable"`
+	CategoryId    int          `json:"categoryId"`
+	CategoryName  string       `json:"categoryName"`
+	AverageRating float64      `json:"averageRating"`
+	RatingCount   int          `json:"ratingCount"`
+	FileObjects   []FileObject `json:"fileObjects"`
 }
 
 type FileObject struct {
 	FileName string `json:"fileName"`
-	Base64   string `json:"base64"`
+	Url      string `json:"url"`
+	Base64   string `json:"base64,omitempty"`
 }
@@ -8,9 +8,19 @@ type Order struct {
 }
 
 type OrderItems struct {
+	OrderItemId int     `json:"orderItemId"`
 	MenuItemId  int     `json:"menuItemId"`
 	Name        string  `json:"name"`
 	Description string  `json:"description"`
 	Quantity    int     `json:"quantity"`
 	Price       float64 `json:"price"`
+	ItemStatus  string  `json:"itemStatus"`
+}
+
+type OrderItemState struct {
+	OrderItemId int
+	OrderId     int
+	TableId     int
+	ItemStatus  string
+	OrderStatus string
 }
@@ -0,0 +1,27 @@
+package model
+
+type Promotion struct {
+	PromotionId    int     `json:"promotionId"`
+	Name           string  `json:"name"`
+	RuleType       string  `json:"ruleType"`
+	Value          float64 `json:"value"`
+	MenuItemId     int     `json:"menuItemId,omitempty"`
+	MinSubtotal    float64 `json:"minSubtotal"`
+	StartsAt       string  `json:"startsAt,omitempty"`
+	EndsAt         string  `json:"endsAt,omitempty"`
+	HappyHourStart string  `json:"happyHourStart,omitempty"`
+	HappyHourEnd   string  `json:"happyHourEnd,omitempty"`
+	CouponCode     string  `json:"couponCode,omitempty"`
+	UsageLimit     int     `json:"usageLimit"`
+	UsedCount      int     `json:"usedCount"`
+	IsActive       bool    `json:"isActive"`
+}
+
+// AppliedPromotion records how much one promotion took off a bill.
+type AppliedPromotion struct {
+	PromotionId int     `json:"promotionId"`
+	Name        string  `json:"name"`
+	RuleType    string  `json:"ruleType"`
+	CouponCode  string  `json:"couponCode,omitempty"`
+	Discount    float64 `json:"discount"`
+}
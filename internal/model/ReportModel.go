@@ -0,0 +1,51 @@
+package model
+
+// SalesReport sums settled bills between From and To, both dates inclusive in TimeZone.
+type SalesReport struct {
+	From     string     `json:"from"`
+	To       string     `json:"to"`
+	TimeZone string     `json:"timeZone"`
+	GroupBy  string     `json:"groupBy"`
+	Rows     []SalesRow `json:"rows"`
+	Totals   SalesRow   `json:"totals"`
+}
+
+// SalesRow is one period, hour of day or table of a sales report. Gross is what was paid,
+// Net is Gross less refunds and AverageTicket is Net per order.
+type SalesRow struct {
+	Period        string  `json:"period"`
+	Orders        int     `json:"orders"`
+	Gross         float64 `json:"gross"`
+	Discount      float64 `json:"discount"`
+	ServiceCharge float64 `json:"serviceCharge"`
+	Vat           float64 `json:"vat"`
+	Refunded      float64 `json:"refunded"`
+	Net           float64 `json:"net"`
+	AverageTicket float64 `json:"averageTicket"`
+}
+
+// MenuItemReport shows how each dish did with the orders placed between From and To.
+type MenuItemReport struct {
+	From     string          `json:"from"`
+	To       string          `json:"to"`
+	TimeZone string          `json:"timeZone"`
+	Items    []MenuItemStats `json:"items"`
+}
+
+// MenuItemStats counts units of one dish. Sold units were paid and not refunded, canceled units were
+// voided or belonged to a canceled order. Revenue is what the paid bills charged for the dish after
+// discounts, less its refunds. CancellationRate is the percentage of ordered units canceled and
+// AverageRating is left at 0 when no review covers the dish.
+type MenuItemStats struct {
+	MenuItemId       int     `json:"menuItemId"`
+	Name             string  `json:"name"`
+	Category         string  `json:"category"`
+	UnitsOrdered     int     `json:"unitsOrdered"`
+	UnitsSold        int     `json:"unitsSold"`
+	UnitsCanceled    int     `json:"unitsCanceled"`
+	UnitsRefunded    int     `json:"unitsRefunded"`
+	Revenue          float64 `json:"revenue"`
+	CancellationRate float64 `json:"cancellationRate"`
+	AverageRating    float64 `json:"averageRating"`
+	Ratings          int     `json:"ratings"`
+}
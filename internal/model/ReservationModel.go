@@ -0,0 +1,17 @@
+package model
+
+type Reservation struct {
+	ReservationId   int    `json:"reservationId"`
+	GuestName       string `json:"guestName"`
+	Phone           string `json:"phone"`
+	PartySize       int    `json:"partySize"`
+	TableId         int    `json:"tableId,omitempty"`
+	TableNumber     int    `json:"tableNumber,omitempty"`
+	ReservedAt      string `json:"reservedAt"`
+	DurationMinutes int    `json:"durationMinutes"`
+	Status          string `json:"status"`
+	Note            string `json:"note,omitempty"`
+	CreatedAt       string `json:"createdAt"`
+	// TableSession is the customer session started when the party is seated
+	TableSession *TableSession `json:"tableSession,omitempty"`
+}
@@ -0,0 +1,35 @@
+package model
+
+// Review rates a whole order, or one dish of it when MenuItemId is set.
+type Review struct {
+	ReviewId       int     `json:"reviewId"`
+	OrderId        int     `json:"orderId"`
+	MenuItemId     int     `json:"menuItemId,omitempty"`
+	MenuItemName   string  `json:"menuItemName,omitempty"`
+	TableId        int     `json:"tableId"`
+	TableNumber    int     `json:"tableNumber"`
+	Rating         int     `json:"rating"`
+	Comment        string  `json:"comment"`
+	Reply          string  `json:"reply,omitempty"`
+	RepliedBy      int     `json:"repliedBy,omitempty"`
+	RepliedAt      *string `json:"repliedAt,omitempty"`
+	IsHidden       bool    `json:"isHidden"`
+	ModerationNote string  `json:"moderationNote,omitempty"`
+	ReviewDate     string  `json:"reviewDate"`
+}
+
+type ReviewPage struct {
+	Reviews  []Review `json:"reviews"`
+	Page     int      `json:"page"`
+	PageSize int      `json:"pageSize"`
+	Total    int      `json:"total"`
+}
+
+// ReviewSummary aggregates the reviews matching a filter. RatingCounts is keyed by star rating.
+type ReviewSummary struct {
+	Count         int         `json:"count"`
+	AverageRating float64     `json:"averageRating"`
+	RatingCounts  map[int]int `json:"ratingCounts"`
+	Replied       int         `json:"replied"`
+	Hidden        int         `json:"hidden"`
+}
@@ -0,0 +1,68 @@
+package model
+
+type Table struct {
+	TableId     int    `json:"tableId"`
+	TableNumber int    `json:"tableNumber"`
+	TableStatus string `json:"tableStatus"`
+	Capacity    int    `json:"capacity"`
+	Zone        string `json:"zone"`
+	X           int    `json:"x"`
+	Y           int    `json:"y"`
+	MergedInto  int    `json:"mergedInto,omitempty"`
+	OpenOrders  int    `json:"openOrders"`
+}
+
+// FloorPlan is the live dining room, table by table within each zone.
+type FloorPlan struct {
+	Zones          []FloorZone `json:"zones"`
+	Seats          int         `json:"seats"`
+	FreeSeats      int         `json:"freeSeats"`
+	OpenOrderTotal float64     `json:"openOrderTotal"`
+}
+
+type FloorZone struct {
+	Zone           string       `json:"zone"`
+	Tables         []FloorTable `json:"tables"`
+	Seats          int          `json:"seats"`
+	FreeSeats      int          `json:"freeSeats"`
+	OpenOrderTotal float64      `json:"openOrderTotal"`
+}
+
+// FloorTable is a table with what is happening at it: the value of its unpaid orders, how long
+// the party has been seated and the time of its next booking today.
+type FloorTable struct {
+	Table
+	OpenOrderTotal  float64 `json:"openOrderTotal"`
+	SeatedMinutes   int     `json:"seatedMinutes,omitempty"`
+	NextReservation string  `json:"nextReservation,omitempty"`
+}
+
+// TableSuggestion lists the free tables that seat a party, best fit first. When none is free,
+// EstimatedWaitMinutes is the wait the party would be quoted on the waitlist.
+type TableSuggestion struct {
+	PartySize            int     `json:"partySize"`
+	Tables               []Table `json:"tables"`
+	EstimatedWaitMinutes int     `json:"estimatedWaitMinutes"`
+}
+
+// TableState is a locked table as seen by transfers, merges and splits. Members is how many
+// tables are merged into it.
+type TableState struct {
+	TableId     int
+	TableNumber int
+	TableStatus string
+	MergedInto  int
+	Members     int
+}
+
+// TableMove reports what a transfer, merge or split did to one table: the orders moved onto it, the
+// customer session started when the party now orders there, and the waitlist party offered the
+// table once it was freed.
+type TableMove struct {
+	TableId      int            `json:"tableId"`
+	TableStatus  string         `json:"tableStatus"`
+	MergedInto   int            `json:"mergedInto,omitempty"`
+	OrdersMoved  int            `json:"ordersMoved"`
+	TableSession *TableSession  `json:"tableSession,omitempty"`
+	NextParty    *WaitlistEntry `json:"nextParty,omitempty"`
+}
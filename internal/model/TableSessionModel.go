@@ -0,0 +1,9 @@
+package model
+
+type TableSession struct {
+	SessionId int    `json:"sessionId"`
+	TableId   int    `json:"tableId"`
+	Token     string `json:"token"`
+	ExpiresAt string `json:"expiresAt"`
+	QrContent string `json:"qrContent"`
+}
@@ -0,0 +1,16 @@
+package model
+
+type User struct {
+	UserId       int    `json:"userId"`
+	Username     string `json:"username"`
+	Role         string `json:"role"`
+	TableId      int    `json:"tableId,omitempty"`
+	IsActive     bool   `json:"isActive"`
+	PasswordHash string `json:"-"`
+}
+
+type LoginToken struct {
+	Token     string `json:"token"`
+	ExpiresAt string `json:"expiresAt"`
+	User      User   `json:"user"`
+}
@@ -0,0 +1,32 @@
+package model
+
+// WaitlistEntry is a walk-in party waiting for a table. TableId is the freed table suggested to a
+// notified party, or the table a seated party got.
+type WaitlistEntry struct {
+	WaitlistId        int    `json:"waitlistId"`
+	PartyName         string `json:"partyName"`
+	Phone             string `json:"phone"`
+	PartySize         int    `json:"partySize"`
+	Status            string `json:"status"`
+	TableId           int    `json:"tableId,omitempty"`
+	TableNumber       int    `json:"tableNumber,omitempty"`
+	QuotedWaitMinutes int    `json:"quotedWaitMinutes"`
+	Note              string `json:"note,omitempty"`
+	CreatedAt         string `json:"createdAt"`
+	NotifiedAt        string `json:"notifiedAt,omitempty"`
+	// Position and EstimatedWaitMinutes are worked out when the waitlist is read
+	Position             int `json:"position,omitempty"`
+	EstimatedWaitMinutes int `json:"estimatedWaitMinutes"`
+	// TableSession is the customer session started when the party is seated
+	TableSession *TableSession `json:"tableSession,omitempty"`
+}
+
+// TableOccupancy is a table as seen by the wait estimate, SeatedMinutes is how long its open
+// session has been running.
+type TableOccupancy struct {
+	TableId       int
+	Capacity      int
+	TableStatus   string
+	InSession     bool
+	SeatedMinutes int
+}
@@ -0,0 +1,35 @@
+package payment
+
+import "context"
+
+// CashProvider takes cash at the counter, nothing leaves the process.
+type CashProvider struct{}
+
+func (p *CashProvider) Name() string {
+	return "cash"
+}
+
+func (p *CashProvider) Charge(ctx context.Context, r ChargeRequest) (ChargeResult, error) {
+	tendered := r.Tendered
+	if tendered == 0 {
+		tendered = r.Amount
+	}
+	if tendered < r.Amount {
+		return ChargeResult{}, ErrInsufficientCash
+	}
+	return ChargeResult{
+		Provider:  p.Name(),
+		Reference: r.Reference,
+		Tendered:  tendered,
+		Change:    tendered - r.Amount,
+	}, nil
+}
+
+func (p *CashProvider) Void(ctx context.Context, reference string) error {
+	return nil
+}
+
+// Refund hands cash back from the drawer, there is no reference to return.
+func (p *CashProvider) Refund(ctx context.Context, reference string, amount int64, key string) (string, error) {
+	return "", nil
+}
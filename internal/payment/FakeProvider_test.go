@@ -0,0 +1,134 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestFakeProviderCharge(t *testing.T) {
+	tests := []struct {
+		name     string
+		decline  bool
+		request  ChargeRequest
+		wantErr  error
+		tendered int64
+	}{
+		{name: "card", request: ChargeRequest{Method: "card", Amount: 12050}, tendered: 12050},
+		{name: "promptpay", request: ChargeRequest{Method: "promptpay", Amount: 100}, tendered: 100},
+		{name: "declined", decline: true, request: ChargeRequest{Method: "card", Amount: 100}, wantErr: ErrDeclined},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewFakeProvider()
+			p.Decline = tt.decline
+			result, err := p.Charge(context.Background(), tt.request)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Charge() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if result.Provider != "fake" || result.Reference == "" || result.Tendered != tt.tendered || result.Change != 0 {
+				t.Errorf("Charge() = %+v, want a fake charge of %d without change", result, tt.tendered)
+			}
+			if !p.Charged(result.Reference) {
+				t.Errorf("Charged(%q) = false after the charge", result.Reference)
+			}
+		})
+	}
+}
+
+func TestFakeProviderChargeCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewFakeProvider().Charge(ctx, ChargeRequest{Method: "card", Amount: 100})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Charge() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestFakeProviderVoid(t *testing.T) {
+	p := NewFakeProvider()
+	charge, err := p.Charge(context.Background(), ChargeRequest{Method: "card", Amount: 500})
+	if err != nil {
+		t.Fatalf("Charge() error = %v", err)
+	}
+	if err := p.Void(context.Background(), charge.Reference); err != nil {
+		t.Fatalf("Void() error = %v", err)
+	}
+	if p.Charged(charge.Reference) {
+		t.Errorf("Charged(%q) = true after the void", charge.Reference)
+	}
+	if _, err := p.Refund(context.Background(), charge.Reference, 100, ""); err == nil {
+		t.Errorf("Refund() of a voided charge succeeded")
+	}
+}
+
+func TestFakeProviderUnknownCharge(t *testing.T) {
+	p := NewFakeProvider()
+	if err := p.Void(context.Background(), "FAKE-card-000001"); err == nil {
+		t.Errorf("Void() of an unknown charge succeeded")
+	}
+	if _, err := p.Refund(context.Background(), "FAKE-card-000001", 100, ""); err == nil {
+		t.Errorf("Refund() of an unknown charge succeeded")
+	}
+}
+
+func TestFakeProviderRefund(t *testing.T) {
+	tests := []struct {
+		name    string
+		refunds []int64
+		wantErr bool
+	}{
+		{name: "full", refunds: []int64{1000}},
+		{name: "partial twice", refunds: []int64{400, 600}},
+		{name: "more than charged", refunds: []int64{1001}, wantErr: true},
+		{name: "more than left", refunds: []int64{700, 400}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewFakeProvider()
+			charge, err := p.Charge(context.Background(), ChargeRequest{Method: "card", Amount: 1000})
+			if err != nil {
+				t.Fatalf("Charge() error = %v", err)
+			}
+			for i, amount := range tt.refunds {
+				reference, err := p.Refund(context.Background(), charge.Reference, amount, fmt.Sprint("refund-", i))
+				last := i == len(tt.refunds)-1
+				if err != nil {
+					if !last || !tt.wantErr {
+						t.Fatalf("Refund(%d) error = %v", amount, err)
+					}
+					return
+				}
+				if reference == "" || reference == charge.Reference {
+					t.Errorf("Refund(%d) reference = %q, want a new reference", amount, reference)
+				}
+			}
+			if tt.wantErr {
+				t.Errorf("Refund() of %v succeeded, want an error", tt.refunds)
+			}
+		})
+	}
+}
+
+func TestFakeProviderRefundRetried(t *testing.T) {
+	p := NewFakeProvider()
+	charge, err := p.Charge(context.Background(), ChargeRequest{Method: "card", Amount: 1000})
+	if err != nil {
+		t.Fatalf("Charge() error = %v", err)
+	}
+	first, err := p.Refund(context.Background(), charge.Reference, 600, "refund-1")
+	if err != nil {
+		t.Fatalf("Refund() error = %v", err)
+	}
+	retried, err := p.Refund(context.Background(), charge.Reference, 600, "refund-1")
+	if err != nil || retried != first {
+		t.Fatalf("Refund() retried = %q, %v, want %q", retried, err, first)
+	}
+	if _, err := p.Refund(context.Background(), charge.Reference, 400, "refund-2"); err != nil {
+		t.Errorf("Refund() of what is left after a retry error = %v", err)
+	}
+}
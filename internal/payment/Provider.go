@@ -0,0 +1,63 @@
+package payment
+
+import (
+	"Restaurant/utils/enums"
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrDeclined         = errors.New("payment declined")
+	ErrInsufficientCash = errors.New("amount tendered is less than the amount due")
+)
+
+// ChargeRequest asks a provider to collect Amount satang, Tendered is the cash handed over and is ignored by non-cash providers.
+type ChargeRequest struct {
+	Method    string
+	Amount    int64
+	Tendered  int64
+	Reference string
+}
+
+type ChargeResult struct {
+	Provider  string
+	Reference string
+	Tendered  int64
+	Change    int64
+}
+
+// Provider collects money for one or more payment methods. Void cancels a whole charge, either
+// when a later tender of the same payment fails or when a paid bill is voided. Refund gives back
+// part or all of a charge and returns the provider's reference for the refund. A refund retried
+// with the same key is only made once and returns the reference of the first.
+type Provider interface {
+	Name() string
+	Charge(ctx context.Context, r ChargeRequest) (ChargeResult, error)
+	Void(ctx context.Context, reference string) error
+	Refund(ctx context.Context, reference string, amount int64, key string) (string, error)
+}
+
+// Registry maps each payment method to the provider that collects it.
+type Registry map[string]Provider
+
+func (r Registry) Provider(method string) (Provider, bool) {
+	provider, ok := r[method]
+	return provider, ok
+}
+
+// NewRegistry takes cash at the counter and sends card and PromptPay payments to the named provider.
+func NewRegistry(provider string) (Registry, error) {
+	var electronic Provider
+	switch provider {
+	case "fake":
+		electronic = NewFakeProvider()
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q", provider)
+	}
+	return Registry{
+		enums.MethodCash:      &CashProvider{},
+		enums.MethodCard:      electronic,
+		enums.MethodPromptPay: electronic,
+	}, nil
+}
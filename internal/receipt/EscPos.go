@@ -0,0 +1,68 @@
+package receipt
+
+import (
+	"bytes"
+	"unicode"
+)
+
+var (
+	escPosInit     = []byte{0x1b, '@'}
+	escPosCodePage = []byte{0x1b, 't'}
+	escPosBoldOn   = []byte{0x1b, 'E', 1}
+	escPosBoldOff  = []byte{0x1b, 'E', 0}
+	escPosFeedCut  = []byte{0x1b, 'd', 4, 0x1d, 'V', 66, 0}
+)
+
+// RenderEscPos renders the receipt as text for ESC/POS thermal printers: it resets the
+// printer, selects the Thai code page, prints width-column lines with bold totals, then
+// feeds and cuts the paper. Text is sent in CP874, the Windows superset of TIS-620.
+func RenderEscPos(r *Receipt, width int) []byte {
+	var buf bytes.Buffer
+	buf.Write(escPosInit)
+	buf.Write(escPosCodePage)
+	buf.WriteByte(r.CodePage)
+	for _, l := range layout(r, width) {
+		if l.Bold {
+			buf.Write(escPosBoldOn)
+		}
+		buf.Write(cp874(l.Text))
+		buf.WriteByte('\n')
+		if l.Bold {
+			buf.Write(escPosBoldOff)
+		}
+	}
+	buf.Write(escPosFeedCut)
+	return buf.Bytes()
+}
+
+// cp874 encodes text in CP874. Characters it does not have print as "?", except combining
+// marks which are dropped so the columns still line up.
+func cp874(text string) []byte {
+	out := make([]byte, 0, len(text))
+	for _, c := range text {
+		switch {
+		case c < 0x80:
+			out = append(out, byte(c))
+		case c >= 0x0e01 && c <= 0x0e3a, c >= 0x0e3f && c <= 0x0e5b:
+			out = append(out, byte(c-0x0e01+0xa1))
+		case c == 0x00a0:
+			out = append(out, 0xa0)
+		case c == 0x20ac:
+			out = append(out, 0x80)
+		case c == 0x2026:
+			out = append(out, 0x85)
+		case c >= 0x2018 && c <= 0x2019:
+			out = append(out, byte(c-0x2018+0x91))
+		case c >= 0x201c && c <= 0x201d:
+			out = append(out, byte(c-0x201c+0x93))
+		case c == 0x2022:
+			out = append(out, 0x95)
+		case c >= 0x2013 && c <= 0x2014:
+			out = append(out, byte(c-0x2013+0x96))
+		case unicode.Is(unicode.Mn, c):
+		default:
+			out = append(out, '?')
+		}
+	}
+	return out
+}
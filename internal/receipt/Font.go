@@ -0,0 +1,182 @@
+package receipt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Font is a TrueType font embedded in PDF receipts, so Thai and other names outside Latin-1
+// print as written. Only the tables the PDF needs are read: glyph lookup, widths and metrics.
+type Font struct {
+	data       []byte
+	cmap       []byte
+	unitsPerEm int
+	ascent     int
+	descent    int
+	bbox       [4]int
+	advances   []int
+	numGlyphs  int
+}
+
+// LoadFont reads and parses a .ttf file.
+func LoadFont(path string) (*Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read font: %v", err)
+	}
+	font, err := ParseFont(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font %s: %v", path, err)
+	}
+	return font, nil
+}
+
+// ParseFont parses a TrueType font. Fonts with CFF outlines and font collections are rejected
+// because PDF embeds them differently.
+func ParseFont(data []byte) (*Font, error) {
+	if len(data) < 12 {
+		return nil, errors.New("file is too short")
+	}
+	if version := binary.BigEndian.Uint32(data); version != 0x00010000 && version != 0x74727565 {
+		return nil, errors.New("not a TrueType font")
+	}
+	tables := map[string][]byte{}
+	count := int(binary.BigEndian.Uint16(data[4:]))
+	for i := 0; i < count; i++ {
+		record := 12 + 16*i
+		if record+16 > len(data) {
+			return nil, errors.New("table directory is cut short")
+		}
+		offset := int(binary.BigEndian.Uint32(data[record+8:]))
+		length := int(binary.BigEndian.Uint32(data[record+12:]))
+		if offset < 0 || length < 0 || offset+length > len(data) {
+			return nil, fmt.Errorf("table %s is out of bounds", data[record:record+4])
+		}
+		tables[string(data[record:record+4])] = data[offset : offset+length]
+	}
+	for _, tag := range []string{"head", "hhea", "hmtx", "maxp", "cmap", "glyf", "loca"} {
+		if tables[tag] == nil {
+			return nil, fmt.Errorf("missing %s table", tag)
+		}
+	}
+	head, hhea, maxp := tables["head"], tables["hhea"], tables["maxp"]
+	if len(head) < 54 || len(hhea) < 36 || len(maxp) < 6 {
+		return nil, errors.New("head, hhea or maxp table is cut short")
+	}
+	f := &Font{
+		data:       data,
+		unitsPerEm: int(binary.BigEndian.Uint16(head[18:])),
+		ascent:     int(int16(binary.BigEndian.Uint16(hhea[4:]))),
+		descent:    int(int16(binary.BigEndian.Uint16(hhea[6:]))),
+		numGlyphs:  int(binary.BigEndian.Uint16(maxp[4:])),
+	}
+	if f.unitsPerEm == 0 {
+		return nil, errors.New("units per em is zero")
+	}
+	for i := range f.bbox {
+		f.bbox[i] = int(int16(binary.BigEndian.Uint16(head[36+2*i:])))
+	}
+	metrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	hmtx := tables["hmtx"]
+	if metrics == 0 || len(hmtx) < 4*metrics {
+		return nil, errors.New("hmtx table is cut short")
+	}
+	f.advances = make([]int, metrics)
+	for i := range f.advances {
+		f.advances[i] = int(binary.BigEndian.Uint16(hmtx[4*i:]))
+	}
+	cmap, err := unicodeCmap(tables["cmap"])
+	if err != nil {
+		return nil, err
+	}
+	f.cmap = cmap
+	return f, nil
+}
+
+// unicodeCmap finds the format 4 Unicode BMP subtable of the cmap table.
+func unicodeCmap(cmap []byte) ([]byte, error) {
+	if len(cmap) < 4 {
+		return nil, errors.New("cmap table is cut short")
+	}
+	count := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := 0; i < count; i++ {
+		record := 4 + 8*i
+		if record+8 > len(cmap) {
+			break
+		}
+		platform := binary.BigEndian.Uint16(cmap[record:])
+		encoding := binary.BigEndian.Uint16(cmap[record+2:])
+		offset := int(binary.BigEndian.Uint32(cmap[record+4:]))
+		if !(platform == 3 && encoding == 1) && platform != 0 {
+			continue
+		}
+		if offset+14 > len(cmap) || binary.BigEndian.Uint16(cmap[offset:]) != 4 {
+			continue
+		}
+		length := int(binary.BigEndian.Uint16(cmap[offset+2:]))
+		if offset+length > len(cmap) {
+			return nil, errors.New("cmap subtable is cut short")
+		}
+		return cmap[offset : offset+length], nil
+	}
+	return nil, errors.New("no Unicode format 4 cmap subtable")
+}
+
+// Glyph returns the glyph of a character, or false when the font does not have one.
+func (f *Font) Glyph(c rune) (uint16, bool) {
+	if c < 0 || c > 0xffff {
+		return 0, false
+	}
+	code := uint16(c)
+	segments := int(binary.BigEndian.Uint16(f.cmap[6:])) / 2
+	ends := 14
+	starts := ends + 2*segments + 2
+	deltas := starts + 2*segments
+	rangeOffsets := deltas + 2*segments
+	if rangeOffsets+2*segments > len(f.cmap) {
+		return 0, false
+	}
+	for i := 0; i < segments; i++ {
+		if binary.BigEndian.Uint16(f.cmap[ends+2*i:]) < code {
+			continue
+		}
+		start := binary.BigEndian.Uint16(f.cmap[starts+2*i:])
+		if start > code {
+			return 0, false
+		}
+		delta := binary.BigEndian.Uint16(f.cmap[deltas+2*i:])
+		rangeOffset := int(binary.BigEndian.Uint16(f.cmap[rangeOffsets+2*i:]))
+		glyph := code + delta
+		if rangeOffset != 0 {
+			at := rangeOffsets + 2*i + rangeOffset + 2*int(code-start)
+			if at+2 > len(f.cmap) {
+				return 0, false
+			}
+			glyph = binary.BigEndian.Uint16(f.cmap[at:])
+			if glyph == 0 {
+				return 0, false
+			}
+			glyph += delta
+		}
+		if glyph == 0 || int(glyph) >= f.numGlyphs {
+			return 0, false
+		}
+		return glyph, true
+	}
+	return 0, false
+}
+
+// advance is the width of a glyph in thousandths of the font size, as PDF measures it.
+func (f *Font) advance(glyph uint16) int {
+	i := int(glyph)
+	if i >= len(f.advances) {
+		i = len(f.advances) - 1
+	}
+	return f.scale(f.advances[i])
+}
+
+func (f *Font) scale(units int) int {
+	return units * 1000 / f.unitsPerEm
+}
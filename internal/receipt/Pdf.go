@@ -0,0 +1,198 @@
+package receipt
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	pdfFontSize = 8.0
+	pdfLeading  = 10.0
+	pdfMargin   = 12.0
+	// Courier glyphs are 600/1000 em wide, embedded fonts are fitted to the same cells
+	pdfCellUnits = 600
+	pdfCharWidth = pdfFontSize * pdfCellUnits / 1000
+	// stroke width that thickens bold lines of an embedded font
+	pdfBoldStroke = 0.3
+)
+
+// RenderPDF renders the receipt as a one-page PDF sized like a thermal receipt. With a Font it
+// embeds the font, otherwise it uses the standard Courier fonts and characters outside Latin-1
+// are printed as "?".
+func RenderPDF(r *Receipt, width int) []byte {
+	lines := layout(r, width)
+	pageWidth := float64(width)*pdfCharWidth + 2*pdfMargin
+	pageHeight := float64(len(lines))*pdfLeading + 2*pdfMargin
+	if r.Font != nil {
+		return embeddedFontPDF(r.Font, lines, pageWidth, pageHeight)
+	}
+
+	var content bytes.Buffer
+	content.WriteString("BT\n")
+	fmt.Fprintf(&content, "%.2f TL\n", pdfLeading)
+	fmt.Fprintf(&content, "%.2f %.2f Td\n", pdfMargin, pageHeight-pdfMargin-pdfFontSize)
+	for _, l := range lines {
+		font := "F1"
+		if l.Bold {
+			font = "F2"
+		}
+		fmt.Fprintf(&content, "/%s %.1f Tf\n(%s) Tj T*\n", font, pdfFontSize, pdfString(l.Text))
+	}
+	content.WriteString("ET\n")
+
+	return writePDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 5 0 R /F2 6 0 R >> >> /Contents 4 0 R >>", pageWidth, pageHeight),
+		pdfStream(content.Bytes(), ""),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>",
+	})
+}
+
+// embeddedFontPDF draws every cell of the layout at its column, squeezing glyphs wider than a
+// cell, so the columns line up as they do in Courier. Combining marks are drawn with the
+// character they sit on. Bold lines are stroked as well as filled.
+func embeddedFontPDF(font *Font, lines []line, pageWidth float64, pageHeight float64) []byte {
+	replacement, _ := font.Glyph('?')
+	used := map[uint16]rune{}
+	var content bytes.Buffer
+	fmt.Fprintf(&content, "%.2f w\nBT\n/F1 %.1f Tf\n", pdfBoldStroke, pdfFontSize)
+	for i, l := range lines {
+		y := pageHeight - pdfMargin - pdfFontSize - float64(i)*pdfLeading
+		if l.Bold {
+			content.WriteString("2 Tr\n")
+		} else {
+			content.WriteString("0 Tr\n")
+		}
+		for column, cell := range cells(l.Text) {
+			if cell == " " {
+				continue
+			}
+			var glyphs strings.Builder
+			advance := 0
+			for _, c := range cell {
+				glyph, ok := font.Glyph(c)
+				if !ok {
+					if unicode.Is(unicode.Mn, c) {
+						continue
+					}
+					glyph, c = replacement, '?'
+				}
+				if glyphs.Len() == 0 {
+					advance = font.advance(glyph)
+				}
+				used[glyph] = c
+				fmt.Fprintf(&glyphs, "%04X", glyph)
+			}
+			if glyphs.Len() == 0 {
+				continue
+			}
+			scale := 1.0
+			if advance > pdfCellUnits {
+				scale = float64(pdfCellUnits) / float64(advance)
+			}
+			x := pdfMargin + float64(column)*pdfCharWidth + (pdfCharWidth-float64(advance)*scale*pdfFontSize/1000)/2
+			fmt.Fprintf(&content, "%.3f 0 0 1 %.2f %.2f Tm <%s> Tj\n", scale, x, y, glyphs.String())
+		}
+	}
+	content.WriteString("ET\n")
+
+	glyphs := make([]int, 0, len(used))
+	for glyph := range used {
+		glyphs = append(glyphs, int(glyph))
+	}
+	sort.Ints(glyphs)
+	var widths, toUnicode strings.Builder
+	for i, glyph := range glyphs {
+		fmt.Fprintf(&widths, "%d [%d] ", glyph, font.advance(uint16(glyph)))
+		if i%100 == 0 {
+			if i > 0 {
+				toUnicode.WriteString("endbfchar\n")
+			}
+			fmt.Fprintf(&toUnicode, "%d beginbfchar\n", min(100, len(glyphs)-i))
+		}
+		fmt.Fprintf(&toUnicode, "<%04X> <%04X>\n", glyph, used[uint16(glyph)])
+	}
+	if len(glyphs) > 0 {
+		toUnicode.WriteString("endbfchar\n")
+	}
+	cmap := "/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n" + toUnicode.String() +
+		"endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n"
+
+	var fontFile bytes.Buffer
+	w := zlib.NewWriter(&fontFile)
+	w.Write(font.data)
+	w.Close()
+
+	return writePDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>", pageWidth, pageHeight),
+		pdfStream(content.Bytes(), ""),
+		"<< /Type /Font /Subtype /Type0 /BaseFont /ReceiptFont /Encoding /Identity-H " +
+			"/DescendantFonts [6 0 R] /ToUnicode 8 0 R >>",
+		fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /ReceiptFont "+
+			"/CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> "+
+			"/FontDescriptor 7 0 R /CIDToGIDMap /Identity /W [%s] >>", strings.TrimSpace(widths.String())),
+		fmt.Sprintf("<< /Type /FontDescriptor /FontName /ReceiptFont /Flags 4 /FontBBox [%d %d %d %d] "+
+			"/ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 9 0 R >>",
+			font.scale(font.bbox[0]), font.scale(font.bbox[1]), font.scale(font.bbox[2]), font.scale(font.bbox[3]),
+			font.scale(font.ascent), font.scale(font.descent), font.scale(font.ascent)),
+		pdfStream([]byte(cmap), ""),
+		pdfStream(fontFile.Bytes(), fmt.Sprintf(" /Length1 %d /Filter /FlateDecode", len(font.data))),
+	})
+}
+
+// pdfStream wraps data in a stream object, entries are added to its dictionary after the length.
+func pdfStream(data []byte, entries string) string {
+	return fmt.Sprintf("<< /Length %d%s >>\nstream\n%s\nendstream", len(data), entries, data)
+}
+
+// writePDF numbers the objects from 1 in order and writes them with their cross-reference table.
+func writePDF(objects []string) []byte {
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = pdf.Len()
+		fmt.Fprintf(&pdf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := pdf.Len()
+	fmt.Fprintf(&pdf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&pdf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&pdf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return pdf.Bytes()
+}
+
+// pdfString escapes text for a PDF literal string in WinAnsi encoding. Combining marks outside
+// Latin-1 are dropped so the columns still line up.
+func pdfString(text string) string {
+	var b strings.Builder
+	for _, c := range text {
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(c)
+		case c >= 0x20 && c <= 0x7e:
+			b.WriteRune(c)
+		case c >= 0xa0 && c <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", c)
+		case unicode.Is(unicode.Mn, c):
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,215 @@
+// Package receipt renders a paid or part-paid bill as a PDF or as ESC/POS text for
+// 80mm thermal printers. Both formats share one fixed-width layout.
+package receipt
+
+import (
+	"Restaurant/internal/model"
+	"Restaurant/utils/enums"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	DefaultWidth = 42
+	MinWidth     = 32
+	MaxWidth     = 64
+)
+
+const (
+	FormatPDF    = "pdf"
+	FormatEscPos = "escpos"
+)
+
+type Receipt struct {
+	Title       string
+	TableNumber int
+	Order       model.Order
+	Bill        model.Bill
+	PrintedAt   time.Time
+	// Font is embedded in PDFs when set, otherwise they use Courier and print "?" outside Latin-1
+	Font *Font
+	// CodePage is the ESC t code page the printer uses for Thai, text is sent in CP874
+	CodePage byte
+}
+
+type Document struct {
+	ContentType string
+	FileName    string
+	Content     []byte
+}
+
+func IsFormat(format string) bool {
+	return format == FormatPDF || format == FormatEscPos
+}
+
+// Render renders the receipt in the given format with lines of width characters.
+func Render(r *Receipt, format string, width int) *Document {
+	if format == FormatEscPos {
+		return &Document{
+			ContentType: "text/plain; charset=windows-874",
+			FileName:    fmt.Sprintf("receipt-%d.txt", r.Order.OrderId),
+			Content:     RenderEscPos(r, width),
+		}
+	}
+	return &Document{
+		ContentType: "application/pdf",
+		FileName:    fmt.Sprintf("receipt-%d.pdf", r.Order.OrderId),
+		Content:     RenderPDF(r, width),
+	}
+}
+
+type line struct {
+	Text string
+	Bold bool
+}
+
+// layout lays the receipt out in lines of exactly width cells.
+func layout(r *Receipt, width int) []line {
+	var lines []line
+	add := func(text string, bold bool) {
+		lines = append(lines, line{Text: text, Bold: bold})
+	}
+	rule := strings.Repeat("-", width)
+
+	add(center(r.Title, width), true)
+	add(center("RECEIPT", width), false)
+	add(columns(fmt.Sprintf("Table %d", r.TableNumber), fmt.Sprintf("Order #%d", r.Order.OrderId), width), false)
+	add(columns("Bill date", formatTimestamp(r.Bill.BillDate), width), false)
+	add(rule, false)
+	for _, item := range r.Order.OrderItems {
+		if item.ItemStatus == enums.ItemVoided {
+			continue
+		}
+		add(columns(fmt.Sprintf("%d x %s", item.Quantity, item.Name), money(item.Price*float64(item.Quantity)), width), false)
+	}
+	add(rule, false)
+	add(columns("Subtotal", money(r.Bill.Subtotal), width), false)
+	for _, promotion := range r.Bill.Promotions {
+		label := "Discount " + promotion.Name
+		if promotion.CouponCode != "" {
+			label += " (" + promotion.CouponCode + ")"
+		}
+		add(columns(label, money(-promotion.Discount), width), false)
+	}
+	if r.Bill.ServiceCharge != 0 {
+		add(columns("Service charge "+rate(r.Bill.ServiceChargeRate), money(r.Bill.ServiceCharge), width), false)
+	}
+	if r.Bill.VatInclusive {
+		add(columns("VAT "+rate(r.Bill.VatRate)+" included", money(r.Bill.VatAmount), width), false)
+	} else {
+		add(columns("VAT "+rate(r.Bill.VatRate), money(r.Bill.VatAmount), width), false)
+	}
+	if r.Bill.Rounding != 0 {
+		add(columns("Rounding", money(r.Bill.Rounding), width), false)
+	}
+	add(columns("TOTAL", money(r.Bill.TotalAmount), width), true)
+	add(rule, false)
+
+	for _, payment := range r.Bill.Payments {
+		if payment.Status != enums.PaymentPaid {
+			continue
+		}
+		if payment.SplitMode != enums.SplitFull {
+			add(columns(payment.Label, money(payment.Amount), width), false)
+		}
+		for _, tender := range payment.Tenders {
+			label := "  " + strings.ToUpper(tender.Method)
+			if tender.Method != enums.MethodCash && tender.Reference != "" {
+				label += " " + tender.Reference
+			}
+			add(columns(label, money(tender.Amount), width), false)
+			if tender.Change > 0 {
+				add(columns("    Tendered", money(tender.Tendered), width), false)
+				add(columns("    Change", money(tender.Change), width), false)
+			}
+		}
+	}
+	if r.Bill.Status != enums.BillOpen {
+		add(columns("PAID", money(r.Bill.PaidAmount), width), true)
+		if r.Bill.RefundedAmount != 0 {
+			label := "REFUNDED"
+			if r.Bill.Status == enums.BillVoided {
+				label = "VOIDED"
+			}
+			add(columns(label, money(-r.Bill.RefundedAmount), width), true)
+			add(columns("NET", money(r.Bill.PaidAmount-r.Bill.RefundedAmount), width), true)
+		}
+	} else {
+		add(columns("Paid", money(r.Bill.PaidAmount), width), false)
+		add(columns("OUTSTANDING", money(r.Bill.Remaining), width), true)
+	}
+	add(rule, false)
+	add(columns("Printed", r.PrintedAt.Format("02/01/2006 15:04"), width), false)
+	add(center("Thank you", width), false)
+	return lines
+}
+
+// columns puts left and right on one line, cutting left short when both do not fit.
+// A right side wider than the line is printed as is.
+func columns(left string, right string, width int) string {
+	room := width - textWidth(right) - 1
+	if room < 0 {
+		room = 0
+	}
+	left = truncate(left, room)
+	padding := width - textWidth(left) - textWidth(right)
+	if padding < 0 {
+		padding = 0
+	}
+	return left + strings.Repeat(" ", padding) + right
+}
+
+func center(text string, width int) string {
+	text = truncate(text, width)
+	padding := width - textWidth(text)
+	return strings.Repeat(" ", padding/2) + text + strings.Repeat(" ", padding-padding/2)
+}
+
+func truncate(text string, width int) string {
+	parts := cells(text)
+	if len(parts) <= width {
+		return text
+	}
+	return strings.Join(parts[:width], "")
+}
+
+// cells splits text into the cells it prints in: a character with the combining marks after it,
+// such as the Thai vowels and tone marks written above or below a consonant.
+func cells(text string) []string {
+	var parts []string
+	start := 0
+	for i, c := range text {
+		if i > 0 && !unicode.Is(unicode.Mn, c) {
+			parts = append(parts, text[start:i])
+			start = i
+		}
+	}
+	if start < len(text) {
+		parts = append(parts, text[start:])
+	}
+	return parts
+}
+
+func textWidth(text string) int {
+	return len(cells(text))
+}
+
+func money(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
+
+func rate(percent float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", percent), "0"), ".") + "%"
+}
+
+// formatTimestamp shows a database timestamp. The restaurant stores Asia/Bangkok wall-clock time
+// and the driver reads it back as UTC, so the clock is shown as is rather than converted.
+func formatTimestamp(value string) string {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return value
+	}
+	return t.Format("02/01/2006 15:04")
+}
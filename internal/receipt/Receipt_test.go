@@ -0,0 +1,267 @@
+package receipt
+
+import (
+	"Restaurant/internal/model"
+	"Restaurant/utils/enums"
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestColumns(t *testing.T) {
+	tests := []struct {
+		name  string
+		left  string
+		right string
+		width int
+		want  string
+	}{
+		{name: "padded", left: "Subtotal", right: "120.00", width: 20, want: "Subtotal      120.00"},
+		{name: "left cut short", left: "2 x Pad Krapow Moo Kai Dao", right: "120.00", width: 20, want: "2 x Pad Krapo 120.00"},
+		{name: "right as wide as the line", left: "Total", right: "1234567890", width: 10, want: "1234567890"},
+		{name: "right wider than the line", left: "Total", right: "123456789012", width: 10, want: "123456789012"},
+		{name: "thai marks take no cell", left: "ไข่ดาว", right: "20.00", width: 11, want: "ไข่ดาว 20.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := columns(tt.left, tt.right, tt.width); got != tt.want {
+				t.Errorf("columns() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCenter(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  string
+	}{
+		{name: "even", text: "ab", width: 6, want: "  ab  "},
+		{name: "odd padding goes right", text: "abc", width: 6, want: " abc  "},
+		{name: "cut short", text: "Thank you", width: 5, want: "Thank"},
+		{name: "thai", text: "ร้านข้าว", width: 8, want: " ร้านข้าว "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := center(tt.text, tt.width); got != tt.want {
+				t.Errorf("center() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  string
+	}{
+		{name: "fits", text: "Rice", width: 4, want: "Rice"},
+		{name: "cut", text: "Rice", width: 2, want: "Ri"},
+		{name: "zero", text: "Rice", width: 0, want: ""},
+		{name: "keeps marks with their consonant", text: "น้ำแข็ง", width: 2, want: "น้ำ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncate(tt.text, tt.width); got != tt.want {
+				t.Errorf("truncate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{text: "", want: 0},
+		{text: "Coke", want: 4},
+		{text: "ผัดไทย", want: 5},
+		{text: "ต้มยำกุ้ง", want: 6},
+		{text: "น้ำ", want: 2},
+	}
+	for _, tt := range tests {
+		if got := textWidth(tt.text); got != tt.want {
+			t.Errorf("textWidth(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestLayoutWidth(t *testing.T) {
+	r := testReceipt()
+	for _, width := range []int{MinWidth, DefaultWidth, MaxWidth} {
+		for _, l := range layout(r, width) {
+			if got := textWidth(l.Text); got != width {
+				t.Errorf("layout(%d) line %q is %d cells wide", width, l.Text, got)
+			}
+		}
+	}
+}
+
+func TestCp874(t *testing.T) {
+	tests := []struct {
+		text string
+		want []byte
+	}{
+		{text: "Total 10.00", want: []byte("Total 10.00")},
+		{text: "กฮ", want: []byte{0xa1, 0xce}},
+		{text: "ไข่", want: []byte{0xe4, 0xa2, 0xe8}},
+		{text: "฿๙", want: []byte{0xdf, 0xf9}},
+		{text: "€…", want: []byte{0x80, 0x85}},
+		{text: "炒饭", want: []byte("??")},
+		{text: "\u00e9", want: []byte("?")},
+		{text: "e\u0301", want: []byte("e")},
+	}
+	for _, tt := range tests {
+		if got := cp874(tt.text); !bytes.Equal(got, tt.want) {
+			t.Errorf("cp874(%q) = % x, want % x", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestRenderEscPos(t *testing.T) {
+	r := testReceipt()
+	r.CodePage = 21
+	got := RenderEscPos(r, DefaultWidth)
+	if !bytes.HasPrefix(got, []byte{0x1b, '@', 0x1b, 't', 21}) {
+		t.Errorf("RenderEscPos() starts with % x, want a reset and ESC t 21", got[:5])
+	}
+	if !bytes.Contains(got, cp874("ผัดกะเพรา")) {
+		t.Errorf("RenderEscPos() does not contain the menu name in CP874")
+	}
+}
+
+func TestParseFont(t *testing.T) {
+	font, err := ParseFont(testFont())
+	if err != nil {
+		t.Fatalf("ParseFont() error = %v", err)
+	}
+	tests := []struct {
+		char    rune
+		glyph   uint16
+		ok      bool
+		advance int
+	}{
+		{char: 'A', glyph: 1, ok: true, advance: 600},
+		{char: 'ก', glyph: 2, ok: true, advance: 500},
+		{char: '่', glyph: 3, ok: true, advance: 0},
+		{char: 'B', ok: false},
+		{char: 0x1f600, ok: false},
+	}
+	for _, tt := range tests {
+		glyph, ok := font.Glyph(tt.char)
+		if glyph != tt.glyph || ok != tt.ok {
+			t.Errorf("Glyph(%q) = %d, %v, want %d, %v", tt.char, glyph, ok, tt.glyph, tt.ok)
+		}
+		if ok && font.advance(glyph) != tt.advance {
+			t.Errorf("advance(%d) = %d, want %d", glyph, font.advance(glyph), tt.advance)
+		}
+	}
+	for _, data := range [][]byte{nil, []byte("not a font at all"), testFont()[:40]} {
+		if _, err := ParseFont(data); err == nil {
+			t.Errorf("ParseFont(% x) succeeded, want an error", data)
+		}
+	}
+}
+
+func TestRenderPDF(t *testing.T) {
+	font, err := ParseFont(testFont())
+	if err != nil {
+		t.Fatalf("ParseFont() error = %v", err)
+	}
+	r := testReceipt()
+	courier := string(RenderPDF(r, DefaultWidth))
+	if !strings.Contains(courier, "/BaseFont /Courier") || strings.Contains(courier, "/FontFile2") {
+		t.Errorf("RenderPDF() without a font does not use Courier")
+	}
+	r.Font = font
+	embedded := string(RenderPDF(r, DefaultWidth))
+	for _, want := range []string{"/Subtype /CIDFontType2", "/FontFile2 9 0 R", "<00020003> Tj", "<0002> <0E01>", "<0003> <0E48>"} {
+		if !strings.Contains(embedded, want) {
+			t.Errorf("RenderPDF() with a font does not contain %q", want)
+		}
+	}
+	if strings.Contains(embedded, "/BaseFont /Courier") {
+		t.Errorf("RenderPDF() with a font still uses Courier")
+	}
+}
+
+func testReceipt() *Receipt {
+	return &Receipt{
+		Title:       "ร้านอาหารไทย",
+		TableNumber: 3,
+		Order: model.Order{OrderId: 7, OrderItems: []model.OrderItems{
+			{Name: "ผัดกะเพราหมูสับไข่ดาว", Quantity: 2, Price: 60},
+			{Name: "ก่", Quantity: 1, Price: 20},
+			{Name: "A very long menu item name that does not fit on one receipt line", Quantity: 1, Price: 1234567.5},
+		}},
+		Bill: model.Bill{
+			BillDate:    "2026-10-18T12:00:00Z",
+			Subtotal:    1234707.5,
+			TotalAmount: 1234707.5,
+			PaidAmount:  1234707.5,
+			Status:      enums.BillSettled,
+		},
+		PrintedAt: time.Date(2026, 10, 18, 12, 30, 0, 0, time.Local),
+	}
+}
+
+// testFont builds a TrueType font with glyphs for "A", "ก" and the tone mark "่".
+func testFont() []byte {
+	be := binary.BigEndian
+	head := make([]byte, 54)
+	be.PutUint32(head, 0x00010000)
+	be.PutUint16(head[18:], 1000)
+	hhea := make([]byte, 36)
+	be.PutUint16(hhea[4:], 800)
+	be.PutUint16(hhea[6:], uint16(0x10000-200))
+	be.PutUint16(hhea[34:], 4)
+	hmtx := make([]byte, 16)
+	for i, advance := range []uint16{500, 600, 500, 0} {
+		be.PutUint16(hmtx[4*i:], advance)
+	}
+	maxp := make([]byte, 6)
+	be.PutUint32(maxp, 0x00005000)
+	be.PutUint16(maxp[4:], 4)
+
+	codes := []uint16{'A', 0x0e01, 0x0e48, 0xffff}
+	subtable := make([]byte, 16+8*len(codes))
+	be.PutUint16(subtable, 4)
+	be.PutUint16(subtable[2:], uint16(len(subtable)))
+	be.PutUint16(subtable[6:], uint16(2*len(codes)))
+	for i, code := range codes {
+		be.PutUint16(subtable[14+2*i:], code)
+		be.PutUint16(subtable[16+2*len(codes)+2*i:], code)
+		delta := uint16(1)
+		if code != 0xffff {
+			delta = uint16(i+1) - code
+		}
+		be.PutUint16(subtable[16+4*len(codes)+2*i:], delta)
+	}
+	cmap := append([]byte{0, 0, 0, 1, 0, 3, 0, 1, 0, 0, 0, 12}, subtable...)
+
+	tables := []struct {
+		tag  string
+		data []byte
+	}{
+		{"cmap", cmap}, {"glyf", []byte{}}, {"head", head}, {"hhea", hhea},
+		{"hmtx", hmtx}, {"loca", make([]byte, 10)}, {"maxp", maxp},
+	}
+	font := make([]byte, 12+16*len(tables))
+	be.PutUint32(font, 0x00010000)
+	be.PutUint16(font[4:], uint16(len(tables)))
+	for i, table := range tables {
+		record := font[12+16*i:]
+		copy(record, table.tag)
+		be.PutUint32(record[8:], uint32(len(font)))
+		be.PutUint32(record[12:], uint32(len(table.data)))
+		font = append(font, table.data...)
+	}
+	return font
+}
@@ -0,0 +1,100 @@
+// Package report writes reports as CSV for spreadsheets, the API returns the same
+// reports as JSON.
+package report
+
+import (
+	"Restaurant/internal/model"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+type Document struct {
+	ContentType string
+	FileName    string
+	Content     []byte
+}
+
+func IsFormat(format string) bool {
+	return format == FormatJSON || format == FormatCSV
+}
+
+// SalesCSV writes one line per row of the report followed by a TOTAL line.
+func SalesCSV(r *model.SalesReport) (*Document, error) {
+	records := [][]string{{"period", "orders", "gross", "discount", "service_charge", "vat", "refunded", "net", "average_ticket"}}
+	rows := make([]model.SalesRow, 0, len(r.Rows)+1)
+	rows = append(append(rows, r.Rows...), r.Totals)
+	for _, row := range rows {
+		records = append(records, []string{
+			text(row.Period),
+			strconv.Itoa(row.Orders),
+			money(row.Gross),
+			money(row.Discount),
+			money(row.ServiceCharge),
+			money(row.Vat),
+			money(row.Refunded),
+			money(row.Net),
+			money(row.AverageTicket),
+		})
+	}
+	return write(fmt.Sprintf("sales-%s-%s-%s.csv", r.GroupBy, r.From, r.To), records)
+}
+
+// MenuItemsCSV writes one line per dish, the rating is left empty for dishes nobody reviewed.
+func MenuItemsCSV(r *model.MenuItemReport) (*Document, error) {
+	records := [][]string{{"menu_item_id", "name", "category", "units_ordered", "units_sold", "units_canceled",
+		"units_refunded", "revenue", "cancellation_rate", "average_rating", "ratings"}}
+	for _, item := range r.Items {
+		rating := ""
+		if item.Ratings > 0 {
+			rating = money(item.AverageRating)
+		}
+		records = append(records, []string{
+			strconv.Itoa(item.MenuItemId),
+			text(item.Name),
+			text(item.Category),
+			strconv.Itoa(item.UnitsOrdered),
+			strconv.Itoa(item.UnitsSold),
+			strconv.Itoa(item.UnitsCanceled),
+			strconv.Itoa(item.UnitsRefunded),
+			money(item.Revenue),
+			money(item.CancellationRate),
+			rating,
+			strconv.Itoa(item.Ratings),
+		})
+	}
+	return write(fmt.Sprintf("menu-items-%s-%s.csv", r.From, r.To), records)
+}
+
+func write(fileName string, records [][]string) (*Document, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(records); err != nil {
+		return nil, err
+	}
+	return &Document{
+		ContentType: "text/csv; charset=utf-8",
+		FileName:    fileName,
+		Content:     buf.Bytes(),
+	}, nil
+}
+
+// text quotes a cell a spreadsheet would otherwise run as a formula, such as a dish named
+// "=HYPERLINK(...)", by putting an apostrophe in front of it.
+func text(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func money(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
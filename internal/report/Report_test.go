@@ -0,0 +1,45 @@
+package report
+
+import (
+	"Restaurant/internal/model"
+	"strings"
+	"testing"
+)
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "", want: ""},
+		{value: "Pad Thai", want: "Pad Thai"},
+		{value: "ผัดไทย", want: "ผัดไทย"},
+		{value: "=HYPERLINK(\"http://x\")", want: "'=HYPERLINK(\"http://x\")"},
+		{value: "+1", want: "'+1"},
+		{value: "-1", want: "'-1"},
+		{value: "@SUM(A1)", want: "'@SUM(A1)"},
+		{value: "a=b", want: "a=b"},
+	}
+	for _, tt := range tests {
+		if got := text(tt.value); got != tt.want {
+			t.Errorf("text(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestMenuItemsCSV(t *testing.T) {
+	doc, err := MenuItemsCSV(&model.MenuItemReport{
+		From: "2026-10-01",
+		To:   "2026-10-31",
+		Items: []model.MenuItemStats{
+			{MenuItemId: 1, Name: "=1+1", Category: "@Drinks", UnitsSold: 2, Revenue: -5},
+		},
+	})
+	if err != nil {
+		t.Fatalf("MenuItemsCSV() error = %v", err)
+	}
+	want := "1,'=1+1,'@Drinks,0,2,0,0,-5.00,0.00,,0\n"
+	if !strings.HasSuffix(string(doc.Content), want) {
+		t.Errorf("MenuItemsCSV() = %q, want it to end with %q", doc.Content, want)
+	}
+}
@@ -5,61 +5,246 @@ import (
 	"Restaurant/database"
 	"Restaurant/internal/model"
 	"Restaurant/internal/request"
+	"Restaurant/utils/enums"
 	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/go-sql-driver/mysql"
 	"log"
 	"strings"
 	"time"
 )
 
+// ErrAlreadyReviewed is returned when a dish was rated on the order before, the reviews table
+// allows one review per dish and order.
+var ErrAlreadyReviewed = errors.New("menu item already reviewed")
+
+// mysqlDuplicateEntry is the MySQL error number for a row breaking a unique key.
+const mysqlDuplicateEntry = 1062
+
 type RestaurantRepository interface {
-	GetAllMenu() ([]model.Menus, error)
+	GetAllMenu(f *request.MenuFilterRequest) ([]model.Menus, int, error)
 	FindTableById(c *request.OrderRequest) (bool, error)
 	FindTableByTableRequestId(c *request.TableRequest) (bool, string, error)
-	FindMenuItemById(c []request.MenuItem) ([]int, error)
+	FindMenuItemById(c []request.MenuItem, tx *sql.Tx) ([]int, error)
 	InsertOrder(c *request.OrderRequest, tx *sql.Tx) (int64, error)
 	InsertOrderItems(orderID int64, menuItems []request.MenuItem, tx *sql.Tx) error
 	FindOrderById(r *request.OrderRequest) (bool, error)
-	UpdateOrder(tableId int, orderId int, status string) error
 	UpdateOrderWithTx(tableId int, orderId int, status string, tx *sql.Tx) error
-	DeleteOrder(r *request.OrderRequest) error
-	PayOrder(r *request.OrderRequest, tx *sql.Tx) error
-	CheckOrderStatus(r *request.OrderRequest, tx *sql.Tx) (string, error)
-	CheckOrderStatusWithOutTx(r *request.OrderRequest) (string, error)
+	DeleteOrder(r *request.OrderRequest, tx *sql.Tx) error
 	HasOrderBeenReviewed(r *request.OrderRequest, tx *sql.Tx) (bool, error)
 	ReviewOrder(r *request.OrderRequest, tx *sql.Tx) error
+	GetOrderMenuItemIds(orderId int, tx *sql.Tx) ([]int, error)
+	GetReviewedMenuItemIds(orderId int, tx *sql.Tx) ([]int, error)
+	ReviewOrderItems(orderId int, items []request.ItemReview, tx *sql.Tx) error
 	GetOrderDetails(r *request.OrderRequest) (*model.Order, error)
 	GetOrderHistory(r *request.OrderRequest) ([]model.ViewOrder, error)
-	UpdateTable(r *request.TableRequest) error
-	DeleteAllOrderWhenCheckOut(r *request.TableRequest) error
+	FindMenuItemByMenuRequestId(r *request.MenuRequest) (bool, error)
+	InsertMenuItem(r *request.MenuRequest) (int64, error)
+	UpdateMenuItem(r *request.MenuRequest) error
+	UpdateMenuItemAvailability(r *request.MenuRequest) error
+	DeleteMenuItem(r *request.MenuRequest) error
+	GetMenuItemImage(menuItemsId int) (string, error)
+	UpdateMenuItemImage(menuItemsId int, fileName string) error
+	GetAllCategories() ([]model.Category, error)
+	FindCategoryById(categoryId int) (bool, error)
+	InsertCategory(r *request.CategoryRequest) (int64, error)
+	UpdateCategory(r *request.CategoryRequest) error
+	DeleteCategory(r *request.CategoryRequest) error
+	GetAllTables() ([]model.Table, error)
+	FindTableByNumber(tableNumber int) (*model.Table, error)
+	FindTableNumberById(tableId int) (int, error)
+	FindRetiredTableByNumber(tableNumber int) (int, error)
+	InsertTable(r *request.TableRequest) (int64, error)
+	RestoreTable(tableId int) error
+	UpdateTableLayout(tr *request.TableRequest) error
+	UpdateTableNumber(r *request.TableRequest) error
+	DeleteTable(r *request.TableRequest) error
+	CountOpenOrdersByTable(tableId int) (int, error)
+	CountLiveOrdersByTable(tableId int) (int, error)
+	IsTableSessionActive(sessionId int, tableId int) (bool, error)
+	FindActiveTableSession(tableId int) (int, error)
+	GetKitchenQueue() ([]model.KitchenOrder, error)
+	FindOrderItemById(orderItemId int, tx *sql.Tx) (*model.OrderItemState, error)
+	UpdateOrderItemStatus(orderItemId int, status string, tx *sql.Tx) error
+	GetOrderItemStatuses(orderId int, tx *sql.Tx) ([]string, error)
+	LockOrder(orderId int, tableId int, tx *sql.Tx) (string, int, error)
+	UpdateOrderItemQuantity(orderId int, orderItemId int, quantity int, tx *sql.Tx) (bool, error)
+	VoidOrderItem(orderId int, orderItemId int, tx *sql.Tx) (bool, error)
+	FindBillByOrderId(orderId int, tx *sql.Tx) (*model.Bill, error)
+	InsertBill(bill *model.Bill, tx *sql.Tx) (int64, error)
+	GetBillPayments(billId int, tx *sql.Tx) ([]model.Payment, error)
+	GetUnpaidBillItems(orderId int, tx *sql.Tx) ([]model.BillItem, error)
+	CancelPendingPayments(billId int, tx *sql.Tx) error
+	InsertPayment(p *model.Payment, tx *sql.Tx) (int64, error)
+	FindPaymentById(paymentId int, tx *sql.Tx) (*model.Payment, error)
+	SettlePayment(paymentId int, tx *sql.Tx) error
+	AddBillPaidAmount(billId int, amount float64, tx *sql.Tx) error
+	InsertTenders(paymentId int, tenders []model.Tender, tx *sql.Tx) error
+	UpdateRefundTender(paymentId int, tender model.Tender, tx *sql.Tx) error
+	CancelPayment(paymentId int, tx *sql.Tx) error
+	UpdateBillAmounts(bill *model.Bill, tx *sql.Tx) error
+	GetAllPromotions() ([]model.Promotion, error)
+	FindPromotionById(promotionId int) (bool, error)
+	IsCouponCodeTaken(couponCode string, promotionId int) (bool, error)
+	InsertPromotion(r *request.PromotionRequest) (int64, error)
+	UpdatePromotion(r *request.PromotionRequest) error
+	DeletePromotion(r *request.PromotionRequest) error
+	GetActivePromotions(couponCodes []string, tx *sql.Tx) ([]model.Promotion, error)
+	UsePromotion(promotionId int, tx *sql.Tx) (bool, error)
+	InsertBillPromotions(billId int, applied []model.AppliedPromotion, tx *sql.Tx) error
+	InsertBillItems(billId int, items []model.BillItem, tx *sql.Tx) error
+	GetBillPromotions(billId int, tx *sql.Tx) ([]model.AppliedPromotion, error)
+	ReleaseBillPromotions(billId int, tx *sql.Tx) error
+	GetOrderBillItems(orderId int, tx *sql.Tx) ([]model.BillItem, error)
+	GetRefundedItemIds(billId int, tx *sql.Tx) ([]int, error)
+	GetRefundableTenders(billId int, tx *sql.Tx) ([]model.Tender, error)
+	InsertRefund(refund *model.Refund, tx *sql.Tx) (int64, error)
+	FindRefundById(refundId int, tx *sql.Tx) (*model.Refund, error)
+	GetRefundTenders(paymentId int, tx *sql.Tx) ([]model.Tender, error)
+	ReopenRefundTenders(paymentId int, tx *sql.Tx) error
+	DeleteRefund(refundId int, tx *sql.Tx) error
+	AddBillRefund(billId int, amount float64, status string, tx *sql.Tx) error
+	GetBillRefunds(billId int, tx *sql.Tx) ([]model.Refund, error)
+	GetSalesReport(from string, to string, groupBy string) ([]model.SalesRow, error)
+	GetMenuItemStats(from string, to string) ([]model.MenuItemStats, error)
+	GetAllReviews(f *request.ReviewFilterRequest) ([]model.Review, int, error)
+	GetReviewSummary(f *request.ReviewFilterRequest) (*model.ReviewSummary, error)
+	FindReviewById(reviewId int) (bool, error)
+	ReplyReview(rr *request.ReviewReplyRequest) error
+	ModerateReview(rr *request.ReviewModerationRequest) error
+	DeleteReview(rr *request.ReviewModerationRequest) error
+	GetAllReservations(f *request.ReservationFilterRequest) ([]model.Reservation, error)
+	FindReservationById(reservationId int, tx *sql.Tx) (*model.Reservation, error)
+	LockTable(tableId int, tx *sql.Tx) (string, int, error)
+	HasReservationConflict(tableId int, start string, end string, reservationId int, tx *sql.Tx) (bool, error)
+	InsertReservation(rr *request.ReservationRequest, reservedAt string, tx *sql.Tx) (int64, error)
+	UpdateReservation(rr *request.ReservationRequest, reservedAt string, tx *sql.Tx) error
+	UpdateReservationStatus(reservationId int, status string, tx *sql.Tx) error
+	UpdateTableWithTx(tableId int, tableStatus string, tx *sql.Tx) error
+	SyncReservedTables(holdUntil string, noShowBefore string, tx *sql.Tx) (int64, error)
+	GetWaitlist() ([]model.WaitlistEntry, error)
+	FindWaitlistEntryById(waitlistId int, tx *sql.Tx) (*model.WaitlistEntry, error)
+	FindNotifiedParty(tableId int, tx *sql.Tx) (*model.WaitlistEntry, error)
+	FindNextWaitingParty(capacity int, tx *sql.Tx) (*model.WaitlistEntry, error)
+	InsertWaitlistEntry(wr *request.WaitlistRequest, quotedWaitMinutes int) (int64, error)
+	UpdateWaitlistStatus(waitlistId int, status string, tableId int, tx *sql.Tx) error
+	ReleaseNotifiedParties(tableId int, waitlistId int, tx *sql.Tx) error
+	GetTableOccupancy(now string) ([]model.TableOccupancy, error)
+	GetAverageDiningMinutes(since string) (float64, error)
+	GetFloorPlan(now string, dayEnd string) ([]model.FloorTable, error)
+	GetTableSuggestions(partySize int, zone string, until string) ([]model.Table, error)
+	LockTableStates(tableIds []int, tx *sql.Tx) (map[int]model.TableState, error)
+	GetGroupMembers(tableId int, tx *sql.Tx) ([]int, error)
+	FindTableGroup(tableId int) (int, int, error)
+	SetTableMergedInto(tableId int, mergedInto int, tx *sql.Tx) error
+	MoveTableOrders(fromTableId int, toTableId int, merge bool, tx *sql.Tx) (int64, error)
+	ReturnMergedOrders(tableId int, originTableId int, tx *sql.Tx) (int64, error)
+	SetTableOrderSession(tableId int, sessionId int, tx *sql.Tx) error
+	FindOpenTableSession(tableId int, tx *sql.Tx) (int, error)
+	InsertTableSessionWithTx(tableId int, expiresAt time.Time, tx *sql.Tx) (int64, error)
+	CloseTableSessionsWithTx(tableId int, tx *sql.Tx) error
+	GetCheckoutOrders(tableId int, tx *sql.Tx) ([]model.CheckoutOrder, error)
+	FindCheckoutStart(tableId int, now string, tx *sql.Tx) (int, string, int, error)
+	InsertCheckout(c *model.Checkout, userId int, tx *sql.Tx) (int64, error)
+	ArchiveTableOrders(tableId int, checkoutId int, tx *sql.Tx) error
+	CompleteSeatedReservations(tableId int, tx *sql.Tx) error
+}
+
+// menuSortColumns maps the public sort keys to ORDER BY columns, only keys listed here reach the query.
+var menuSortColumns = map[string][]string{
+	"":         {"c.category_id IS NULL", "c.sort_order", "c.category_id"},
+	"category": {"c.category_id IS NULL", "c.sort_order", "c.category_id"},
+	"name":     {"mi.name"},
+	"price":    {"mi.price"},
+	"newest":   {"mi.created_at"},
+	"rating":   {"COALESCE(rt.average_rating, 0)"},
 }
+
+// menuSortDescending lists the sort keys that list the largest first when no order is given.
+var menuSortDescending = map[string]bool{"newest": true, "rating": true}
+
 type MySQLRestaurantRepository struct{}
 
-func (r *MySQLRestaurantRepository) GetAllMenu() ([]model.Menus, error) {
-	query := "SELECT menu_items_id, name, description, price, file_path, is_available FROM menu_items WHERE is_deleted = false"
-	rows, err := database.DB.Query(query)
+func (r *MySQLRestaurantRepository) GetAllMenu(f *request.MenuFilterRequest) ([]model.Menus, int, error) {
+	conditions := []string{"mi.is_deleted = FALSE"}
+	var args []any
+	if f.CategoryId > 0 {
+		conditions = append(conditions, "mi.category_id = ?")
+		args = append(args, f.CategoryId)
+	}
+	if f.IsAvailable != nil {
+		conditions = append(conditions, "mi.is_available = ?")
+		args = append(args, *f.IsAvailable)
+	}
+	if f.MinPrice > 0 {
+		conditions = append(conditions, "mi.price >= ?")
+		args = append(args, f.MinPrice)
+	}
+	if f.MaxPrice > 0 {
+		conditions = append(conditions, "mi.price <= ?")
+		args = append(args, f.MaxPrice)
+	}
+	if f.Name != "" {
+		conditions = append(conditions, "mi.name LIKE ?")
+		args = append(args, "%"+escapeLike(f.Name)+"%")
+	}
+	from := `
+		FROM menu_items mi
+		LEFT JOIN categories c ON mi.category_id = c.category_id AND c.is_deleted = FALSE
+		LEFT JOIN (
+			SELECT menu_item_id, AVG(rating) AS average_rating, COUNT(*) AS ratings
+			FROM reviews
+			WHERE menu_item_id IS NOT NULL AND is_deleted = FALSE AND is_hidden = FALSE
+			GROUP BY menu_item_id
+		) rt ON rt.menu_item_id = mi.menu_items_id
+		WHERE ` + strings.Join(conditions, " AND ")
+
+	var total int
+	err := database.DB.QueryRow("SELECT count(1)"+from, args...).Scan(&total)
+	if err != nil {
+		log.Printf("Error counting menus from database: %v", err)
+		return nil, 0, err
+	}
+
+	direction := " ASC"
+	if f.Order == "desc" || (f.Order == "" && menuSortDescending[f.Sort]) {
+		direction = " DESC"
+	}
+	orderBy := strings.Join(menuSortColumns[f.Sort], direction+", ") + direction
+	query := `
+		SELECT mi.menu_items_id, mi.name, COALESCE(mi.description, ''), mi.price, mi.file_path, mi.is_available,
+		       COALESCE(c.category_id, 0), COALESCE(c.name, ''),
+		       ROUND(COALESCE(rt.average_rating, 0), 2), COALESCE(rt.ratings, 0)` + from + `
+		ORDER BY ` + orderBy + `, mi.menu_items_id`
+	if f.PageSize > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, f.PageSize, (f.Page-1)*f.PageSize)
+	}
+	rows, err := database.DB.Query(query, args...)
 	if err != nil {
 		log.Printf("Error fetching menus from database: %v", err)
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
-	var menus []model.Menus
+	menus := []model.Menus{}
 	for rows.Next() {
 		var menu model.Menus
-		var filePath string
-		if err := rows.Scan(&menu.MenuItemsId, &menu.Name, &menu.Description, &menu.Price, &filePath, &menu.IsAvailable); err != nil {
+		var filePath sql.NullString
+		if err := rows.Scan(&menu.MenuItemsId, &menu.Name, &menu.Description, &menu.Price, &filePath, &menu.IsAvailable,
+			&menu.CategoryId, &menu.CategoryName, &menu.AverageRating, &menu.RatingCount); err != nil {
 			log.Printf("Error scanning menu: %v", err)
-			return nil, err
+			return nil, 0, err
 		}
-		filePath = strings.TrimPrefix(filePath, "K:\\IdeaProjects\\GoLand\\26Sep\\Restaurant\\assets\\images\\")
-		menu.FileObjects = []model.FileObject{
-			{FileName: filePath}, // Add the trimmed file path
+		menu.FileObjects = []model.FileObject{}
+		if fileName := imageFileName(filePath.String); fileName != "" {
+			menu.FileObjects = append(menu.FileObjects, model.FileObject{FileName: fileName})
 		}
 		menus = append(menus, menu)
 	}
 
-	return menus, nil
+	return menus, total, nil
 }
 
 func (r *MySQLRestaurantRepository) FindTableById(c *request.OrderRequest) (bool, error) {
@@ -73,6 +258,17 @@ func (r *MySQLRestaurantRepository) FindTableById(c *request.OrderRequest) (bool
 	return false, err
 }
 
+// FindTableNumberById returns the number printed on the table, retired tables included.
+func (r *MySQLRestaurantRepository) FindTableNumberById(tableId int) (int, error) {
+	query := "SELECT table_number FROM tables WHERE table_id = ?"
+	var tableNumber int
+	err := database.DB.QueryRow(query, tableId).Scan(&tableNumber)
+	if err != nil {
+		return 0, err
+	}
+	return tableNumber, nil
+}
+
 func (r *MySQLRestaurantRepository) FindTableByTableRequestId(c *request.TableRequest) (bool, string, error) {
 	query := "SELECT count(1), table_status FROM tables WHERE table_id = ? AND is_deleted = FALSE GROUP BY table_status "
 	var count int
@@ -88,27 +284,14 @@ func (r *MySQLRestaurantRepository) FindTableByTableRequestId(c *request.TableRe
 	return false, "", err
 }
 
-func (r *MySQLRestaurantRepository) UpdateTable(ro *request.TableRequest) error {
-	updateQuery := `
-		UPDATE tables
-		SET table_status = ?, updated_at = ?
-		WHERE table_id = ? AND is_deleted = FALSE
-	`
-	currentTime := config.FormatTime(time.Now())
-	_, err := database.DB.Exec(updateQuery, ro.TableStatus, currentTime, ro.TableId)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func (r *MySQLRestaurantRepository) FindMenuItemById(c []request.MenuItem) ([]int, error) {
-	query := "SELECT count(1) FROM menu_items WHERE menu_items_id = ?  AND is_deleted = FALSE AND is_available = TRUE;"
+// FindMenuItemById returns the ids of the items that are not on the menu or not available. The
+// items found are share locked, so they cannot be taken off the menu before the order is inserted.
+func (r *MySQLRestaurantRepository) FindMenuItemById(c []request.MenuItem, tx *sql.Tx) ([]int, error) {
+	query := "SELECT count(1) FROM menu_items WHERE menu_items_id = ?  AND is_deleted = FALSE AND is_available = TRUE LOCK IN SHARE MODE;"
 	var notFoundItems []int
 	for _, item := range c {
 		var count int
-		err := database.DB.QueryRow(query, item.MenuItemID).Scan(&count)
+		err := tx.QueryRow(query, item.MenuItemID).Scan(&count)
 		if err != nil {
 			log.Printf("Error checking menu item ID %d: %v", item.MenuItemID, err)
 			return nil, err
@@ -122,9 +305,9 @@ func (r *MySQLRestaurantRepository) FindMenuItemById(c []request.MenuItem) ([]in
 }
 
 func (r *MySQLRestaurantRepository) InsertOrder(c *request.OrderRequest, tx *sql.Tx) (int64, error) {
-	orderQuery := "INSERT INTO orders (table_id, created_at) VALUES (?, ?)"
+	orderQuery := "INSERT INTO orders (table_id, session_id, created_at) VALUES (?, ?, ?)"
 	currentTime := config.FormatTime(time.Now())
-	result, err := tx.Exec(orderQuery, c.TableId, currentTime)
+	result, err := tx.Exec(orderQuery, c.TableId, nullableId(c.SessionId), currentTime)
 	if err != nil {
 		return 0, err
 	}
@@ -160,7 +343,7 @@ func (r *MySQLRestaurantRepository) FindOrderById(ro *request.OrderRequest) (boo
 	return false, err
 }
 
-func (r *MySQLRestaurantRepository) UpdateOrder(tableId int, orderId int, status string) error {
+func (r *MySQLRestaurantRepository) UpdateOrderWithTx(tableId int, orderId int, status string, tx *sql.Tx) error {
 	var currentStatus string
 	currentTime := config.FormatTime(time.Now())
 	updateQuery := `
@@ -168,46 +351,15 @@ func (r *MySQLRestaurantRepository) UpdateOrder(tableId int, orderId int, status
 			SET status = ?, updated_at = ?
 			WHERE order_id = ? AND table_id = ? AND is_deleted = FALSE;
 		`
-	_, err := database.DB.Exec(updateQuery, status, currentTime, orderId, tableId)
+	result, err := tx.Exec(updateQuery, status, currentTime, orderId, tableId)
 	if err != nil {
 		return err
 	}
-	statusQuery := "SELECT status FROM orders WHERE order_id = ? AND table_id = ? AND is_deleted = FALSE"
-	err = database.DB.QueryRow(statusQuery, orderId, tableId).Scan(&currentStatus)
-	log.Println("currentStatus", currentStatus)
-	if err != nil {
-		return err
-	}
-
-	if currentStatus == "canceled" {
-		deleteQuery := `
-			UPDATE orders
-			SET status = ?, is_deleted = TRUE, updated_at = ?
-			WHERE order_id = ? AND table_id = ? AND is_deleted = FALSE;
-		`
-		_, err := database.DB.Exec(deleteQuery, status, currentTime, orderId, tableId)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-func (r *MySQLRestaurantRepository) UpdateOrderWithTx(tableId int, orderId int, status string, tx *sql.Tx) error {
-	var currentStatus string
-	currentTime := config.FormatTime(time.Now())
-	updateQuery := `
-			UPDATE orders
-			SET status = ?, updated_at = ?
-			WHERE order_id = ? AND table_id = ? AND is_deleted = FALSE;
-		`
-	_, err := tx.Exec(updateQuery, status, currentTime, orderId, tableId)
-	if err != nil {
+	if err := expectUpdated(result, orderId, tableId); err != nil {
 		return err
 	}
 	statusQuery := "SELECT status FROM orders WHERE order_id = ? AND table_id = ? AND is_deleted = FALSE"
 	err = tx.QueryRow(statusQuery, orderId, tableId).Scan(&currentStatus)
-	log.Println("currentStatus", currentStatus)
 	if err != nil {
 		return err
 	}
@@ -226,7 +378,7 @@ func (r *MySQLRestaurantRepository) UpdateOrderWithTx(tableId int, orderId int,
 	return nil
 }
 
-func (r *MySQLRestaurantRepository) DeleteOrder(ro *request.OrderRequest) error {
+func (r *MySQLRestaurantRepository) DeleteOrder(ro *request.OrderRequest, tx *sql.Tx) error {
 	if ro.Status == "canceled" {
 		currentTime := config.FormatTime(time.Now())
 		deleteQuery := `
@@ -234,85 +386,31 @@ func (r *MySQLRestaurantRepository) DeleteOrder(ro *request.OrderRequest) error
 			SET is_deleted = TRUE, updated_at = ?, status = ?
 			WHERE order_id = ? AND table_id = ? AND is_deleted = FALSE;
 		`
-		_, err := database.DB.Exec(deleteQuery, currentTime, ro.Status, ro.OrderId, ro.TableId)
+		result, err := tx.Exec(deleteQuery, currentTime, ro.Status, ro.OrderId, ro.TableId)
 		if err != nil {
 			return err
 		}
-		return nil
+		return expectUpdated(result, ro.OrderId, ro.TableId)
 	}
 	return fmt.Errorf("cannot delete order, status is not 'canceled'")
 }
 
-func (r *MySQLRestaurantRepository) DeleteAllOrderWhenCheckOut(ro *request.TableRequest) error {
-	currentTime := config.FormatTime(time.Now())
-	deleteQuery := `
-		UPDATE orders
-		SET is_deleted = TRUE, updated_at = ?
-		WHERE table_id = ? AND is_deleted = FALSE;
-	`
-	_, err := database.DB.Exec(deleteQuery, currentTime, ro.TableId)
+// expectUpdated fails when an order update changed no row, so a wrong table never passes as done.
+func expectUpdated(result sql.Result, orderId int, tableId int) error {
+	affected, err := result.RowsAffected()
 	if err != nil {
 		return err
 	}
-	return nil
-}
-
-func (r *MySQLRestaurantRepository) CheckOrderStatus(ro *request.OrderRequest, tx *sql.Tx) (string, error) {
-	checkStatusQuery := `
-		SELECT status FROM orders
-		WHERE order_id = ? AND is_deleted = FALSE
-	`
-
-	var status string
-	err := tx.QueryRow(checkStatusQuery, ro.OrderId).Scan(&status)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return "", fmt.Errorf("order not found or already deleted")
-		}
-		return "", err
-	}
-	return status, nil
-}
-
-func (r *MySQLRestaurantRepository) CheckOrderStatusWithOutTx(ro *request.OrderRequest) (string, error) {
-	checkStatusQuery := `
-		SELECT status FROM orders
-		WHERE order_id = ? AND is_deleted = FALSE
-	`
-
-	var status string
-	err := database.DB.QueryRow(checkStatusQuery, ro.OrderId).Scan(&status)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return "", fmt.Errorf("order not found or already deleted")
-		}
-		return "", err
-	}
-	return status, nil
-}
-
-func (r *MySQLRestaurantRepository) PayOrder(ro *request.OrderRequest, tx *sql.Tx) error {
-	payQuery := `
-		INSERT INTO bills (order_id, table_id, total_amount, bill_date)
-		SELECT o.order_id, o.table_id, SUM(oi.quantity * oi.price) AS total_amount, ?
-		FROM orders o
-		INNER JOIN order_items oi ON o.order_id = oi.order_id
-		WHERE o.order_id = ? AND o.is_deleted = FALSE
-		GROUP BY o.order_id, o.table_id
-	`
-	currentTime := config.FormatTime(time.Now())
-	_, err := tx.Exec(payQuery, currentTime, ro.OrderId)
-	if err != nil {
-		return fmt.Errorf("failed to create bill: %v", err)
+	if affected == 0 {
+		return fmt.Errorf("order %d on table %d was not updated", orderId, tableId)
 	}
-
 	return nil
 }
 
 func (r *MySQLRestaurantRepository) HasOrderBeenReviewed(ro *request.OrderRequest, tx *sql.Tx) (bool, error) {
 	reviewQuery := `
 		SELECT count(1) FROM reviews
-		WHERE order_id = ? AND is_deleted = FALSE
+		WHERE order_id = ? AND menu_item_id IS NULL AND is_deleted = FALSE
 	`
 	var count int
 	err := tx.QueryRow(reviewQuery, ro.OrderId).Scan(&count)
@@ -341,16 +439,73 @@ func (r *MySQLRestaurantRepository) ReviewOrder(ro *request.OrderRequest, tx *sq
 	return nil
 }
 
+// GetOrderMenuItemIds returns the dishes on the order that were not voided, each once.
+func (r *MySQLRestaurantRepository) GetOrderMenuItemIds(orderId int, tx *sql.Tx) ([]int, error) {
+	query := "SELECT DISTINCT menu_item_id FROM order_items WHERE order_id = ? AND item_status <> 'voided'"
+	return queryIds(tx, query, orderId)
+}
+
+// GetReviewedMenuItemIds returns the dishes rated on the order, including reviews the restaurant
+// deleted, so a removed review cannot be posted again.
+func (r *MySQLRestaurantRepository) GetReviewedMenuItemIds(orderId int, tx *sql.Tx) ([]int, error) {
+	query := "SELECT menu_item_id FROM reviews WHERE order_id = ? AND menu_item_id IS NOT NULL"
+	return queryIds(tx, query, orderId)
+}
+
+func (r *MySQLRestaurantRepository) ReviewOrderItems(orderId int, items []request.ItemReview, tx *sql.Tx) error {
+	reviewQuery := `
+		INSERT INTO reviews (order_id, menu_item_id, rating, comment, review_date)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	currentTime := config.FormatTime(time.Now())
+	for _, item := range items {
+		_, err := tx.Exec(reviewQuery, orderId, item.MenuItemId, item.Rating, item.Comment, currentTime)
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+			return ErrAlreadyReviewed
+		}
+		if err != nil {
+			return fmt.Errorf("failed to review menu item %d: %v", item.MenuItemId, err)
+		}
+	}
+	return nil
+}
+
+func queryIds(tx *sql.Tx, query string, args ...any) ([]int, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetOrderDetails returns the order on the table with its items, or nil when the table, or the
+// session when one is given, has no such order.
 func (r *MySQLRestaurantRepository) GetOrderDetails(ro *request.OrderRequest) (*model.Order, error) {
 	query := `
-		SELECT o.order_id, o.table_id, o.status, 
-		       oi.menu_item_id, mi.name, mi.description, oi.quantity, oi.price
+		SELECT o.order_id, o.table_id, o.status,
+		       oi.id, oi.menu_item_id, mi.name, COALESCE(mi.description, ''), oi.quantity, oi.price, oi.item_status
 		FROM orders o
 		INNER JOIN order_items oi ON o.order_id = oi.order_id
 		INNER JOIN menu_items mi ON oi.menu_item_id = mi.menu_items_id
-		WHERE o.order_id = ? AND o.is_deleted = FALSE
+		WHERE o.order_id = ? AND o.table_id = ? AND o.is_deleted = FALSE
 	`
-	rows, err := database.DB.Query(query, ro.OrderId)
+	args := []any{ro.OrderId, ro.TableId}
+	if ro.SessionId > 0 {
+		query += " AND o.session_id = ?"
+		args = append(args, ro.SessionId)
+	}
+	rows, err := database.DB.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -360,8 +515,8 @@ func (r *MySQLRestaurantRepository) GetOrderDetails(ro *request.OrderRequest) (*
 	orderMap := make(map[int]*model.Order) // For tracking unique orders
 	for rows.Next() {
 		var orderItem model.OrderItems
-		if err := rows.Scan(&order.OrderId, &order.TableId, &order.Status, &orderItem.MenuItemId, &orderItem.Name,
-			&orderItem.Description, &orderItem.Quantity, &orderItem.Price); err != nil {
+		if err := rows.Scan(&order.OrderId, &order.TableId, &order.Status, &orderItem.OrderItemId, &orderItem.MenuItemId, &orderItem.Name,
+			&orderItem.Description, &orderItem.Quantity, &orderItem.Price, &orderItem.ItemStatus); err != nil {
 			return nil, err
 		}
 
@@ -383,9 +538,15 @@ func (r *MySQLRestaurantRepository) GetOrderHistory(ro *request.OrderRequest) ([
 		SELECT o.order_id, o.table_id, o.status, created_at
 		FROM orders o
 		WHERE o.table_id = ?
-  		AND o.is_deleted = FALSE
+  		AND o.is_deleted = FALSE AND o.checkout_id IS NULL
 	`
-	rows, err := database.DB.Query(query, ro.TableId)
+	args := []any{ro.TableId}
+	// Customers only see the orders placed during their own table session
+	if ro.SessionId > 0 {
+		query += " AND o.session_id = ?"
+		args = append(args, ro.SessionId)
+	}
+	rows, err := database.DB.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -402,3 +563,2293 @@ func (r *MySQLRestaurantRepository) GetOrderHistory(ro *request.OrderRequest) ([
 
 	return orders, nil
 }
+
+func (r *MySQLRestaurantRepository) FindMenuItemByMenuRequestId(mr *request.MenuRequest) (bool, error) {
+	query := "SELECT count(1) FROM menu_items WHERE menu_items_id = ? AND is_deleted = FALSE"
+	var count int
+	err := database.DB.QueryRow(query, mr.MenuItemsId).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *MySQLRestaurantRepository) InsertMenuItem(mr *request.MenuRequest) (int64, error) {
+	insertQuery := `
+		INSERT INTO menu_items (name, description, price, is_available, category_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	isAvailable := true
+	if mr.IsAvailable != nil {
+		isAvailable = *mr.IsAvailable
+	}
+	currentTime := config.FormatTime(time.Now())
+	result, err := database.DB.Exec(insertQuery, mr.Name, mr.Description, mr.Price, isAvailable, nullableId(mr.CategoryId), currentTime)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create menu item: %v", err)
+	}
+	menuItemId, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return menuItemId, nil
+}
+
+func (r *MySQLRestaurantRepository) UpdateMenuItem(mr *request.MenuRequest) error {
+	updateQuery := `
+		UPDATE menu_items
+		SET name = ?, description = ?, price = ?, category_id = ?, updated_at = ?
+		WHERE menu_items_id = ? AND is_deleted = FALSE
+	`
+	currentTime := config.FormatTime(time.Now())
+	_, err := database.DB.Exec(updateQuery, mr.Name, mr.Description, mr.Price, nullableId(mr.CategoryId), currentTime, mr.MenuItemsId)
+	if err != nil {
+		return fmt.Errorf("failed to update menu item: %v", err)
+	}
+	return nil
+}
+
+func (r *MySQLRestaurantRepository) UpdateMenuItemAvailability(mr *request.MenuRequest) error {
+	updateQuery := `
+		UPDATE menu_items
+		SET is_available = ?, updated_at = ?
+		WHERE menu_items_id = ? AND is_deleted = FALSE
+	`
+	currentTime := config.FormatTime(time.Now())
+	_, err := database.DB.Exec(updateQuery, *mr.IsAvailable, currentTime, mr.MenuItemsId)
+	if err != nil {
+		return fmt.Errorf("failed to update menu item availability: %v", err)
+	}
+	return nil
+}
+
+func (r *MySQLRestaurantRepository) DeleteMenuItem(mr *request.MenuRequest) error {
+	deleteQuery := `
+		UPDATE menu_items
+		SET is_deleted = TRUE, is_available = FALSE, updated_at = ?
+		WHERE menu_items_id = ? AND is_deleted = FALSE
+	`
+	currentTime := config.FormatTime(time.Now())
+	_, err := database.DB.Exec(deleteQuery, currentTime, mr.MenuItemsId)
+	if err != nil {
+		return fmt.Errorf("failed to delete menu item: %v", err)
+	}
+	return nil
+}
+
+func (r *MySQLRestaurantRepository) GetMenuItemImage(menuItemsId int) (string, error) {
+	query := "SELECT file_path FROM menu_items WHERE menu_items_id = ? AND is_deleted = FALSE"
+	var filePath sql.NullString
+	err := database.DB.QueryRow(query, menuItemsId).Scan(&filePath)
+	if err != nil {
+		return "", err
+	}
+	return imageFileName(filePath.String), nil
+}
+
+func (r *MySQLRestaurantRepository) UpdateMenuItemImage(menuItemsId int, fileName string) error {
+	updateQuery := `
+		UPDATE menu_items
+		SET file_path = ?, updated_at = ?
+		WHERE menu_items_id = ? AND is_deleted = FALSE
+	`
+	currentTime := config.FormatTime(time.Now())
+	_, err := database.DB.Exec(updateQuery, fileName, currentTime, menuItemsId)
+	if err != nil {
+		return fmt.Errorf("failed to update menu item image: %v", err)
+	}
+	return nil
+}
+
+// imageFileName strips any directory from file_path. Older rows stored an absolute
+// Windows path, newer rows store the bare file name inside the image storage.
+func imageFileName(filePath string) string {
+	if i := strings.LastIndexAny(filePath, "/\\"); i >= 0 {
+		return filePath[i+1:]
+	}
+	return filePath
+}
+
+func (r *MySQLRestaurantRepository) GetAllCategories() ([]model.Category, error) {
+	query := "SELECT category_id, name, sort_order FROM categories WHERE is_deleted = FALSE ORDER BY sort_order, category_id"
+	rows, err := database.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []model.Category{}
+	for rows.Next() {
+		var category model.Category
+		if err := rows.Scan(&category.CategoryId, &category.Name, &category.SortOrder); err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, nil
+}
+
+func (r *MySQLRestaurantRepository) FindCategoryById(categoryId int) (bool, error) {
+	query := "SELECT count(1) FROM categories WHERE category_id = ? AND is_deleted = FALSE"
+	var count int
+	err := database.DB.QueryRow(query, categoryId).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *MySQLRestaurantRepository) InsertCategory(cr *request.CategoryRequest) (int64, error) {
+	insertQuery := "INSERT INTO categories (name, sort_order, created_at) VALUES (?, ?, ?)"
+	currentTime := config.FormatTime(time.Now())
+	result, err := database.DB.Exec(insertQuery, cr.Name, cr.SortOrder, currentTime)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create category: %v", err)
+	}
+	return result.LastInsertId()
+}
+
+func (r *MySQLRestaurantRepository) UpdateCategory(cr *request.CategoryRequest) error {
+	updateQuery := `
+		UPDATE categories
+		SET name = ?, sort_order = ?, updated_at = ?
+		WHERE category_id = ? AND is_deleted = FALSE
+	`
+	currentTime := config.FormatTime(time.Now())
+	_, err := database.DB.Exec(updateQuery, cr.Name, cr.SortOrder, currentTime, cr.CategoryId)
+	if err != nil {
+		return fmt.Errorf("failed to update category: %v", err)
+	}
+	return nil
+}
+
+func (r *MySQLRestaurantRepository) DeleteCategory(cr *request.CategoryRequest) error {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return err
+	}
+	currentTime := config.FormatTime(time.Now())
+	_, err = tx.Exec("UPDATE menu_items SET category_id = NULL, updated_at = ? WHERE category_id = ?", currentTime, cr.CategoryId)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to detach menu items from category: %v", err)
+	}
+	_, err = tx.Exec("UPDATE categories SET is_deleted = TRUE, updated_at = ? WHERE category_id = ? AND is_deleted = FALSE", currentTime, cr.CategoryId)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to delete category: %v", err)
+	}
+	return tx.Commit()
+}
+
+// nullableId stores 0 as NULL for optional foreign keys.
+func nullableId(id int) any {
+	if id <= 0 {
+		return nil
+	}
+	return id
+}
+
+func nullableString(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(s)
+}
+
+const tableSelectQuery = `
+	SELECT t.table_id, t.table_number, t.table_status, t.capacity, t.zone, t.pos_x, t.pos_y, COALESCE(t.merged_into, 0),
+	       (SELECT count(1) FROM orders o
+	        WHERE o.table_id = t.table_id AND o.is_deleted = FALSE AND o.checkout_id IS NULL
+	          AND o.status IN ('created', 'prepare', 'completed')) AS open_orders
+	FROM tables t
+`
+
+func scanTable(row interface{ Scan(...any) error }, extra ...any) (model.Table, error) {
+	var table model.Table
+	dest := append([]any{&table.TableId, &table.TableNumber, &table.TableStatus, &table.Capacity, &table.Zone,
+		&table.X, &table.Y, &table.MergedInto, &table.OpenOrders}, extra...)
+	err := row.Scan(dest...)
+	return table, err
+}
+
+func (r *MySQLRestaurantRepository) GetAllTables() ([]model.Table, error) {
+	query := tableSelectQuery + " WHERE t.is_deleted = FALSE ORDER BY t.table_number"
+	rows, err := database.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := []model.Table{}
+	for rows.Next() {
+		table, err := scanTable(rows)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+func (r *MySQLRestaurantRepository) FindTableByNumber(tableNumber int) (*model.Table, error) {
+	query := tableSelectQuery + " WHERE t.table_number = ? AND t.is_deleted = FALSE"
+	table, err := scanTable(database.DB.QueryRow(query, tableNumber))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &table, nil
+}
+
+func (r *MySQLRestaurantRepository) FindRetiredTableByNumber(tableNumber int) (int, error) {
+	query := "SELECT table_id FROM tables WHERE table_number = ? AND is_deleted = TRUE"
+	var tableId int
+	err := database.DB.QueryRow(query, tableNumber).Scan(&tableId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return tableId, nil
+}
+
+func (r *MySQLRestaurantRepository) InsertTable(tr *request.TableRequest) (int64, error) {
+	insertQuery := `
+		INSERT INTO tables (table_number, table_status, capacity, zone, pos_x, pos_y, created_at)
+		VALUES (?, 'available', ?, ?, ?, ?, ?)
+	`
+	currentTime := config.FormatTime(time.Now())
+	result, err := database.DB.Exec(insertQuery, tr.TableNumber, tr.Capacity, tr.Zone, tr.X, tr.Y, currentTime)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create table: %v", err)
+	}
+	return result.LastInsertId()
+}
+
+func (r *MySQLRestaurantRepository) RestoreTable(tableId int) error {
+	updateQuery := `
+		UPDATE tables
+		SET is_deleted = FALSE, table_status = 'available', updated_at = ?
+		WHERE table_id = ? AND is_deleted = TRUE
+	`
+	currentTime := config.FormatTime(time.Now())
+	_, err := database.DB.Exec(updateQuery, currentTime, tableId)
+	if err != nil {
+		return fmt.Errorf("failed to restore table: %v", err)
+	}
+	return nil
+}
+
+func (r *MySQLRestaurantRepository) UpdateTableLayout(tr *request.TableRequest) error {
+	updateQuery := `
+		UPDATE tables
+		SET capacity = ?, zone = ?, pos_x = ?, pos_y = ?, updated_at = ?
+		WHERE table_id = ? AND is_deleted = FALSE
+	`
+	currentTime := config.FormatTime(time.Now())
+	_, err := database.DB.Exec(updateQuery, tr.Capacity, tr.Zone, tr.X, tr.Y, currentTime, tr.TableId)
+	if err != nil {
+		return fmt.Errorf("failed to update table layout: %v", err)
+	}
+	return nil
+}
+
+func (r *MySQLRestaurantRepository) UpdateTableNumber(tr *request.TableRequest) error {
+	updateQuery := `
+		UPDATE tables
+		SET table_number = ?, updated_at = ?
+		WHERE table_id = ? AND is_deleted = FALSE
+	`
+	currentTime := config.FormatTime(time.Now())
+	_, err := database.DB.Exec(updateQuery, tr.TableNumber, currentTime, tr.TableId)
+	if err != nil {
+		return fmt.Errorf("failed to renumber table: %v", err)
+	}
+	return nil
+}
+
+func (r *MySQLRestaurantRepository) DeleteTable(tr *request.TableRequest) error {
+	deleteQuery := `
+		UPDATE tables
+		SET is_deleted = TRUE, table_status = 'available', updated_at = ?
+		WHERE table_id = ? AND is_deleted = FALSE
+	`
+	currentTime := config.FormatTime(time.Now())
+	_, err := database.DB.Exec(deleteQuery, currentTime, tr.TableId)
+	if err != nil {
+		return fmt.Errorf("failed to retire table: %v", err)
+	}
+	return nil
+}
+
+func (r *MySQLRestaurantRepository) CountOpenOrdersByTable(tableId int) (int, error) {
+	query := `
+		SELECT count(1) FROM orders
+		WHERE table_id = ? AND is_deleted = FALSE AND checkout_id IS NULL AND status IN ('created', 'prepare', 'completed')
+	`
+	var count int
+	err := database.DB.QueryRow(query, tableId).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// CountLiveOrdersByTable counts the orders of the party at the table, whatever their status, that
+// were not checked out yet.
+func (r *MySQLRestaurantRepository) CountLiveOrdersByTable(tableId int) (int, error) {
+	query := "SELECT count(1) FROM orders WHERE table_id = ? AND is_deleted = FALSE AND checkout_id IS NULL"
+	var count int
+	err := database.DB.QueryRow(query, tableId).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *MySQLRestaurantRepository) IsTableSessionActive(sessionId int, tableId int) (bool, error) {
+	query := `
+		SELECT count(1) FROM table_sessions
+		WHERE session_id = ? AND table_id = ? AND closed_at IS NULL AND expires_at > ?
+	`
+	var count int
+	err := database.DB.QueryRow(query, sessionId, tableId, config.FormatTime(time.Now())).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+const openTableSessionQuery = `
+	SELECT COALESCE(MAX(session_id), 0) FROM table_sessions
+	WHERE table_id = ? AND closed_at IS NULL AND expires_at > ?`
+
+// FindActiveTableSession returns the open session of the table, or 0 when it has none.
+func (r *MySQLRestaurantRepository) FindActiveTableSession(tableId int) (int, error) {
+	var sessionId int
+	err := database.DB.QueryRow(openTableSessionQuery, tableId, config.FormatTime(time.Now())).Scan(&sessionId)
+	if err != nil {
+		return 0, err
+	}
+	return sessionId, nil
+}
+
+func (r *MySQLRestaurantRepository) GetKitchenQueue() ([]model.KitchenOrder, error) {
+	query := `
+		SELECT o.order_id, o.table_id, t.table_number, o.status, o.created_at,
+		       oi.id, oi.menu_item_id, mi.name, COALESCE(mi.description, ''), oi.quantity, oi.price, oi.item_status
+		FROM orders o
+		INNER JOIN tables t ON o.table_id = t.table_id
+		INNER JOIN order_items oi ON o.order_id = oi.order_id
+		INNER JOIN menu_items mi ON oi.menu_item_id = mi.menu_items_id
+		WHERE o.is_deleted = FALSE AND o.checkout_id IS NULL AND o.status IN ('created', 'prepare')
+		ORDER BY o.created_at, o.order_id, oi.id
+	`
+	rows, err := database.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	queue := []model.KitchenOrder{}
+	for rows.Next() {
+		var order model.KitchenOrder
+		var createdAt time.Time
+		var orderItem model.OrderItems
+		if err := rows.Scan(&order.OrderId, &order.TableId, &order.TableNumber, &order.Status, &createdAt,
+			&orderItem.OrderItemId, &orderItem.MenuItemId, &orderItem.Name, &orderItem.Description, &orderItem.Quantity,
+			&orderItem.Price, &orderItem.ItemStatus); err != nil {
+			return nil, err
+		}
+		// Rows are ordered by order, so items of the same order are adjacent
+		if len(queue) == 0 || queue[len(queue)-1].OrderId != order.OrderId {
+			order.CreatedAt = config.FormatTime(createdAt)
+			order.AgeMinutes = int(now.Sub(createdAt).Minutes())
+			queue = append(queue, order)
+		}
+		last := &queue[len(queue)-1]
+		last.OrderItems = append(last.OrderItems, orderItem)
+	}
+	return queue, nil
+}
+
+func (r *MySQLRestaurantRepository) FindOrderItemById(orderItemId int, tx *sql.Tx) (*model.OrderItemState, error) {
+	// Lock the item and its order so concurrent item updates derive the order status in turn
+	query := `
+		SELECT oi.id, o.order_id, o.table_id, oi.item_status, o.status
+		FROM order_items oi
+		INNER JOIN orders o ON oi.order_id = o.order_id
+		WHERE oi.id = ? AND o.is_deleted = FALSE
+		FOR UPDATE
+	`
+	var state model.OrderItemState
+	err := tx.QueryRow(query, orderItemId).Scan(&state.OrderItemId, &state.OrderId, &state.TableId, &state.ItemStatus, &state.OrderStatus)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (r *MySQLRestaurantRepository) UpdateOrderItemStatus(orderItemId int, status string, tx *sql.Tx) error {
+	updateQuery := "UPDATE order_items SET item_status = ?, updated_at = ? WHERE id = ?"
+	currentTime := config.FormatTime(time.Now())
+	_, err := tx.Exec(updateQuery, status, currentTime, orderItemId)
+	if err != nil {
+		return fmt.Errorf("failed to update order item: %v", err)
+	}
+	return nil
+}
+
+func (r *MySQLRestaurantRepository) GetOrderItemStatuses(orderId int, tx *sql.Tx) ([]string, error) {
+	rows, err := tx.Query("SELECT item_status FROM order_items WHERE order_id = ?", orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var statuses []string
+	for rows.Next() {
+		var status string
+		if err := rows.Scan(&status); err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// LockOrder locks the order row for the rest of the transaction and returns its
+// status and table session, or an empty status when the order does not exist.
+func (r *MySQLRestaurantRepository) LockOrder(orderId int, tableId int, tx *sql.Tx) (string, int, error) {
+	query := `
+		SELECT status, COALESCE(session_id, 0) FROM orders
+		WHERE order_id = ? AND table_id = ? AND is_deleted = FALSE
+		FOR UPDATE
+	`
+	var status string
+	var sessionId int
+	err := tx.QueryRow(query, orderId, tableId).Scan(&status, &sessionId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", 0, nil
+		}
+		return "", 0, err
+	}
+	return status, sessionId, nil
+}
+
+func (r *MySQLRestaurantRepository) UpdateOrderItemQuantity(orderId int, orderItemId int, quantity int, tx *sql.Tx) (bool, error) {
+	updateQuery := `
+		UPDATE order_items
+		SET quantity = ?, updated_at = ?
+		WHERE id = ? AND order_id = ? AND item_status = 'queued'
+	`
+	currentTime := config.FormatTime(time.Now())
+	result, err := tx.Exec(updateQuery, quantity, currentTime, orderItemId, orderId)
+	if err != nil {
+		return false, fmt.Errorf("failed to update order item quantity: %v", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *MySQLRestaurantRepository) VoidOrderItem(orderId int, orderItemId int, tx *sql.Tx) (bool, error) {
+	updateQuery := `
+		UPDATE order_items
+		SET item_status = 'voided', updated_at = ?
+		WHERE id = ? AND order_id = ? AND item_status = 'queued'
+	`
+	currentTime := config.FormatTime(time.Now())
+	result, err := tx.Exec(updateQuery, currentTime, orderItemId, orderId)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove order item: %v", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// FindBillByOrderId locks the bill of the order for the rest of the transaction, or returns nil when none was opened yet.
+func (r *MySQLRestaurantRepository) FindBillByOrderId(orderId int, tx *sql.Tx) (*model.Bill, error) {
+	query := `
+		SELECT id, order_id, table_id, subtotal, discount, service_charge_rate, service_charge, vat_rate, vat_inclusive,
+			vat_amount, rounding, total_amount, paid_amount, refunded_amount, status, bill_date
+		FROM bills
+		WHERE order_id = ?
+		FOR UPDATE
+	`
+	var bill model.Bill
+	err := tx.QueryRow(query, orderId).Scan(&bill.BillId, &bill.OrderId, &bill.TableId, &bill.Subtotal, &bill.Discount,
+		&bill.ServiceChargeRate, &bill.ServiceCharge, &bill.VatRate, &bill.VatInclusive, &bill.VatAmount,
+		&bill.Rounding, &bill.TotalAmount, &bill.PaidAmount, &bill.RefundedAmount, &bill.Status, &bill.BillDate)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &bill, nil
+}
+
+func (r *MySQLRestaurantRepository) InsertBill(bill *model.Bill, tx *sql.Tx) (int64, error) {
+	insertQuery := `
+		INSERT INTO bills (order_id, table_id, subtotal, discount, service_charge_rate, service_charge, vat_rate,
+			vat_inclusive, vat_amount, rounding, total_amount, bill_date)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	currentTime := config.FormatTime(time.Now())
+	result, err := tx.Exec(insertQuery, bill.OrderId, bill.TableId, bill.Subtotal, bill.Discount, bill.ServiceChargeRate,
+		bill.ServiceCharge, bill.VatRate, bill.VatInclusive, bill.VatAmount, bill.Rounding, bill.TotalAmount, currentTime)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create bill: %v", err)
+	}
+	return result.LastInsertId()
+}
+
+func (r *MySQLRestaurantRepository) GetBillPayments(billId int, tx *sql.Tx) ([]model.Payment, error) {
+	query := `
+		SELECT payment_id, bill_id, label, split_mode, amount, status, paid_at
+		FROM payments
+		WHERE bill_id = ? AND status <> 'canceled'
+		ORDER BY payment_id
+	`
+	rows, err := tx.Query(query, billId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []model.Payment
+	index := make(map[int]int)
+	for rows.Next() {
+		var payment model.Payment
+		var paidAt sql.NullString
+		if err := rows.Scan(&payment.PaymentId, &payment.BillId, &payment.Label, &payment.SplitMode,
+			&payment.Amount, &payment.Status, &paidAt); err != nil {
+			return nil, err
+		}
+		if paidAt.Valid {
+			payment.PaidAt = &paidAt.String
+		}
+		index[payment.PaymentId] = len(payments)
+		payments = append(payments, payment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	itemQuery := `
+		SELECT pi.payment_id, pi.order_item_id
+		FROM payment_items pi
+		INNER JOIN payments p ON pi.payment_id = p.payment_id
+		WHERE p.bill_id = ? AND p.status <> 'canceled'
+		ORDER BY pi.order_item_id
+	`
+	itemRows, err := tx.Query(itemQuery, billId)
+	if err != nil {
+		return nil, err
+	}
+	defer itemRows.Close()
+	for itemRows.Next() {
+		var paymentId, orderItemId int
+		if err := itemRows.Scan(&paymentId, &orderItemId); err != nil {
+			return nil, err
+		}
+		if i, ok := index[paymentId]; ok {
+			payments[i].OrderItemIds = append(payments[i].OrderItemIds, orderItemId)
+		}
+	}
+	if err := itemRows.Err(); err != nil {
+		return nil, err
+	}
+
+	tenderQuery := `
+		SELECT t.tender_id, t.payment_id, t.method, t.amount, t.tendered, t.change_amount,
+			COALESCE(t.reference, ''), t.provider, COALESCE(t.refund_of, 0), t.status
+		FROM payment_tenders t
+		INNER JOIN payments p ON t.payment_id = p.payment_id
+		WHERE p.bill_id = ? AND p.status <> 'canceled'
+		ORDER BY t.tender_id
+	`
+	tenderRows, err := tx.Query(tenderQuery, billId)
+	if err != nil {
+		return nil, err
+	}
+	defer tenderRows.Close()
+	for tenderRows.Next() {
+		var tender model.Tender
+		if err := tenderRows.Scan(&tender.TenderId, &tender.PaymentId, &tender.Method, &tender.Amount,
+			&tender.Tendered, &tender.Change, &tender.Reference, &tender.Provider, &tender.RefundOf, &tender.Status); err != nil {
+			return nil, err
+		}
+		if i, ok := index[tender.PaymentId]; ok {
+			payments[i].Tenders = append(payments[i].Tenders, tender)
+		}
+	}
+	return payments, tenderRows.Err()
+}
+
+// GetUnpaidBillItems returns the order items not yet covered by a paid item split, with their line amount.
+func (r *MySQLRestaurantRepository) GetUnpaidBillItems(orderId int, tx *sql.Tx) ([]model.BillItem, error) {
+	query := `
+		SELECT oi.id, oi.menu_item_id, oi.quantity, oi.price, oi.quantity * oi.price
+		FROM order_items oi
+		WHERE oi.order_id = ? AND oi.item_status <> 'voided'
+		AND NOT EXISTS (
+			SELECT 1 FROM payment_items pi
+			INNER JOIN payments p ON pi.payment_id = p.payment_id
+			WHERE pi.order_item_id = oi.id AND p.status = 'paid'
+		)
+		ORDER BY oi.id
+	`
+	rows, err := tx.Query(query, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.BillItem
+	for rows.Next() {
+		var item model.BillItem
+		if err := rows.Scan(&item.OrderItemId, &item.MenuItemId, &item.Quantity, &item.Price, &item.Amount); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (r *MySQLRestaurantRepository) CancelPendingPayments(billId int, tx *sql.Tx) error {
+	updateQuery := `
+		UPDATE payments
+		SET status = 'canceled'
+		WHERE bill_id = ? AND status = 'pending'
+	`
+	_, err := tx.Exec(updateQuery, billId)
+	if err != nil {
+		return fmt.Errorf("failed to cancel pending payments: %v", err)
+	}
+	return nil
+}
+
+func (r *MySQLRestaurantRepository) InsertPayment(p *model.Payment, tx *sql.Tx) (int64, error) {
+	insertQuery := `
+		INSERT INTO payments (bill_id, label, split_mode, amount, status, created_at)
+		VALUES (?, ?, ?, ?, 'pending', ?)
+	`
+	currentTime := config.FormatTime(time.Now())
+	result, err := tx.Exec(insertQuery, p.BillId, p.Label, p.SplitMode, p.Amount, currentTime)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create payment: %v", err)
+	}
+	paymentId, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	itemQuery := "INSERT INTO payment_items (payment_id, order_item_id) VALUES (?, ?)"
+	for _, orderItemId := range p.OrderItemIds {
+		_, err = tx.Exec(itemQuery, paymentId, orderItemId)
+		if err != nil {
+			return 0, fmt.Errorf("failed to link order item %d to payment: %v", orderItemId, err)
+		}
+	}
+	return paymentId, nil
+}
+
+// FindPaymentById locks the payment for the rest of the transaction, or returns nil when it does not exist.
+func (r *MySQLRestaurantRepository) FindPaymentById(paymentId int, tx *sql.Tx) (*model.Payment, error) {
+	query := `
+		SELECT payment_id, bill_id, label, split_mode, amount, status
+		FROM payments
+		WHERE payment_id = ?
+		FOR UPDATE
+	`
+	var payment model.Payment
+	err := tx.QueryRow(query, paymentId).Scan(&payment.PaymentId, &payment.BillId, &payment.Label,
+		&payment.SplitMode, &payment.Amount, &payment.Status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *MySQLRestaurantRepository) SettlePayment(paymentId int, tx *sql.Tx) error {
+	updateQuery := `
+		UPDATE payments
+		SET status = 'paid', paid_at = ?
+		WHERE payment_id = ? AND status = 'pending'
+	`
+	currentTime := config.FormatTime(time.Now())
+	_, err := tx.Exec(updateQuery, currentTime, paymentId)
+	if err != nil {
+		return fmt.Errorf("failed to settle payment: %v", err)
+	}
+	return nil
+}
+
+// AddBillPaidAmount adds a settled payment to the bill and closes the bill once it is fully paid.
+// MySQL applies the assignments left to right, so status and settled_at see the new paid_amount.
+func (r *MySQLRestaurantRepository) AddBillPaidAmount(billId int, amount float64, tx *sql.Tx) error {
+	updateQuery := `
+		UPDATE bills
+		SET paid_amount = paid_amount + ?,
+			status = IF(paid_amount >= total_amount, 'settled', 'open'),
+			settled_at = IF(paid_amount >= total_amount, ?, NULL)
+		WHERE id = ?
+	`
+	currentTime := config.FormatTime(time.Now())
+	_, err := tx.Exec(updateQuery, amount, currentTime, billId)
+	if err != nil {
+		return fmt.Errorf("failed to update bill paid amount: %v", err)
+	}
+	return nil
+}
+
+// InsertTenders records the tenders of a payment, tenders without a status are settled.
+func (r *MySQLRestaurantRepository) InsertTenders(paymentId int, tenders []model.Tender, tx *sql.Tx) error {
+	insertQuery := `
+		INSERT INTO payment_tenders (payment_id, method, amount, tendered, change_amount, reference, provider, refund_of, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	currentTime := config.FormatTime(time.Now())
+	for _, tender := range tenders {
+		var reference any
+		if tender.Reference != "" {
+			reference = tender.Reference
+		}
+		status := tender.Status
+		if status == "" {
+			status = enums.TenderSettled
+		}
+		_, err := tx.Exec(insertQuery, paymentId, tender.Method, tender.Amount, tender.Tendered, tender.Change,
+			reference, tender.Provider, nullableId(tender.RefundOf), status, currentTime)
+		if err != nil {
+			return fmt.Errorf("failed to record %s tender: %v", tender.Method, err)
+		}
+	}
+	return nil
+}
+
+// UpdateRefundTender records what the provider did with a pending refund tender, found by the
+// charge it returns. A settled tender keeps the provider's refund reference.
+func (r *MySQLRestaurantRepository) UpdateRefundTender(paymentId int, tender model.Tender, tx *sql.Tx) error {
+	updateQuery := `
+		UPDATE payment_tenders
+		SET status = ?, reference = COALESCE(?, reference)
+		WHERE payment_id = ? AND refund_of = ? AND status = 'pending'
+	`
+	_, err := tx.Exec(updateQuery, tender.Status, nullableString(tender.Reference), paymentId, tender.RefundOf)
+	if err != nil {
+		return fmt.Errorf("failed to update %s refund tender: %v", tender.Method, err)
+	}
+	return nil
+}
+
+func (r *MySQLRestaurantRepository) CancelPayment(paymentId int, tx *sql.Tx) error {
+	updateQuery := `
+		UPDATE payments
+		SET status = 'canceled'
+		WHERE payment_id = ? AND status = 'pending'
+	`
+	_, err := tx.Exec(updateQuery, paymentId)
+	if err != nil {
+		return fmt.Errorf("failed to cancel payment: %v", err)
+	}
+	return nil
+}
+
+func (r *MySQLRestaurantRepository) UpdateBillAmounts(bill *model.Bill, tx *sql.Tx) error {
+	updateQuery := `
+		UPDATE bills
+		SET subtotal = ?, discount = ?, service_charge_rate = ?, service_charge = ?, vat_rate = ?,
+			vat_inclusive = ?, vat_amount = ?, rounding = ?, total_amount = ?
+		WHERE id = ? AND status = 'open'
+	`
+	_, err := tx.Exec(updateQuery, bill.Subtotal, bill.Discount, bill.ServiceChargeRate, bill.ServiceCharge,
+		bill.VatRate, bill.VatInclusive, bill.VatAmount, bill.Rounding, bill.TotalAmount, bill.BillId)
+	if err != nil {
+		return fmt.Errorf("failed to update bill amounts: %v", err)
+	}
+	return nil
+}
+
+const promotionColumns = `
+	promotion_id, name, rule_type, value, COALESCE(menu_item_id, 0), min_subtotal, starts_at, ends_at,
+	COALESCE(TIME_FORMAT(happy_hour_start, '%H:%i'), ''), COALESCE(TIME_FORMAT(happy_hour_end, '%H:%i'), ''),
+	COALESCE(coupon_code, ''), usage_limit, used_count, is_active
+`
+
+func scanPromotion(rows *sql.Rows) (model.Promotion, error) {
+	var promotion model.Promotion
+	var startsAt, endsAt sql.NullTime
+	err := rows.Scan(&promotion.PromotionId, &promotion.Name, &promotion.RuleType, &promotion.Value,
+		&promotion.MenuItemId, &promotion.MinSubtotal, &startsAt, &endsAt, &promotion.HappyHourStart,
+		&promotion.HappyHourEnd, &promotion.CouponCode, &promotion.UsageLimit, &promotion.UsedCount, &promotion.IsActive)
+	if startsAt.Valid {
+		promotion.StartsAt = config.FormatTime(startsAt.Time)
+	}
+	if endsAt.Valid {
+		promotion.EndsAt = config.FormatTime(endsAt.Time)
+	}
+	return promotion, err
+}
+
+func (r *MySQLRestaurantRepository) GetAllPromotions() ([]model.Promotion, error) {
+	query := "SELECT " + promotionColumns + " FROM promotions WHERE is_deleted = FALSE ORDER BY promotion_id"
+	rows, err := database.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	promotions := []model.Promotion{}
+	for rows.Next() {
+		promotion, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, promotion)
+	}
+	return promotions, rows.Err()
+}
+
+func (r *MySQLRestaurantRepository) FindPromotionById(promotionId int) (bool, error) {
+	query := "SELECT count(1) FROM promotions WHERE promotion_id = ? AND is_deleted = FALSE"
+	var count int
+	err := database.DB.QueryRow(query, promotionId).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// IsCouponCodeTaken reports whether another live promotion already uses the coupon code.
+func (r *MySQLRestaurantRepository) IsCouponCodeTaken(couponCode string, promotionId int) (bool, error) {
+	query := "SELECT count(1) FROM promotions WHERE coupon_code = ? AND promotion_id <> ? AND is_deleted = FALSE"
+	var count int
+	err := database.DB.QueryRow(query, couponCode, promotionId).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func promotionArgs(pr *request.PromotionRequest) []any {
+	isActive := true
+	if pr.IsActive != nil {
+		isActive = *pr.IsActive
+	}
+	return []any{pr.Name, pr.RuleType, pr.Value, nullableId(pr.MenuItemId), pr.MinSubtotal,
+		nullableString(pr.StartsAt), nullableString(pr.EndsAt), nullableString(pr.HappyHourStart),
+		nullableString(pr.HappyHourEnd), nullableString(pr.CouponCode), pr.UsageLimit, isActive}
+}
+
+func (r *MySQLRestaurantRepository) InsertPromotion(pr *request.PromotionRequest) (int64, error) {
+	insertQuery := `
+		INSERT INTO promotions (name, rule_type, value, menu_item_id, min_subtotal, starts_at, ends_at,
+			happy_hour_start, happy_hour_end, coupon_code, usage_limit, is_active, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	currentTime := config.FormatTime(time.Now())
+	result, err := database.DB.Exec(insertQuery, append(promotionArgs(pr), currentTime)...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create promotion: %v", err)
+	}
+	return result.LastInsertId()
+}
+
+func (r *MySQLRestaurantRepository) UpdatePromotion(pr *request.PromotionRequest) error {
+	updateQuery := `
+		UPDATE promotions
+		SET name = ?, rule_type = ?, value = ?, menu_item_id = ?, min_subtotal = ?, starts_at = ?, ends_at = ?,
+			happy_hour_start = ?, happy_hour_end = ?, coupon_code = ?, usage_limit = ?, is_active = ?, updated_at = ?
+		WHERE promotion_id = ? AND is_deleted = FALSE
+	`
+	currentTime := config.FormatTime(time.Now())
+	_, err := database.DB.Exec(updateQuery, append(promotionArgs(pr), currentTime, pr.PromotionId)...)
+	if err != nil {
+		return fmt.Errorf("failed to update promotion: %v", err)
+	}
+	return nil
+}
+
+func (r *MySQLRestaurantRepository) DeletePromotion(pr *request.PromotionRequest) error {
+	deleteQuery := "UPDATE promotions SET is_deleted = TRUE, updated_at = ? WHERE promotion_id = ?"
+	currentTime := config.FormatTime(time.Now())
+	_, err := database.DB.Exec(deleteQuery, currentTime, pr.PromotionId)
+	if err != nil {
+		return fmt.Errorf("failed to delete promotion: %v", err)
+	}
+	return nil
+}
+
+// GetActivePromotions returns the live promotions whose date range covers now, automatic ones and
+// those matching one of couponCodes. Only the coupon rows are locked, as only coupons count their uses,
+// and they are returned even when used up so the caller can tell. Happy hours are checked by the caller.
+func (r *MySQLRestaurantRepository) GetActivePromotions(couponCodes []string, tx *sql.Tx) ([]model.Promotion, error) {
+	currentTime := config.FormatTime(time.Now())
+	active := "is_active = TRUE AND is_deleted = FALSE AND (starts_at IS NULL OR starts_at <= ?) AND (ends_at IS NULL OR ends_at >= ?)"
+	promotions, err := queryPromotions(tx, active+" AND coupon_code IS NULL ORDER BY promotion_id", currentTime, currentTime)
+	if err != nil || len(couponCodes) == 0 {
+		return promotions, err
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(couponCodes)), ", ")
+	args := []any{currentTime, currentTime}
+	for _, code := range couponCodes {
+		args = append(args, code)
+	}
+	coupons, err := queryPromotions(tx, active+" AND coupon_code IN ("+placeholders+") ORDER BY promotion_id FOR UPDATE", args...)
+	if err != nil {
+		return nil, err
+	}
+	return append(promotions, coupons...), nil
+}
+
+func queryPromotions(tx *sql.Tx, condition string, args ...any) ([]model.Promotion, error) {
+	rows, err := tx.Query("SELECT "+promotionColumns+" FROM promotions WHERE "+condition, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var promotions []model.Promotion
+	for rows.Next() {
+		promotion, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, promotion)
+	}
+	return promotions, rows.Err()
+}
+
+// UsePromotion counts one more use of a coupon, it returns false once the usage limit is reached.
+func (r *MySQLRestaurantRepository) UsePromotion(promotionId int, tx *sql.Tx) (bool, error) {
+	updateQuery := `
+		UPDATE promotions
+		SET used_count = used_count + 1
+		WHERE promotion_id = ? AND (usage_limit = 0 OR used_count < usage_limit)
+	`
+	result, err := tx.Exec(updateQuery, promotionId)
+	if err != nil {
+		return false, fmt.Errorf("failed to use promotion: %v", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *MySQLRestaurantRepository) InsertBillPromotions(billId int, applied []model.AppliedPromotion, tx *sql.Tx) error {
+	insertQuery := `
+		INSERT INTO bill_promotions (bill_id, promotion_id, name, rule_type, coupon_code, discount_amount, applied_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	currentTime := config.FormatTime(time.Now())
+	for _, promotion := range applied {
+		_, err := tx.Exec(insertQuery, billId, promotion.PromotionId, promotion.Name, promotion.RuleType,
+			nullableString(promotion.CouponCode), promotion.Discount, currentTime)
+		if err != nil {
+			return fmt.Errorf("failed to record promotion %d on bill: %v", promotion.PromotionId, err)
+		}
+	}
+	return nil
+}
+
+// InsertBillItems replaces what each order item takes of the bill total.
+func (r *MySQLRestaurantRepository) InsertBillItems(billId int, items []model.BillItem, tx *sql.Tx) error {
+	_, err := tx.Exec("DELETE FROM bill_items WHERE bill_id = ?", billId)
+	if err != nil {
+		return fmt.Errorf("failed to remove bill items: %v", err)
+	}
+	insertQuery := "INSERT INTO bill_items (bill_id, order_item_id, amount) VALUES (?, ?, ?)"
+	for _, item := range items {
+		_, err = tx.Exec(insertQuery, billId, item.OrderItemId, item.Charged)
+		if err != nil {
+			return fmt.Errorf("failed to record order item %d on bill: %v", item.OrderItemId, err)
+		}
+	}
+	return nil
+}
+
+func (r *MySQLRestaurantRepository) GetBillPromotions(billId int, tx *sql.Tx) ([]model.AppliedPromotion, error) {
+	query := `
+		SELECT promotion_id, name, rule_type, COALESCE(coupon_code, ''), discount_amount
+		FROM bill_promotions
+		WHERE bill_id = ?
+		ORDER BY id
+	`
+	rows, err := tx.Query(query, billId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := []model.AppliedPromotion{}
+	for rows.Next() {
+		var promotion model.AppliedPromotion
+		if err := rows.Scan(&promotion.PromotionId, &promotion.Name, &promotion.RuleType, &promotion.CouponCode,
+			&promotion.Discount); err != nil {
+			return nil, err
+		}
+		applied = append(applied, promotion)
+	}
+	return applied, rows.Err()
+}
+
+// ReleaseBillPromotions takes the promotions off a bill that is about to be priced again and gives back their uses.
+func (r *MySQLRestaurantRepository) ReleaseBillPromotions(billId int, tx *sql.Tx) error {
+	releaseQuery := `
+		UPDATE promotions p
+		INNER JOIN bill_promotions bp ON p.promotion_id = bp.promotion_id
+		SET p.used_count = GREATEST(p.used_count - 1, 0)
+		WHERE bp.bill_id = ? AND bp.coupon_code IS NOT NULL
+	`
+	_, err := tx.Exec(releaseQuery, billId)
+	if err != nil {
+		return fmt.Errorf("failed to release promotions: %v", err)
+	}
+	_, err = tx.Exec("DELETE FROM bill_promotions WHERE bill_id = ?", billId)
+	if err != nil {
+		return fmt.Errorf("failed to remove bill promotions: %v", err)
+	}
+	return nil
+}
+
+// GetOrderBillItems returns every order item that was not voided, paid or not, with what it took of the bill total.
+func (r *MySQLRestaurantRepository) GetOrderBillItems(orderId int, tx *sql.Tx) ([]model.BillItem, error) {
+	query := `
+		SELECT oi.id, oi.menu_item_id, oi.quantity, oi.price, oi.quantity * oi.price, COALESCE(bi.amount, 0)
+		FROM order_items oi
+		LEFT JOIN bill_items bi ON bi.order_item_id = oi.id
+		WHERE oi.order_id = ? AND oi.item_status <> 'voided'
+		ORDER BY oi.id
+	`
+	rows, err := tx.Query(query, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.BillItem
+	for rows.Next() {
+		var item model.BillItem
+		if err := rows.Scan(&item.OrderItemId, &item.MenuItemId, &item.Quantity, &item.Price, &item.Amount, &item.Charged); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (r *MySQLRestaurantRepository) GetRefundedItemIds(billId int, tx *sql.Tx) ([]int, error) {
+	query := `
+		SELECT ri.order_item_id
+		FROM refund_items ri
+		INNER JOIN refunds rf ON ri.refund_id = rf.refund_id
+		WHERE rf.bill_id = ?
+	`
+	rows, err := tx.Query(query, billId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orderItemIds []int
+	for rows.Next() {
+		var orderItemId int
+		if err := rows.Scan(&orderItemId); err != nil {
+			return nil, err
+		}
+		orderItemIds = append(orderItemIds, orderItemId)
+	}
+	return orderItemIds, rows.Err()
+}
+
+// GetRefundableTenders returns the tenders of the bill's paid payments, latest first, with Amount
+// reduced by what was already refunded or is being refunded on them. Fully refunded tenders are left out.
+func (r *MySQLRestaurantRepository) GetRefundableTenders(billId int, tx *sql.Tx) ([]model.Tender, error) {
+	query := `
+		SELECT t.tender_id, t.payment_id, t.method,
+			t.amount + COALESCE((SELECT SUM(rt.amount) FROM payment_tenders rt WHERE rt.refund_of = t.tender_id AND rt.status <> 'failed'), 0) AS refundable,
+			t.tendered, t.change_amount, COALESCE(t.reference, ''), t.provider
+		FROM payment_tenders t
+		INNER JOIN payments p ON t.payment_id = p.payment_id
+		WHERE p.bill_id = ? AND p.status = 'paid' AND t.refund_of IS NULL AND t.amount > 0
+		HAVING refundable > 0
+		ORDER BY t.tender_id DESC
+	`
+	rows, err := tx.Query(query, billId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tenders []model.Tender
+	for rows.Next() {
+		var tender model.Tender
+		if err := rows.Scan(&tender.TenderId, &tender.PaymentId, &tender.Method, &tender.Amount, &tender.Tendered,
+			&tender.Change, &tender.Reference, &tender.Provider); err != nil {
+			return nil, err
+		}
+		tenders = append(tenders, tender)
+	}
+	return tenders, rows.Err()
+}
+
+func (r *MySQLRestaurantRepository) InsertRefund(refund *model.Refund, tx *sql.Tx) (int64, error) {
+	insertQuery := `
+		INSERT INTO refunds (bill_id, payment_id, kind, reason_code, note, amount, authorized_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	currentTime := config.FormatTime(time.Now())
+	result, err := tx.Exec(insertQuery, refund.BillId, refund.PaymentId, refund.Kind, refund.ReasonCode,
+		nullableString(refund.Note), refund.Amount, refund.AuthorizedBy, currentTime)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create refund: %v", err)
+	}
+	refundId, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	itemQuery := "INSERT INTO refund_items (refund_id, order_item_id, amount) VALUES (?, ?, ?)"
+	for _, item := range refund.Items {
+		_, err = tx.Exec(itemQuery, refundId, item.OrderItemId, item.Amount)
+		if err != nil {
+			return 0, fmt.Errorf("failed to link order item %d to refund: %v", item.OrderItemId, err)
+		}
+	}
+	return refundId, nil
+}
+
+// FindRefundById locks the refund for the rest of the transaction, or returns nil when it does not exist.
+func (r *MySQLRestaurantRepository) FindRefundById(refundId int, tx *sql.Tx) (*model.Refund, error) {
+	query := `
+		SELECT refund_id, bill_id, payment_id, kind, reason_code, COALESCE(note, ''), amount, authorized_by, created_at
+		FROM refunds
+		WHERE refund_id = ?
+		FOR UPDATE
+	`
+	var refund model.Refund
+	err := tx.QueryRow(query, refundId).Scan(&refund.RefundId, &refund.BillId, &refund.PaymentId, &refund.Kind,
+		&refund.ReasonCode, &refund.Note, &refund.Amount, &refund.AuthorizedBy, &refund.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &refund, nil
+}
+
+// GetRefundTenders returns the negative tenders of a refund payment. Tenders that are not settled
+// yet still hold the reference of the charge they return.
+func (r *MySQLRestaurantRepository) GetRefundTenders(paymentId int, tx *sql.Tx) ([]model.Tender, error) {
+	query := `
+		SELECT tender_id, payment_id, method, amount, tendered, change_amount, COALESCE(reference, ''), provider,
+			refund_of, status
+		FROM payment_tenders
+		WHERE payment_id = ? AND refund_of IS NOT NULL
+		ORDER BY tender_id
+	`
+	rows, err := tx.Query(query, paymentId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tenders []model.Tender
+	for rows.Next() {
+		var tender model.Tender
+		if err := rows.Scan(&tender.TenderId, &tender.PaymentId, &tender.Method, &tender.Amount, &tender.Tendered,
+			&tender.Change, &tender.Reference, &tender.Provider, &tender.RefundOf, &tender.Status); err != nil {
+			return nil, err
+		}
+		tenders = append(tenders, tender)
+	}
+	return tenders, rows.Err()
+}
+
+// ReopenRefundTenders puts the declined tenders of a refund payment back to pending before they are retried.
+func (r *MySQLRestaurantRepository) ReopenRefundTenders(paymentId int, tx *sql.Tx) error {
+	updateQuery := `
+		UPDATE payment_tenders
+		SET status = 'pending'
+		WHERE payment_id = ? AND refund_of IS NOT NULL AND status = 'failed'
+	`
+	_, err := tx.Exec(updateQuery, paymentId)
+	if err != nil {
+		return fmt.Errorf("failed to reopen refund tenders: %v", err)
+	}
+	return nil
+}
+
+// DeleteRefund removes a refund that gave nothing back, so its items can be refunded again.
+func (r *MySQLRestaurantRepository) DeleteRefund(refundId int, tx *sql.Tx) error {
+	_, err := tx.Exec("DELETE FROM refunds WHERE refund_id = ?", refundId)
+	if err != nil {
+		return fmt.Errorf("failed to delete refund: %v", err)
+	}
+	return nil
+}
+
+func (r *MySQLRestaurantRepository) AddBillRefund(billId int, amount float64, status string, tx *sql.Tx) error {
+	updateQuery := `
+		UPDATE bills
+		SET refunded_amount = refunded_amount + ?, status = ?
+		WHERE id = ?
+	`
+	_, err := tx.Exec(updateQuery, amount, status, billId)
+	if err != nil {
+		return fmt.Errorf("failed to update bill refunded amount: %v", err)
+	}
+	return nil
+}
+
+func (r *MySQLRestaurantRepository) GetBillRefunds(billId int, tx *sql.Tx) ([]model.Refund, error) {
+	query := `
+		SELECT refund_id, bill_id, payment_id, kind, reason_code, COALESCE(note, ''), amount, authorized_by, created_at
+		FROM refunds
+		WHERE bill_id = ?
+		ORDER BY refund_id
+	`
+	rows, err := tx.Query(query, billId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refunds []model.Refund
+	index := make(map[int]int)
+	for rows.Next() {
+		var refund model.Refund
+		if err := rows.Scan(&refund.RefundId, &refund.BillId, &refund.PaymentId, &refund.Kind, &refund.ReasonCode,
+			&refund.Note, &refund.Amount, &refund.AuthorizedBy, &refund.CreatedAt); err != nil {
+			return nil, err
+		}
+		index[refund.RefundId] = len(refunds)
+		refunds = append(refunds, refund)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	itemQuery := `
+		SELECT ri.refund_id, ri.order_item_id, ri.amount
+		FROM refund_items ri
+		INNER JOIN refunds rf ON ri.refund_id = rf.refund_id
+		WHERE rf.bill_id = ?
+		ORDER BY ri.order_item_id
+	`
+	itemRows, err := tx.Query(itemQuery, billId)
+	if err != nil {
+		return nil, err
+	}
+	defer itemRows.Close()
+	for itemRows.Next() {
+		var refundId int
+		var item model.RefundItem
+		if err := itemRows.Scan(&refundId, &item.OrderItemId, &item.Amount); err != nil {
+			return nil, err
+		}
+		if i, ok := index[refundId]; ok {
+			refunds[i].Items = append(refunds[i].Items, item)
+		}
+	}
+	return refunds, itemRows.Err()
+}
+
+// salesPeriods maps each report grouping to the period it puts a settled bill in and to
+// the order of the periods. Settled times are stored as local wall-clock time.
+var salesPeriods = map[string]struct{ Period, Order string }{
+	enums.ReportByDay:   {"DATE_FORMAT(b.settled_at, '%Y-%m-%d')", "period"},
+	enums.ReportByWeek:  {"DATE_FORMAT(b.settled_at, '%x-W%v')", "period"},
+	enums.ReportByMonth: {"DATE_FORMAT(b.settled_at, '%Y-%m')", "period"},
+	enums.ReportByHour:  {"DATE_FORMAT(b.settled_at, '%H:00')", "period"},
+	enums.ReportByTable: {"CONCAT('Table ', t.table_number)", "MIN(t.table_number)"},
+}
+
+// GetSalesReport sums the bills settled from from up to but not including to. Voided bills
+// never made a sale and are left out, refunded ones count with their refunds.
+func (r *MySQLRestaurantRepository) GetSalesReport(from string, to string, groupBy string) ([]model.SalesRow, error) {
+	period, ok := salesPeriods[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown report grouping %q", groupBy)
+	}
+	query := fmt.Sprintf(`
+		SELECT %s AS period, COUNT(*), SUM(b.paid_amount), SUM(b.discount), SUM(b.service_charge),
+			SUM(b.vat_amount), SUM(b.refunded_amount)
+		FROM bills b
+		INNER JOIN tables t ON b.table_id = t.table_id
+		WHERE b.status IN ('settled', 'refunded') AND b.settled_at >= ? AND b.settled_at < ?
+		GROUP BY period
+		ORDER BY %s
+	`, period.Period, period.Order)
+	rows, err := database.DB.Query(query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var salesRows []model.SalesRow
+	for rows.Next() {
+		var row model.SalesRow
+		if err := rows.Scan(&row.Period, &row.Orders, &row.Gross, &row.Discount, &row.ServiceCharge,
+			&row.Vat, &row.Refunded); err != nil {
+			return nil, err
+		}
+		salesRows = append(salesRows, row)
+	}
+	return salesRows, rows.Err()
+}
+
+// GetMenuItemStats counts the units of each dish on orders placed from from up to but not including
+// to, with the ratings the dish itself got on those orders. Revenue is the share of the paid bills
+// allocated to the dish, so it is net of discounts, and refunded amounts come off it. Deleted dishes
+// only show when they were ordered in the range.
+func (r *MySQLRestaurantRepository) GetMenuItemStats(from string, to string) ([]model.MenuItemStats, error) {
+	query := `
+		SELECT m.menu_items_id, m.name, COALESCE(c.name, ''),
+			COALESCE(s.ordered, 0), COALESCE(s.sold, 0), COALESCE(s.canceled, 0), COALESCE(s.refunded, 0),
+			COALESCE(s.revenue, 0), COALESCE(rt.average_rating, 0), COALESCE(rt.ratings, 0)
+		FROM menu_items m
+		LEFT JOIN categories c ON m.category_id = c.category_id
+		LEFT JOIN (
+			SELECT oi.menu_item_id,
+				SUM(oi.quantity) AS ordered,
+				SUM(IF(oi.item_status <> 'voided' AND b.status IN ('settled', 'refunded') AND ri.order_item_id IS NULL,
+					oi.quantity, 0)) AS sold,
+				SUM(IF(oi.item_status = 'voided' OR o.status = 'canceled', oi.quantity, 0)) AS canceled,
+				SUM(IF(ri.order_item_id IS NOT NULL, oi.quantity, 0)) AS refunded,
+				SUM(IF(oi.item_status <> 'voided' AND b.status IN ('settled', 'refunded'),
+					COALESCE(bi.amount, 0) - COALESCE(ri.amount, 0), 0)) AS revenue
+			FROM order_items oi
+			INNER JOIN orders o ON oi.order_id = o.order_id
+			LEFT JOIN bills b ON b.order_id = o.order_id
+			LEFT JOIN bill_items bi ON bi.bill_id = b.id AND bi.order_item_id = oi.id
+			LEFT JOIN refund_items ri ON ri.order_item_id = oi.id
+			WHERE o.created_at >= ? AND o.created_at < ?
+			GROUP BY oi.menu_item_id
+		) s ON s.menu_item_id = m.menu_items_id
+		LEFT JOIN (
+			SELECT rv.menu_item_id, AVG(rv.rating) AS average_rating, COUNT(*) AS ratings
+			FROM reviews rv
+			INNER JOIN orders o ON rv.order_id = o.order_id
+			WHERE rv.menu_item_id IS NOT NULL AND rv.is_deleted = FALSE AND rv.is_hidden = FALSE
+				AND o.created_at >= ? AND o.created_at < ?
+			GROUP BY rv.menu_item_id
+		) rt ON rt.menu_item_id = m.menu_items_id
+		WHERE m.is_deleted = FALSE OR s.menu_item_id IS NOT NULL
+		ORDER BY COALESCE(s.revenue, 0) DESC, m.name
+	`
+	rows, err := database.DB.Query(query, from, to, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.MenuItemStats
+	for rows.Next() {
+		var item model.MenuItemStats
+		if err := rows.Scan(&item.MenuItemId, &item.Name, &item.Category, &item.UnitsOrdered, &item.UnitsSold,
+			&item.UnitsCanceled, &item.UnitsRefunded, &item.Revenue, &item.AverageRating, &item.Ratings); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// reviewConditions turns a review filter into WHERE conditions on reviews rv joined with orders o.
+// Dates compare against the stored local wall-clock time.
+func reviewConditions(f *request.ReviewFilterRequest) (string, []any) {
+	conditions := []string{"rv.is_deleted = FALSE"}
+	var args []any
+	switch f.Visibility {
+	case "hidden":
+		conditions = append(conditions, "rv.is_hidden = TRUE")
+	case "all":
+	default:
+		conditions = append(conditions, "rv.is_hidden = FALSE")
+	}
+	if f.Rating > 0 {
+		conditions = append(conditions, "rv.rating = ?")
+		args = append(args, f.Rating)
+	}
+	if f.MinRating > 0 {
+		conditions = append(conditions, "rv.rating >= ?")
+		args = append(args, f.MinRating)
+	}
+	if f.MaxRating > 0 {
+		conditions = append(conditions, "rv.rating <= ?")
+		args = append(args, f.MaxRating)
+	}
+	if f.From != "" {
+		conditions = append(conditions, "rv.review_date >= ?")
+		args = append(args, f.From)
+	}
+	if f.To != "" {
+		conditions = append(conditions, "rv.review_date < DATE_ADD(?, INTERVAL 1 DAY)")
+		args = append(args, f.To)
+	}
+	if f.OrderId > 0 {
+		conditions = append(conditions, "rv.order_id = ?")
+		args = append(args, f.OrderId)
+	}
+	if f.TableId > 0 {
+		conditions = append(conditions, "o.table_id = ?")
+		args = append(args, f.TableId)
+	}
+	if f.MenuItemId > 0 {
+		conditions = append(conditions, "rv.menu_item_id = ?")
+		args = append(args, f.MenuItemId)
+	}
+	switch f.Kind {
+	case "order":
+		conditions = append(conditions, "rv.menu_item_id IS NULL")
+	case "dish":
+		conditions = append(conditions, "rv.menu_item_id IS NOT NULL")
+	}
+	if f.Replied != nil {
+		if *f.Replied {
+			conditions = append(conditions, "rv.reply IS NOT NULL")
+		} else {
+			conditions = append(conditions, "rv.reply IS NULL")
+		}
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+func (r *MySQLRestaurantRepository) GetAllReviews(f *request.ReviewFilterRequest) ([]model.Review, int, error) {
+	where, args := reviewConditions(f)
+	from := `
+		FROM reviews rv
+		INNER JOIN orders o ON rv.order_id = o.order_id
+		LEFT JOIN tables t ON o.table_id = t.table_id
+		LEFT JOIN menu_items mi ON rv.menu_item_id = mi.menu_items_id
+		WHERE ` + where
+
+	var total int
+	err := database.DB.QueryRow("SELECT count(1)"+from, args...).Scan(&total)
+	if err != nil {
+		log.Printf("Error counting reviews from database: %v", err)
+		return nil, 0, err
+	}
+
+	direction := " DESC"
+	if f.Order == "asc" {
+		direction = " ASC"
+	}
+	query := `
+		SELECT rv.id, rv.order_id, COALESCE(rv.menu_item_id, 0), COALESCE(mi.name, ''),
+		       COALESCE(o.table_id, 0), COALESCE(t.table_number, 0), rv.rating,
+		       COALESCE(rv.comment, ''), COALESCE(rv.reply, ''), COALESCE(rv.replied_by, 0), rv.replied_at,
+		       rv.is_hidden, COALESCE(rv.moderation_note, ''), rv.review_date` + from + `
+		ORDER BY rv.review_date` + direction + `, rv.id` + direction
+	if f.PageSize > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, f.PageSize, (f.Page-1)*f.PageSize)
+	}
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		log.Printf("Error fetching reviews from database: %v", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	reviews := []model.Review{}
+	for rows.Next() {
+		var review model.Review
+		var repliedAt sql.NullString
+		if err := rows.Scan(&review.ReviewId, &review.OrderId, &review.MenuItemId, &review.MenuItemName,
+			&review.TableId, &review.TableNumber, &review.Rating,
+			&review.Comment, &review.Reply, &review.RepliedBy, &repliedAt, &review.IsHidden, &review.ModerationNote,
+			&review.ReviewDate); err != nil {
+			log.Printf("Error scanning review: %v", err)
+			return nil, 0, err
+		}
+		if repliedAt.Valid {
+			review.RepliedAt = &repliedAt.String
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews, total, rows.Err()
+}
+
+func (r *MySQLRestaurantRepository) GetReviewSummary(f *request.ReviewFilterRequest) (*model.ReviewSummary, error) {
+	where, args := reviewConditions(f)
+	query := `
+		SELECT COUNT(*), COALESCE(AVG(rv.rating), 0),
+		       COALESCE(SUM(rv.rating = 1), 0), COALESCE(SUM(rv.rating = 2), 0), COALESCE(SUM(rv.rating = 3), 0),
+		       COALESCE(SUM(rv.rating = 4), 0), COALESCE(SUM(rv.rating = 5), 0),
+		       COALESCE(SUM(rv.reply IS NOT NULL), 0), COALESCE(SUM(rv.is_hidden), 0)
+		FROM reviews rv
+		INNER JOIN orders o ON rv.order_id = o.order_id
+		WHERE ` + where
+	summary := &model.ReviewSummary{RatingCounts: make(map[int]int, 5)}
+	var counts [5]int
+	err := database.DB.QueryRow(query, args...).Scan(&summary.Count, &summary.AverageRating,
+		&counts[0], &counts[1], &counts[2], &counts[3], &counts[4], &summary.Replied, &summary.Hidden)
+	if err != nil {
+		return nil, err
+	}
+	for i, count := range counts {
+		summary.RatingCounts[i+1] = count
+	}
+	return summary, nil
+}
+
+func (r *MySQLRestaurantRepository) FindReviewById(reviewId int) (bool, error) {
+	query := "SELECT count(1) FROM reviews WHERE id = ? AND is_deleted = FALSE"
+	var count int
+	err := database.DB.QueryRow(query, reviewId).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *MySQLRestaurantRepository) ReplyReview(rr *request.ReviewReplyRequest) error {
+	updateQuery := "UPDATE reviews SET reply = ?, replied_by = ?, replied_at = ? WHERE id = ? AND is_deleted = FALSE"
+	currentTime := config.FormatTime(time.Now())
+	_, err := database.DB.Exec(updateQuery, rr.Reply, nullableId(rr.UserId), currentTime, rr.ReviewId)
+	if err != nil {
+		return fmt.Errorf("failed to reply to review: %v", err)
+	}
+	return nil
+}
+
+func (r *MySQLRestaurantRepository) ModerateReview(rr *request.ReviewModerationRequest) error {
+	updateQuery := `
+		UPDATE reviews
+		SET is_hidden = ?, moderation_note = ?, moderated_by = ?, moderated_at = ?
+		WHERE id = ? AND is_deleted = FALSE
+	`
+	currentTime := config.FormatTime(time.Now())
+	_, err := database.DB.Exec(updateQuery, rr.Hidden, nullableString(rr.Note), nullableId(rr.UserId), currentTime, rr.ReviewId)
+	if err != nil {
+		return fmt.Errorf("failed to moderate review: %v", err)
+	}
+	return nil
+}
+
+func (r *MySQLRestaurantRepository) DeleteReview(rr *request.ReviewModerationRequest) error {
+	deleteQuery := `
+		UPDATE reviews
+		SET is_deleted = TRUE, moderation_note = COALESCE(?, moderation_note), moderated_by = ?, moderated_at = ?
+		WHERE id = ?
+	`
+	currentTime := config.FormatTime(time.Now())
+	_, err := database.DB.Exec(deleteQuery, nullableString(rr.Note), nullableId(rr.UserId), currentTime, rr.ReviewId)
+	if err != nil {
+		return fmt.Errorf("failed to delete review: %v", err)
+	}
+	return nil
+}
+
+const reservationColumns = `
+	rs.reservation_id, rs.guest_name, rs.phone, rs.party_size, COALESCE(rs.table_id, 0), COALESCE(t.table_number, 0),
+	rs.reserved_at, rs.duration_minutes, rs.status, COALESCE(rs.note, ''), rs.created_at`
+
+func scanReservation(row interface{ Scan(...any) error }) (model.Reservation, error) {
+	var reservation model.Reservation
+	err := row.Scan(&reservation.ReservationId, &reservation.GuestName, &reservation.Phone, &reservation.PartySize,
+		&reservation.TableId, &reservation.TableNumber, &reservation.ReservedAt, &reservation.DurationMinutes,
+		&reservation.Status, &reservation.Note, &reservation.CreatedAt)
+	return reservation, err
+}
+
+func (r *MySQLRestaurantRepository) GetAllReservations(f *request.ReservationFilterRequest) ([]model.Reservation, error) {
+	conditions := []string{"rs.reserved_at >= ?", "rs.reserved_at < DATE_ADD(?, INTERVAL 1 DAY)"}
+	args := []any{f.Date, f.Date}
+	if f.Status != "" {
+		conditions = append(conditions, "rs.status = ?")
+		args = append(args, f.Status)
+	}
+	if f.TableId > 0 {
+		conditions = append(conditions, "rs.table_id = ?")
+		args = append(args, f.TableId)
+	}
+	query := `
+		SELECT` + reservationColumns + `
+		FROM reservations rs
+		LEFT JOIN tables t ON rs.table_id = t.table_id
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY rs.reserved_at, rs.reservation_id`
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reservations := []model.Reservation{}
+	for rows.Next() {
+		reservation, err := scanReservation(rows)
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, reservation)
+	}
+	return reservations, rows.Err()
+}
+
+// FindReservationById locks the reservation for the rest of the transaction, it returns nil when none exists.
+func (r *MySQLRestaurantRepository) FindReservationById(reservationId int, tx *sql.Tx) (*model.Reservation, error) {
+	query := `
+		SELECT` + reservationColumns + `
+		FROM reservations rs
+		LEFT JOIN tables t ON rs.table_id = t.table_id
+		WHERE rs.reservation_id = ?
+		FOR UPDATE`
+	reservation, err := scanReservation(tx.QueryRow(query, reservationId))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// LockTable locks the table row and returns its status and seat count, or an empty status when the
+// table does not exist.
+func (r *MySQLRestaurantRepository) LockTable(tableId int, tx *sql.Tx) (string, int, error) {
+	query := "SELECT table_status, capacity FROM tables WHERE table_id = ? AND is_deleted = FALSE FOR UPDATE"
+	var tableStatus string
+	var capacity int
+	err := tx.QueryRow(query, tableId).Scan(&tableStatus, &capacity)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", 0, nil
+		}
+		return "", 0, err
+	}
+	return tableStatus, capacity, nil
+}
+
+// HasReservationConflict reports whether another live booking of the table overlaps start to end.
+func (r *MySQLRestaurantRepository) HasReservationConflict(tableId int, start string, end string, reservationId int, tx *sql.Tx) (bool, error) {
+	query := `
+		SELECT count(1) FROM reservations
+		WHERE table_id = ? AND reservation_id <> ? AND status IN ('booked', 'seated')
+		AND reserved_at < ? AND DATE_ADD(reserved_at, INTERVAL duration_minutes MINUTE) > ?
+	`
+	var count int
+	err := tx.QueryRow(query, tableId, reservationId, end, start).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *MySQLRestaurantRepository) InsertReservation(rr *request.ReservationRequest, reservedAt string, tx *sql.Tx) (int64, error) {
+	insertQuery := `
+		INSERT INTO reservations (guest_name, phone, party_size, table_id, reserved_at, duration_minutes, note, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	currentTime := config.FormatTime(time.Now())
+	result, err := tx.Exec(insertQuery, rr.GuestName, rr.Phone, rr.PartySize, nullableId(rr.TableId), reservedAt,
+		rr.DurationMinutes, nullableString(rr.Note), nullableId(rr.UserId), currentTime)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create reservation: %v", err)
+	}
+	return result.LastInsertId()
+}
+
+func (r *MySQLRestaurantRepository) UpdateReservation(rr *request.ReservationRequest, reservedAt string, tx *sql.Tx) error {
+	updateQuery := `
+		UPDATE reservations
+		SET guest_name = ?, phone = ?, party_size = ?, table_id = ?, reserved_at = ?, duration_minutes = ?, note = ?, updated_at = ?
+		WHERE reservation_id = ?
+	`
+	currentTime := config.FormatTime(time.Now())
+	_, err := tx.Exec(updateQuery, rr.GuestName, rr.Phone, rr.PartySize, nullableId(rr.TableId), reservedAt,
+		rr.DurationMinutes, nullableString(rr.Note), currentTime, rr.ReservationId)
+	if err != nil {
+		return fmt.Errorf("failed to update reservation: %v", err)
+	}
+	return nil
+}
+
+func (r *MySQLRestaurantRepository) UpdateReservationStatus(reservationId int, status string, tx *sql.Tx) error {
+	updateQuery := "UPDATE reservations SET status = ?, updated_at = ? WHERE reservation_id = ?"
+	currentTime := config.FormatTime(time.Now())
+	_, err := tx.Exec(updateQuery, status, currentTime, reservationId)
+	if err != nil {
+		return fmt.Errorf("failed to update reservation status: %v", err)
+	}
+	return nil
+}
+
+func (r *MySQLRestaurantRepository) UpdateTableWithTx(tableId int, tableStatus string, tx *sql.Tx) error {
+	updateQuery := `
+		UPDATE tables
+		SET table_status = ?, updated_at = ?
+		WHERE table_id = ? AND is_deleted = FALSE
+	`
+	currentTime := config.FormatTime(time.Now())
+	_, err := tx.Exec(updateQuery, tableStatus, currentTime, tableId)
+	if err != nil {
+		return fmt.Errorf("failed to update table status: %v", err)
+	}
+	return nil
+}
+
+// SyncReservedTables marks bookings that started before noShowBefore as no-shows, then reserves the
+// available tables booked before holdUntil and releases reserved tables that no longer are. It
+// returns the number of new no-shows.
+func (r *MySQLRestaurantRepository) SyncReservedTables(holdUntil string, noShowBefore string, tx *sql.Tx) (int64, error) {
+	currentTime := config.FormatTime(time.Now())
+	result, err := tx.Exec(`
+		UPDATE reservations
+		SET status = 'no_show', updated_at = ?
+		WHERE status = 'booked' AND reserved_at < ?
+	`, currentTime, noShowBefore)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark no-show reservations: %v", err)
+	}
+	noShows, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	held := `
+		SELECT 1 FROM reservations rs
+		WHERE rs.table_id = t.table_id AND rs.status = 'booked' AND rs.reserved_at <= ?`
+	_, err = tx.Exec(`
+		UPDATE tables t
+		SET t.table_status = 'reserved', t.updated_at = ?
+		WHERE t.table_status = 'available' AND t.is_deleted = FALSE AND EXISTS (`+held+`)
+	`, currentTime, holdUntil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reserve tables: %v", err)
+	}
+	_, err = tx.Exec(`
+		UPDATE tables t
+		SET t.table_status = 'available', t.updated_at = ?
+		WHERE t.table_status = 'reserved' AND NOT EXISTS (`+held+`)
+	`, currentTime, holdUntil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to release reserved tables: %v", err)
+	}
+	return noShows, nil
+}
+
+const waitlistColumns = `
+	w.waitlist_id, w.party_name, w.phone, w.party_size, w.status, COALESCE(w.table_id, 0), COALESCE(t.table_number, 0),
+	w.quoted_wait_minutes, COALESCE(w.note, ''), w.created_at, w.notified_at`
+
+func scanWaitlistEntry(row interface{ Scan(...any) error }) (model.WaitlistEntry, error) {
+	var entry model.WaitlistEntry
+	var notifiedAt sql.NullString
+	err := row.Scan(&entry.WaitlistId, &entry.PartyName, &entry.Phone, &entry.PartySize, &entry.Status,
+		&entry.TableId, &entry.TableNumber, &entry.QuotedWaitMinutes, &entry.Note, &entry.CreatedAt, &notifiedAt)
+	entry.NotifiedAt = notifiedAt.String
+	return entry, err
+}
+
+// findWaitlistEntry runs a waitlist query that locks at most one entry, it returns nil when none matches.
+func findWaitlistEntry(tx *sql.Tx, condition string, args ...any) (*model.WaitlistEntry, error) {
+	query := `
+		SELECT` + waitlistColumns + `
+		FROM waitlist w
+		LEFT JOIN tables t ON w.table_id = t.table_id
+		WHERE ` + condition + `
+		ORDER BY w.created_at, w.waitlist_id
+		LIMIT 1
+		FOR UPDATE`
+	entry, err := scanWaitlistEntry(tx.QueryRow(query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// GetWaitlist returns the parties still waiting or notified, first come first.
+func (r *MySQLRestaurantRepository) GetWaitlist() ([]model.WaitlistEntry, error) {
+	query := `
+		SELECT` + waitlistColumns + `
+		FROM waitlist w
+		LEFT JOIN tables t ON w.table_id = t.table_id
+		WHERE w.status IN ('waiting', 'notified')
+		ORDER BY w.created_at, w.waitlist_id`
+	rows, err := database.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []model.WaitlistEntry{}
+	for rows.Next() {
+		entry, err := scanWaitlistEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func (r *MySQLRestaurantRepository) FindWaitlistEntryById(waitlistId int, tx *sql.Tx) (*model.WaitlistEntry, error) {
+	return findWaitlistEntry(tx, "w.waitlist_id = ?", waitlistId)
+}
+
+// FindNotifiedParty returns the party the table is already suggested to.
+func (r *MySQLRestaurantRepository) FindNotifiedParty(tableId int, tx *sql.Tx) (*model.WaitlistEntry, error) {
+	return findWaitlistEntry(tx, "w.table_id = ? AND w.status = 'notified'", tableId)
+}
+
+// FindNextWaitingParty returns the longest waiting party that fits capacity seats.
+func (r *MySQLRestaurantRepository) FindNextWaitingParty(capacity int, tx *sql.Tx) (*model.WaitlistEntry, error) {
+	return findWaitlistEntry(tx, "w.status = 'waiting' AND w.party_size <= ?", capacity)
+}
+
+func (r *MySQLRestaurantRepository) InsertWaitlistEntry(wr *request.WaitlistRequest, quotedWaitMinutes int) (int64, error) {
+	insertQuery := `
+		INSERT INTO waitlist (party_name, phone, party_size, quoted_wait_minutes, note, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	currentTime := config.FormatTime(time.Now())
+	result, err := database.DB.Exec(insertQuery, wr.PartyName, wr.Phone, wr.PartySize, quotedWaitMinutes,
+		nullableString(wr.Note), nullableId(wr.UserId), currentTime)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add waitlist entry: %v", err)
+	}
+	return result.LastInsertId()
+}
+
+// UpdateWaitlistStatus moves the entry to status with the suggested or seated table, and stamps when
+// the party was notified or seated.
+func (r *MySQLRestaurantRepository) UpdateWaitlistStatus(waitlistId int, status string, tableId int, tx *sql.Tx) error {
+	updateQuery := `
+		UPDATE waitlist
+		SET status = ?, table_id = ?, updated_at = ?,
+		    notified_at = IF(? = 'notified', ?, notified_at),
+		    seated_at = IF(? = 'seated', ?, seated_at)
+		WHERE waitlist_id = ?
+	`
+	currentTime := config.FormatTime(time.Now())
+	_, err := tx.Exec(updateQuery, status, nullableId(tableId), currentTime, status, currentTime, status, currentTime, waitlistId)
+	if err != nil {
+		return fmt.Errorf("failed to update waitlist status: %v", err)
+	}
+	return nil
+}
+
+// ReleaseNotifiedParties puts the parties other than waitlistId that were suggested the table back in
+// the queue, keeping their place.
+func (r *MySQLRestaurantRepository) ReleaseNotifiedParties(tableId int, waitlistId int, tx *sql.Tx) error {
+	updateQuery := `
+		UPDATE waitlist
+		SET status = 'waiting', table_id = NULL, updated_at = ?
+		WHERE table_id = ? AND status = 'notified' AND waitlist_id <> ?
+	`
+	currentTime := config.FormatTime(time.Now())
+	_, err := tx.Exec(updateQuery, currentTime, tableId, waitlistId)
+	if err != nil {
+		return fmt.Errorf("failed to release notified parties: %v", err)
+	}
+	return nil
+}
+
+// GetTableOccupancy returns every table with its seat count, status and how many minutes before now
+// its open session started.
+func (r *MySQLRestaurantRepository) GetTableOccupancy(now string) ([]model.TableOccupancy, error) {
+	query := `
+		SELECT t.table_id, t.capacity, t.table_status,
+		       (SELECT TIMESTAMPDIFF(MINUTE, MAX(ts.created_at), ?) FROM table_sessions ts
+		        WHERE ts.table_id = t.table_id AND ts.closed_at IS NULL)
+		FROM tables t
+		WHERE t.is_deleted = FALSE
+		ORDER BY t.capacity, t.table_number`
+	rows, err := database.DB.Query(query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := []model.TableOccupancy{}
+	for rows.Next() {
+		var table model.TableOccupancy
+		var seatedMinutes sql.NullInt64
+		if err := rows.Scan(&table.TableId, &table.Capacity, &table.TableStatus, &seatedMinutes); err != nil {
+			return nil, err
+		}
+		table.InSession = seatedMinutes.Valid
+		table.SeatedMinutes = int(seatedMinutes.Int64)
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+// GetAverageDiningMinutes averages how long the table sessions closed since then lasted, ignoring
+// sessions shorter than five minutes or longer than six hours. It returns 0 without any sessions.
+func (r *MySQLRestaurantRepository) GetAverageDiningMinutes(since string) (float64, error) {
+	query := `
+		SELECT COALESCE(AVG(TIMESTAMPDIFF(MINUTE, created_at, closed_at)), 0)
+		FROM table_sessions
+		WHERE closed_at IS NOT NULL AND created_at >= ?
+		  AND TIMESTAMPDIFF(MINUTE, created_at, closed_at) BETWEEN 5 AND 360`
+	var minutes float64
+	err := database.DB.QueryRow(query, since).Scan(&minutes)
+	if err != nil {
+		return 0, fmt.Errorf("failed to average dining time: %v", err)
+	}
+	return minutes, nil
+}
+
+// GetFloorPlan returns every table in zone order with the value of its unpaid orders, the minutes its
+// open session has been running at now and its next booking before dayEnd.
+func (r *MySQLRestaurantRepository) GetFloorPlan(now string, dayEnd string) ([]model.FloorTable, error) {
+	query := `
+		SELECT t.table_id, t.table_number, t.table_status, t.capacity, t.zone, t.pos_x, t.pos_y, COALESCE(t.merged_into, 0),
+		       COUNT(DISTINCT o.order_id),
+		       COALESCE(SUM(CASE WHEN oi.item_status <> 'voided' THEN oi.price * oi.quantity END), 0),
+		       (SELECT TIMESTAMPDIFF(MINUTE, MAX(ts.created_at), ?) FROM table_sessions ts
+		        WHERE ts.table_id = t.table_id AND ts.closed_at IS NULL),
+		       (SELECT DATE_FORMAT(MIN(rs.reserved_at), '%Y-%m-%d %H:%i') FROM reservations rs
+		        WHERE rs.table_id = t.table_id AND rs.status = 'booked' AND rs.reserved_at < ?)
+		FROM tables t
+		LEFT JOIN orders o ON o.table_id = t.table_id AND o.is_deleted = FALSE AND o.checkout_id IS NULL
+		     AND o.status IN ('created', 'prepare', 'completed')
+		LEFT JOIN order_items oi ON oi.order_id = o.order_id
+		WHERE t.is_deleted = FALSE
+		GROUP BY t.table_id, t.table_number, t.table_status, t.capacity, t.zone, t.pos_x, t.pos_y, t.merged_into
+		ORDER BY t.zone, t.table_number`
+	rows, err := database.DB.Query(query, now, dayEnd)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := []model.FloorTable{}
+	for rows.Next() {
+		var table model.FloorTable
+		var seatedMinutes sql.NullInt64
+		var nextReservation sql.NullString
+		table.Table, err = scanTable(rows, &table.OpenOrderTotal, &seatedMinutes, &nextReservation)
+		if err != nil {
+			return nil, err
+		}
+		table.SeatedMinutes = int(seatedMinutes.Int64)
+		table.NextReservation = nextReservation.String
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+// GetTableSuggestions returns the free tables that seat partySize, smallest first, leaving out tables
+// suggested to a waitlist party or booked before until.
+func (r *MySQLRestaurantRepository) GetTableSuggestions(partySize int, zone string, until string) ([]model.Table, error) {
+	conditions := []string{"t.is_deleted = FALSE", "t.table_status = 'available'", "t.capacity >= ?",
+		"NOT EXISTS (SELECT 1 FROM waitlist w WHERE w.table_id = t.table_id AND w.status = 'notified')",
+		"NOT EXISTS (SELECT 1 FROM reservations rs WHERE rs.table_id = t.table_id AND rs.status = 'booked' AND rs.reserved_at < ?)"}
+	args := []any{partySize, until}
+	if zone != "" {
+		conditions = append(conditions, "t.zone = ?")
+		args = append(args, zone)
+	}
+	query := tableSelectQuery + " WHERE " + strings.Join(conditions, " AND ") + " ORDER BY t.capacity, t.table_number"
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := []model.Table{}
+	for rows.Next() {
+		table, err := scanTable(rows)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+// LockTableStates locks the tables in id order and returns them by id, with how many tables are
+// merged into each. Tables that do not exist are left out.
+func (r *MySQLRestaurantRepository) LockTableStates(tableIds []int, tx *sql.Tx) (map[int]model.TableState, error) {
+	placeholders := make([]string, len(tableIds))
+	args := make([]any, len(tableIds))
+	for i, tableId := range tableIds {
+		placeholders[i] = "?"
+		args[i] = tableId
+	}
+	query := `
+		SELECT t.table_id, t.table_number, t.table_status, COALESCE(t.merged_into, 0),
+		       (SELECT count(1) FROM tables m WHERE m.merged_into = t.table_id AND m.is_deleted = FALSE)
+		FROM tables t
+		WHERE t.table_id IN (` + strings.Join(placeholders, ", ") + `) AND t.is_deleted = FALSE
+		ORDER BY t.table_id
+		FOR UPDATE`
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := map[int]model.TableState{}
+	for rows.Next() {
+		var table model.TableState
+		if err := rows.Scan(&table.TableId, &table.TableNumber, &table.TableStatus, &table.MergedInto, &table.Members); err != nil {
+			return nil, err
+		}
+		tables[table.TableId] = table
+	}
+	return tables, rows.Err()
+}
+
+// GetGroupMembers locks and returns the tables merged into tableId.
+func (r *MySQLRestaurantRepository) GetGroupMembers(tableId int, tx *sql.Tx) ([]int, error) {
+	query := "SELECT table_id FROM tables WHERE merged_into = ? AND is_deleted = FALSE ORDER BY table_id FOR UPDATE"
+	return queryIds(tx, query, tableId)
+}
+
+// FindTableGroup returns the table the given table is merged into and how many tables are merged into it.
+func (r *MySQLRestaurantRepository) FindTableGroup(tableId int) (int, int, error) {
+	query := `
+		SELECT COALESCE(t.merged_into, 0),
+		       (SELECT count(1) FROM tables m WHERE m.merged_into = t.table_id AND m.is_deleted = FALSE)
+		FROM tables t
+		WHERE t.table_id = ?`
+	var mergedInto, members int
+	err := database.DB.QueryRow(query, tableId).Scan(&mergedInto, &members)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, 0, err
+	}
+	return mergedInto, members, nil
+}
+
+func (r *MySQLRestaurantRepository) SetTableMergedInto(tableId int, mergedInto int, tx *sql.Tx) error {
+	updateQuery := "UPDATE tables SET merged_into = ?, updated_at = ? WHERE table_id = ?"
+	currentTime := config.FormatTime(time.Now())
+	_, err := tx.Exec(updateQuery, nullableId(mergedInto), currentTime, tableId)
+	if err != nil {
+		return fmt.Errorf("failed to update table group: %v", err)
+	}
+	return nil
+}
+
+// MoveTableOrders moves the live orders of a table that were not checked out, and their bills, to
+// another table and returns how many orders moved. A merge remembers the table each order came from
+// so it can be split back.
+func (r *MySQLRestaurantRepository) MoveTableOrders(fromTableId int, toTableId int, merge bool, tx *sql.Tx) (int64, error) {
+	currentTime := config.FormatTime(time.Now())
+	_, err := tx.Exec(`
+		UPDATE bills b
+		INNER JOIN orders o ON b.order_id = o.order_id
+		SET b.table_id = ?
+		WHERE o.table_id = ? AND o.is_deleted = FALSE AND o.checkout_id IS NULL
+	`, toTableId, fromTableId)
+	if err != nil {
+		return 0, fmt.Errorf("failed to move bills: %v", err)
+	}
+	result, err := tx.Exec(`
+		UPDATE orders
+		SET table_id = ?, origin_table_id = IF(?, COALESCE(origin_table_id, ?), origin_table_id), updated_at = ?
+		WHERE table_id = ? AND is_deleted = FALSE AND checkout_id IS NULL
+	`, toTableId, merge, fromTableId, currentTime, fromTableId)
+	if err != nil {
+		return 0, fmt.Errorf("failed to move orders: %v", err)
+	}
+	return result.RowsAffected()
+}
+
+// ReturnMergedOrders moves the live orders that came from originTableId in a merge, and their bills,
+// from tableId back to it and returns how many orders moved.
+func (r *MySQLRestaurantRepository) ReturnMergedOrders(tableId int, originTableId int, tx *sql.Tx) (int64, error) {
+	currentTime := config.FormatTime(time.Now())
+	_, err := tx.Exec(`
+		UPDATE bills b
+		INNER JOIN orders o ON b.order_id = o.order_id
+		SET b.table_id = ?
+		WHERE o.table_id = ? AND o.origin_table_id = ? AND o.is_deleted = FALSE AND o.checkout_id IS NULL
+	`, originTableId, tableId, originTableId)
+	if err != nil {
+		return 0, fmt.Errorf("failed to move bills: %v", err)
+	}
+	result, err := tx.Exec(`
+		UPDATE orders
+		SET table_id = ?, origin_table_id = NULL, updated_at = ?
+		WHERE table_id = ? AND origin_table_id = ? AND is_deleted = FALSE AND checkout_id IS NULL
+	`, originTableId, currentTime, tableId, originTableId)
+	if err != nil {
+		return 0, fmt.Errorf("failed to move orders: %v", err)
+	}
+	return result.RowsAffected()
+}
+
+// SetTableOrderSession hands the live orders of a table that were not checked out to its new
+// customer session, so the party still sees them in its order history.
+func (r *MySQLRestaurantRepository) SetTableOrderSession(tableId int, sessionId int, tx *sql.Tx) error {
+	updateQuery := "UPDATE orders SET session_id = ? WHERE table_id = ? AND is_deleted = FALSE AND checkout_id IS NULL"
+	_, err := tx.Exec(updateQuery, nullableId(sessionId), tableId)
+	if err != nil {
+		return fmt.Errorf("failed to update order sessions: %v", err)
+	}
+	return nil
+}
+
+// FindOpenTableSession returns the current customer session of the table, or 0 when there is none.
+func (r *MySQLRestaurantRepository) FindOpenTableSession(tableId int, tx *sql.Tx) (int, error) {
+	var sessionId int
+	err := tx.QueryRow(openTableSessionQuery, tableId, config.FormatTime(time.Now())).Scan(&sessionId)
+	if err != nil {
+		return 0, err
+	}
+	return sessionId, nil
+}
+
+func (r *MySQLRestaurantRepository) InsertTableSessionWithTx(tableId int, expiresAt time.Time, tx *sql.Tx) (int64, error) {
+	insertQuery := "INSERT INTO table_sessions (table_id, expires_at, created_at) VALUES (?, ?, ?)"
+	currentTime := config.FormatTime(time.Now())
+	result, err := tx.Exec(insertQuery, tableId, config.FormatTime(expiresAt), currentTime)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create table session: %v", err)
+	}
+	return result.LastInsertId()
+}
+
+func (r *MySQLRestaurantRepository) CloseTableSessionsWithTx(tableId int, tx *sql.Tx) error {
+	updateQuery := `
+		UPDATE table_sessions
+		SET closed_at = ?
+		WHERE table_id = ? AND closed_at IS NULL
+	`
+	currentTime := config.FormatTime(time.Now())
+	_, err := tx.Exec(updateQuery, currentTime, tableId)
+	if err != nil {
+		return fmt.Errorf("failed to close table sessions: %v", err)
+	}
+	return nil
+}
+
+// GetCheckoutOrders locks and returns the live orders of a table that were not checked out yet with
+// their item count and bill amounts. Canceled orders count no items and no total.
+func (r *MySQLRestaurantRepository) GetCheckoutOrders(tableId int, tx *sql.Tx) ([]model.CheckoutOrder, error) {
+	query := `
+		SELECT o.order_id, o.status, o.created_at,
+		       CASE WHEN o.status = 'canceled' THEN 0 ELSE COALESCE(i.items, 0) END,
+		       CASE WHEN o.status = 'canceled' THEN 0 ELSE COALESCE(b.total_amount, i.amount, 0) END,
+		       COALESCE(b.paid_amount, 0), COALESCE(b.refunded_amount, 0)
+		FROM orders o
+		LEFT JOIN bills b ON b.order_id = o.order_id
+		LEFT JOIN (
+			SELECT order_id, SUM(quantity) AS items, SUM(price * quantity) AS amount
+			FROM order_items
+			WHERE item_status <> 'voided'
+			GROUP BY order_id
+		) i ON i.order_id = o.order_id
+		WHERE o.table_id = ? AND o.is_deleted = FALSE AND o.checkout_id IS NULL
+		ORDER BY o.order_id
+		FOR UPDATE`
+	rows, err := tx.Query(query, tableId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []model.CheckoutOrder{}
+	for rows.Next() {
+		var order model.CheckoutOrder
+		if err := rows.Scan(&order.OrderId, &order.Status, &order.CreatedAt, &order.Items, &order.Total,
+			&order.Paid, &order.Refunded); err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	return orders, rows.Err()
+}
+
+// FindCheckoutStart returns the open customer session of a table, when the visit started and how
+// many minutes before now. The visit starts with the session, or with the first live order when the
+// table has no session, and the start is empty when it has neither.
+func (r *MySQLRestaurantRepository) FindCheckoutStart(tableId int, now string, tx *sql.Tx) (int, string, int, error) {
+	query := `
+		SELECT COALESCE(s.session_id, 0),
+		       DATE_FORMAT(COALESCE(s.created_at, o.first_at), '%Y/%m/%d %H:%i:%s'),
+		       COALESCE(TIMESTAMPDIFF(MINUTE, COALESCE(s.created_at, o.first_at), ?), 0)
+		FROM (SELECT MIN(created_at) AS first_at FROM orders
+		      WHERE table_id = ? AND is_deleted = FALSE AND checkout_id IS NULL) o
+		LEFT JOIN (
+			SELECT session_id, created_at FROM table_sessions
+			WHERE table_id = ? AND closed_at IS NULL
+			ORDER BY session_id DESC
+			LIMIT 1
+		) s ON TRUE`
+	var sessionId, minutes int
+	var startedAt sql.NullString
+	err := tx.QueryRow(query, now, tableId, tableId).Scan(&sessionId, &startedAt, &minutes)
+	if err != nil {
+		return 0, "", 0, err
+	}
+	return sessionId, startedAt.String, minutes, nil
+}
+
+func (r *MySQLRestaurantRepository) InsertCheckout(c *model.Checkout, userId int, tx *sql.Tx) (int64, error) {
+	insertQuery := `
+		INSERT INTO checkouts (table_id, session_id, started_at, order_count, item_count, total_amount, paid_amount,
+		                       refunded_amount, unpaid_amount, forced, reason, closed_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	result, err := tx.Exec(insertQuery, c.TableId, nullableId(c.SessionId), nullableString(c.StartedAt), c.OrderCount,
+		c.ItemCount, c.TotalAmount, c.PaidAmount, c.RefundedAmount, c.UnpaidAmount, c.Forced, nullableString(c.Reason),
+		nullableId(userId), c.ClosedAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive checkout: %v", err)
+	}
+	return result.LastInsertId()
+}
+
+// ArchiveTableOrders files the live orders of the table under the checkout. The orders stay live so
+// they can still be looked up, refunded and reviewed, but no longer belong to the party at the table.
+func (r *MySQLRestaurantRepository) ArchiveTableOrders(tableId int, checkoutId int, tx *sql.Tx) error {
+	updateQuery := `
+		UPDATE orders
+		SET checkout_id = ?, updated_at = ?
+		WHERE table_id = ? AND is_deleted = FALSE AND checkout_id IS NULL
+	`
+	currentTime := config.FormatTime(time.Now())
+	_, err := tx.Exec(updateQuery, checkoutId, currentTime, tableId)
+	if err != nil {
+		return fmt.Errorf("failed to archive orders: %v", err)
+	}
+	return nil
+}
+
+// CompleteSeatedReservations completes the bookings seated at the table once the party has left.
+func (r *MySQLRestaurantRepository) CompleteSeatedReservations(tableId int, tx *sql.Tx) error {
+	updateQuery := "UPDATE reservations SET status = 'completed', updated_at = ? WHERE table_id = ? AND status = 'seated'"
+	currentTime := config.FormatTime(time.Now())
+	_, err := tx.Exec(updateQuery, currentTime, tableId)
+	if err != nil {
+		return fmt.Errorf("failed to complete reservations: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"Restaurant/config"
+	"Restaurant/database"
+	"Restaurant/internal/model"
+	"Restaurant/internal/request"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type UserRepository interface {
+	FindUserByUsername(username string) (*model.User, error)
+	FindUserById(userId int) (*model.User, error)
+	GetAllUsers() ([]model.User, error)
+	CountUsers() (int, error)
+	InsertUser(r *request.UserRequest, passwordHash string) (int64, error)
+	DeleteUser(userId int) error
+}
+type MySQLUserRepository struct{}
+
+const userSelectQuery = `
+	SELECT user_id, username, password_hash, role, COALESCE(table_id, 0), is_active
+	FROM users
+`
+
+func (r *MySQLUserRepository) FindUserByUsername(username string) (*model.User, error) {
+	query := userSelectQuery + " WHERE username = ? AND is_deleted = FALSE"
+	return scanUser(database.DB.QueryRow(query, username))
+}
+
+func (r *MySQLUserRepository) FindUserById(userId int) (*model.User, error) {
+	query := userSelectQuery + " WHERE user_id = ? AND is_deleted = FALSE"
+	return scanUser(database.DB.QueryRow(query, userId))
+}
+
+func (r *MySQLUserRepository) GetAllUsers() ([]model.User, error) {
+	query := userSelectQuery + " WHERE is_deleted = FALSE ORDER BY user_id"
+	rows, err := database.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []model.User{}
+	for rows.Next() {
+		var user model.User
+		if err := rows.Scan(&user.UserId, &user.Username, &user.PasswordHash, &user.Role, &user.TableId, &user.IsActive); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (r *MySQLUserRepository) CountUsers() (int, error) {
+	var count int
+	err := database.DB.QueryRow("SELECT count(1) FROM users WHERE is_deleted = FALSE").Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *MySQLUserRepository) InsertUser(ur *request.UserRequest, passwordHash string) (int64, error) {
+	insertQuery := `
+		INSERT INTO users (username, password_hash, role, table_id, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	currentTime := config.FormatTime(time.Now())
+	result, err := database.DB.Exec(insertQuery, ur.Username, passwordHash, ur.Role, nullableId(ur.TableId), currentTime)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create user: %v", err)
+	}
+	return result.LastInsertId()
+}
+
+func (r *MySQLUserRepository) DeleteUser(userId int) error {
+	deleteQuery := `
+		UPDATE users
+		SET is_deleted = TRUE, is_active = FALSE, updated_at = ?
+		WHERE user_id = ? AND is_deleted = FALSE
+	`
+	currentTime := config.FormatTime(time.Now())
+	_, err := database.DB.Exec(deleteQuery, currentTime, userId)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %v", err)
+	}
+	return nil
+}
+
+func scanUser(row *sql.Row) (*model.User, error) {
+	var user model.User
+	err := row.Scan(&user.UserId, &user.Username, &user.PasswordHash, &user.Role, &user.TableId, &user.IsActive)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,66 @@
+package request
+
+type BillRequest struct {
+	OrderId int `json:"orderId" query:"orderId" binding:"required"`
+	TableId int `json:"tableId" query:"tableId" binding:"required"`
+}
+
+type ReceiptRequest struct {
+	OrderId   int    `query:"orderId" binding:"required"`
+	TableId   int    `query:"tableId" binding:"required"`
+	Format    string `query:"format"`
+	Width     int    `query:"width"`
+	SessionId int    `json:"-"`
+}
+
+type BillSplitRequest struct {
+	OrderId     int              `json:"orderId" binding:"required"`
+	TableId     int              `json:"tableId" binding:"required"`
+	Mode        string           `json:"mode" binding:"required"`
+	Parts       int              `json:"parts"`
+	Shares      []BillSplitShare `json:"shares"`
+	CouponCodes []string         `json:"couponCodes"`
+}
+
+// BillSplitShare is one guest's part of the bill, OrderItemIds is used by "item" splits and Amount by "custom" splits.
+type BillSplitShare struct {
+	Label        string  `json:"label"`
+	OrderItemIds []int   `json:"orderItemIds"`
+	Amount       float64 `json:"amount"`
+}
+
+type BillPaymentRequest struct {
+	OrderId   int             `json:"orderId" binding:"required"`
+	TableId   int             `json:"tableId" binding:"required"`
+	PaymentId int             `json:"paymentId" binding:"required"`
+	Tenders   []PaymentTender `json:"tenders"`
+}
+
+// PaymentTender is one way a payment is paid, a payment may mix several. Tendered is the
+// cash handed over and only applies to cash, leaving out tenders pays the amount due in exact cash.
+type PaymentTender struct {
+	Method    string  `json:"method" binding:"required"`
+	Amount    float64 `json:"amount"`
+	Tendered  float64 `json:"tendered"`
+	Reference string  `json:"reference"`
+}
+
+// RefundRequest gives money back on a paid order. A void cancels every tender of the bill and is
+// only possible before any refund, a refund without OrderItemIds gives back what is left of the bill.
+type RefundRequest struct {
+	OrderId      int    `json:"orderId" binding:"required"`
+	TableId      int    `json:"tableId" binding:"required"`
+	Kind         string `json:"kind" binding:"required"`
+	OrderItemIds []int  `json:"orderItemIds"`
+	ReasonCode   string `json:"reasonCode" binding:"required"`
+	Note         string `json:"note"`
+	UserId       int    `json:"-"`
+}
+
+// RefundRetryRequest asks the providers again for the tenders of a pending refund that were
+// declined or whose outcome was never recorded.
+type RefundRetryRequest struct {
+	OrderId  int `json:"orderId" binding:"required"`
+	TableId  int `json:"tableId" binding:"required"`
+	RefundId int `json:"refundId" binding:"required"`
+}
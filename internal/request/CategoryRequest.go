@@ -0,0 +1,7 @@
+package request
+
+type CategoryRequest struct {
+	CategoryId int    `json:"categoryId" binding:"required"`
+	Name       string `json:"name" binding:"required"`
+	SortOrder  int    `json:"sortOrder"`
+}
@@ -0,0 +1,30 @@
+package request
+
+import "mime/multipart"
+
+type MenuRequest struct {
+	MenuItemsId int     `json:"menuItemsId" binding:"required"`
+	Name        string  `json:"name" binding:"required"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price" binding:"required"`
+	IsAvailable *bool   `json:"isAvailable"`
+	CategoryId  int     `json:"categoryId"`
+}
+
+type MenuFilterRequest struct {
+	CategoryId    int     `query:"categoryId"`
+	IsAvailable   *bool   `query:"isAvailable"`
+	MinPrice      float64 `query:"minPrice"`
+	MaxPrice      float64 `query:"maxPrice"`
+	Name          string  `query:"name"`
+	Sort          string  `query:"sort"`
+	Order         string  `query:"order"`
+	Page          int     `query:"page"`
+	PageSize      int     `query:"pageSize"`
+	IncludeBase64 bool    `query:"includeBase64"`
+}
+
+type MenuImageRequest struct {
+	MenuItemsId int                   `form:"menuItemsId" binding:"required"`
+	File        *multipart.FileHeader `form:"file" binding:"required"`
+}
@@ -0,0 +1,20 @@
+package request
+
+type OrderItemRequest struct {
+	OrderItemId int    `json:"orderItemId" binding:"required"`
+	Status      string `json:"status" binding:"required"`
+}
+
+type OrderAmendRequest struct {
+	OrderId       int                 `json:"orderId" binding:"required"`
+	TableId       int                 `json:"tableId" binding:"required"`
+	AddItems      []MenuItem          `json:"addItems"`
+	UpdateItems   []OrderItemQuantity `json:"updateItems"`
+	RemoveItemIds []int               `json:"removeItemIds"`
+	SessionId     int                 `json:"-"`
+}
+
+type OrderItemQuantity struct {
+	OrderItemId int `json:"orderItemId" binding:"required"`
+	Quantity    int `json:"quantity" binding:"required,min=1"`
+}
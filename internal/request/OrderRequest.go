@@ -1,14 +1,25 @@
 package request
 
 type OrderRequest struct {
-	OrderId   int        `json:"orderId" binding:"required"`
-	TableId   int        `json:"tableId" binding:"required"`
-	Status    string     `json:"status" binding:"required"`
-	MenuItems []MenuItem `json:"menuItems" binding:"required"`
-	Rating    int        `json:"rating" binding:"required,max=5 min=1"`
-	Comment   string     `json:"comment" binding:"required"`
+	OrderId     int             `json:"orderId" binding:"required"`
+	TableId     int             `json:"tableId" binding:"required"`
+	Status      string          `json:"status" binding:"required"`
+	MenuItems   []MenuItem      `json:"menuItems" binding:"required"`
+	Rating      int             `json:"rating" binding:"required,max=5 min=1"`
+	Comment     string          `json:"comment" binding:"required"`
+	Tenders     []PaymentTender `json:"tenders"`
+	CouponCodes []string        `json:"couponCodes"`
+	ItemReviews []ItemReview    `json:"itemReviews"`
+	SessionId   int             `json:"-"`
 }
 type MenuItem struct {
 	MenuItemID int `json:"menuItemId" binding:"required"`
 	Quantity   int `json:"quantity" binding:"required,min=1"`
 }
+
+// ItemReview rates one dish of a paid order, apart from the rating of the whole order.
+type ItemReview struct {
+	MenuItemId int    `json:"menuItemId" binding:"required"`
+	Rating     int    `json:"rating" binding:"required,max=5 min=1"`
+	Comment    string `json:"comment"`
+}
@@ -0,0 +1,22 @@
+package request
+
+// PromotionRequest describes a promotion rule. Value is a percentage for percent rules and baht
+// for fixed rules, MenuItemId is required by item rules and BOGO. StartsAt and EndsAt use
+// "2006/01/02 15:04:05", HappyHourStart and HappyHourEnd use "15:04" and may wrap past midnight.
+// A promotion with a CouponCode only applies when the code is given. UsageLimit caps how often a coupon
+// is used, 0 means unlimited.
+type PromotionRequest struct {
+	PromotionId    int     `json:"promotionId" binding:"required"`
+	Name           string  `json:"name" binding:"required"`
+	RuleType       string  `json:"ruleType" binding:"required"`
+	Value          float64 `json:"value" binding:"required"`
+	MenuItemId     int     `json:"menuItemId"`
+	MinSubtotal    float64 `json:"minSubtotal"`
+	StartsAt       string  `json:"startsAt"`
+	EndsAt         string  `json:"endsAt"`
+	HappyHourStart string  `json:"happyHourStart"`
+	HappyHourEnd   string  `json:"happyHourEnd"`
+	CouponCode     string  `json:"couponCode"`
+	UsageLimit     int     `json:"usageLimit"`
+	IsActive       *bool   `json:"isActive"`
+}
@@ -0,0 +1,10 @@
+package request
+
+// ReportRequest picks the dates of a report as YYYY-MM-DD, both inclusive. Without dates a
+// report covers the last 30 days up to today.
+type ReportRequest struct {
+	From    string `query:"from"`
+	To      string `query:"to"`
+	GroupBy string `query:"groupBy"`
+	Format  string `query:"format"`
+}
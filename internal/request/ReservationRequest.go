@@ -0,0 +1,27 @@
+package request
+
+// ReservationRequest books a table. ReservedAt is local time as "2006-01-02 15:04", the table may be
+// assigned later and DurationMinutes falls back to RESERVATION_DURATION.
+type ReservationRequest struct {
+	ReservationId   int    `json:"reservationId"`
+	GuestName       string `json:"guestName" binding:"required"`
+	Phone           string `json:"phone" binding:"required"`
+	PartySize       int    `json:"partySize" binding:"required"`
+	ReservedAt      string `json:"reservedAt" binding:"required"`
+	DurationMinutes int    `json:"durationMinutes"`
+	TableId         int    `json:"tableId"`
+	Note            string `json:"note"`
+	UserId          int    `json:"-"`
+}
+
+type ReservationStatusRequest struct {
+	ReservationId int    `json:"reservationId" binding:"required"`
+	Status        string `json:"status" binding:"required"`
+}
+
+// ReservationFilterRequest lists the reservations of one local day, today when Date is empty.
+type ReservationFilterRequest struct {
+	Date    string `query:"date"`
+	Status  string `query:"status"`
+	TableId int    `query:"tableId"`
+}
@@ -0,0 +1,35 @@
+package request
+
+// ReviewFilterRequest narrows the reviews listed or summarized. From and To are dates as
+// YYYY-MM-DD, both inclusive. Visibility is visible (default), hidden or all, deleted reviews
+// are never returned. Kind is order or dish, both are listed when it is empty.
+type ReviewFilterRequest struct {
+	Rating     int    `query:"rating"`
+	MinRating  int    `query:"minRating"`
+	MaxRating  int    `query:"maxRating"`
+	From       string `query:"from"`
+	To         string `query:"to"`
+	OrderId    int    `query:"orderId"`
+	TableId    int    `query:"tableId"`
+	MenuItemId int    `query:"menuItemId"`
+	Kind       string `query:"kind"`
+	Visibility string `query:"visibility"`
+	Replied    *bool  `query:"replied"`
+	Order      string `query:"order"`
+	Page       int    `query:"page"`
+	PageSize   int    `query:"pageSize"`
+}
+
+type ReviewReplyRequest struct {
+	ReviewId int    `json:"reviewId" binding:"required"`
+	Reply    string `json:"reply" binding:"required"`
+	UserId   int    `json:"-"`
+}
+
+// ReviewModerationRequest hides or shows a review, or deletes it through /review/delete.
+type ReviewModerationRequest struct {
+	ReviewId int    `json:"reviewId" binding:"required"`
+	Hidden   bool   `json:"hidden"`
+	Note     string `json:"note"`
+	UserId   int    `json:"-"`
+}
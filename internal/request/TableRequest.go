@@ -1,6 +1,40 @@
 package request
 
+// TableRequest identifies a table. Capacity, Zone, X and Y place it on the floor plan when the
+// table is created or its layout is changed.
 type TableRequest struct {
 	TableId     int    `json:"tableId" binding:"required"`
+	TableNumber int    `json:"tableNumber"`
 	TableStatus string `json:"tableStatus" binding:"required"`
+	Capacity    int    `json:"capacity"`
+	Zone        string `json:"zone"`
+	X           int    `json:"x"`
+	Y           int    `json:"y"`
+}
+
+// TableSuggestionRequest looks for a free table for a party, optionally in one zone.
+type TableSuggestionRequest struct {
+	PartySize int    `query:"partySize"`
+	Zone      string `query:"zone"`
+}
+
+// TableTransferRequest moves a party and all its orders to a free table.
+type TableTransferRequest struct {
+	FromTableId int `json:"fromTableId" binding:"required"`
+	ToTableId   int `json:"toTableId" binding:"required"`
+}
+
+// TableGroupRequest merges TableIds into TableId so they are billed together, or splits them off
+// again. A split without TableIds breaks up the whole group.
+type TableGroupRequest struct {
+	TableId  int   `json:"tableId" binding:"required"`
+	TableIds []int `json:"tableIds"`
+}
+
+// CheckoutRequest closes a table. Force closes it even with unpaid orders and requires a Reason.
+type CheckoutRequest struct {
+	TableId int    `json:"tableId" binding:"required"`
+	Force   bool   `json:"force"`
+	Reason  string `json:"reason"`
+	UserId  int    `json:"-"`
 }
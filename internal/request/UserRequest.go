@@ -0,0 +1,14 @@
+package request
+
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type UserRequest struct {
+	UserId   int    `json:"userId" binding:"required"`
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Role     string `json:"role" binding:"required"`
+	TableId  int    `json:"tableId"`
+}
@@ -0,0 +1,17 @@
+package request
+
+type WaitlistRequest struct {
+	PartyName string `json:"partyName" binding:"required"`
+	Phone     string `json:"phone" binding:"required"`
+	PartySize int    `json:"partySize" binding:"required"`
+	Note      string `json:"note"`
+	UserId    int    `json:"-"`
+}
+
+// WaitlistStatusRequest seats a party or takes it off the waitlist. TableId defaults to the table
+// suggested to a notified party, or else the best fitting free table.
+type WaitlistStatusRequest struct {
+	WaitlistId int    `json:"waitlistId" binding:"required"`
+	Status     string `json:"status" binding:"required"`
+	TableId    int    `json:"tableId"`
+}
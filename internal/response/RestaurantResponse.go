@@ -1,7 +1,14 @@
 package response
 
 type CustomResponse struct {
-	Code    string `json:"code"`           // Status code, e.g., "S0000", "E9999"
-	Message string `json:"message"`        // Message describing the status
-	Data    any    `json:"data,omitempty"` // Data field, will be omitted if empty
+	Code    string  `json:"code"`             // Status code, e.g., "S0000", "E9999"
+	Message string  `json:"message"`          // Message describing the status
+	Data    any     `json:"data,omitempty"`   // Data field, will be omitted if empty
+	Paging  *Paging `json:"paging,omitempty"` // Paging of a list in Data, omitted for unpaged responses
+}
+
+type Paging struct {
+	Page     int `json:"page"`
+	PageSize int `json:"pageSize"`
+	Total    int `json:"total"`
 }
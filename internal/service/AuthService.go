@@ -0,0 +1,257 @@
+package service
+
+import (
+	"Restaurant/config"
+	"Restaurant/internal/model"
+	"Restaurant/internal/repository"
+	"Restaurant/internal/request"
+	"Restaurant/internal/response"
+	"Restaurant/utils/enums"
+	"Restaurant/utils/security"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type AuthService struct {
+	UserRepo       repository.UserRepository
+	RestaurantRepo repository.RestaurantRepository
+	Secret         []byte
+	TokenTTL       time.Duration
+}
+
+func (s *AuthService) Login(r *request.LoginRequest) (response.CustomResponse, int) {
+	log.Println("AuthService -> Login")
+	//check input
+	if strings.TrimSpace(r.Username) == "" || r.Password == "" {
+		log.Println("AuthService -> " + enums.Invalid.GetMessage() + ", Username and password must not be empty.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Username and password must not be empty.",
+		}, http.StatusBadRequest
+	}
+	user, err := s.UserRepo.FindUserByUsername(strings.TrimSpace(r.Username))
+	if err != nil {
+		log.Printf("Service error fetching user: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	// Same answer for unknown users and wrong passwords
+	if user == nil || !user.IsActive || !security.CheckPassword(user.PasswordHash, r.Password) {
+		log.Println("AuthService -> " + enums.Unauthorized.GetMessage() + ", Invalid username or password.")
+		return response.CustomResponse{
+			Code:    enums.Unauthorized.GetCode(),
+			Message: enums.Unauthorized.GetMessage() + ", Invalid username or password.",
+		}, http.StatusUnauthorized
+	}
+	now := time.Now()
+	expiresAt := now.Add(s.TokenTTL)
+	token, err := security.SignToken(security.Claims{
+		UserId:    user.UserId,
+		Username:  user.Username,
+		Role:      user.Role,
+		TableId:   user.TableId,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	}, s.Secret)
+	if err != nil {
+		log.Println("AuthService -> Error signing token:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data: model.LoginToken{
+			Token:     token,
+			ExpiresAt: config.FormatTime(expiresAt),
+			User:      *user,
+		},
+	}, http.StatusOK
+}
+
+func (s *AuthService) GetAllUsers() (response.CustomResponse, int) {
+	log.Println("AuthService -> GetAllUsers")
+	users, err := s.UserRepo.GetAllUsers()
+	if err != nil {
+		log.Printf("Service error fetching users: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data:    users,
+	}, http.StatusOK
+}
+
+func (s *AuthService) CreateUser(r *request.UserRequest) (response.CustomResponse, int) {
+	log.Println("AuthService -> CreateUser")
+	//check input
+	r.Username = strings.TrimSpace(r.Username)
+	if r.Username == "" || len(r.Username) > 50 {
+		log.Println("AuthService -> " + enums.Invalid.GetMessage() + ", Username must be between 1 and 50 characters.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Username must be between 1 and 50 characters.",
+		}, http.StatusBadRequest
+	}
+	// bcrypt ignores everything after 72 bytes
+	if len(r.Password) < 8 || len(r.Password) > 72 {
+		log.Println("AuthService -> " + enums.Invalid.GetMessage() + ", Password must be between 8 and 72 characters.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Password must be between 8 and 72 characters.",
+		}, http.StatusBadRequest
+	}
+	if !enums.IsRole(r.Role) {
+		log.Println("AuthService -> " + enums.Invalid.GetMessage() + ", Role must be one of admin, cashier, kitchen, table.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Role must be one of admin, cashier, kitchen, table.",
+		}, http.StatusBadRequest
+	}
+	if r.Role == enums.RoleTable {
+		if r.TableId <= 0 {
+			log.Println("AuthService -> " + enums.Invalid.GetMessage() + ", Table ID is required for table users.")
+			return response.CustomResponse{
+				Code:    enums.Invalid.GetCode(),
+				Message: enums.Invalid.GetMessage() + ", Table ID is required for table users.",
+			}, http.StatusBadRequest
+		}
+		exists, err := s.RestaurantRepo.FindTableById(&request.OrderRequest{TableId: r.TableId})
+		if err != nil {
+			log.Printf("Service error fetching table: %v", err)
+			return response.CustomResponse{
+				Code:    enums.Error.GetCode(),
+				Message: enums.Error.GetMessage(),
+			}, http.StatusInternalServerError
+		}
+		if !exists {
+			log.Println("AuthService -> " + enums.NotFound.GetMessage() + ", Table ID not found.")
+			return response.CustomResponse{
+				Code:    enums.NotFound.GetCode(),
+				Message: enums.NotFound.GetMessage() + ", Table ID " + fmt.Sprint(r.TableId) + " not found.",
+			}, http.StatusNotFound
+		}
+	} else {
+		r.TableId = 0
+	}
+	existing, err := s.UserRepo.FindUserByUsername(r.Username)
+	if err != nil {
+		log.Printf("Service error fetching user: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	if existing != nil {
+		log.Println("AuthService -> " + enums.Invalid.GetMessage() + ", Username already exists.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Username " + r.Username + " already exists.",
+		}, http.StatusBadRequest
+	}
+	passwordHash, err := security.HashPassword(r.Password)
+	if err != nil {
+		log.Println("AuthService -> Error hashing password:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	userId, err := s.UserRepo.InsertUser(r, passwordHash)
+	if err != nil {
+		log.Println("AuthService -> Error creating user:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data: model.User{
+			UserId:   int(userId),
+			Username: r.Username,
+			Role:     r.Role,
+			TableId:  r.TableId,
+			IsActive: true,
+		},
+	}, http.StatusOK
+}
+
+func (s *AuthService) DeleteUser(r *request.UserRequest, currentUserId int) (response.CustomResponse, int) {
+	log.Println("AuthService -> DeleteUser")
+	//check input
+	if r.UserId <= 0 {
+		log.Println("AuthService -> " + enums.Invalid.GetMessage() + ", User ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", User ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	if r.UserId == currentUserId {
+		log.Println("AuthService -> " + enums.Invalid.GetMessage() + ", Users cannot delete themselves.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Users cannot delete themselves.",
+		}, http.StatusBadRequest
+	}
+	user, err := s.UserRepo.FindUserById(r.UserId)
+	if err != nil {
+		log.Printf("Service error fetching user: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	if user == nil {
+		log.Println("AuthService -> " + enums.NotFound.GetMessage() + ", User ID not found.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", User ID " + fmt.Sprint(r.UserId) + " not found.",
+		}, http.StatusNotFound
+	}
+	err = s.UserRepo.DeleteUser(r.UserId)
+	if err != nil {
+		log.Println("AuthService -> Error deleting user:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+	}, http.StatusOK
+}
+
+// EnsureAdmin creates the first admin account from ADMIN_USERNAME/ADMIN_PASSWORD
+// when the users table is empty, so a fresh database is not locked out.
+func (s *AuthService) EnsureAdmin(username string, password string) {
+	count, err := s.UserRepo.CountUsers()
+	if err != nil {
+		log.Fatalf("Failed to count users: %v", err)
+	}
+	if count > 0 {
+		return
+	}
+	if username == "" || password == "" {
+		log.Println("AuthService -> No users found, set ADMIN_USERNAME and ADMIN_PASSWORD to create the first admin")
+		return
+	}
+	resp, status := s.CreateUser(&request.UserRequest{Username: username, Password: password, Role: enums.RoleAdmin})
+	if status != http.StatusOK {
+		log.Fatalf("Failed to create admin user: %s", resp.Message)
+	}
+	log.Printf("Admin user %s created", username)
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"Restaurant/internal/model"
+	"math"
+)
+
+// BillPolicy turns the sum of the order items into the amounts printed on the bill.
+// Rates are percentages and RoundingStep is in satang, 0 or 1 leaves the total as is.
+type BillPolicy struct {
+	ServiceChargeRate float64
+	VatRate           float64
+	VatInclusive      bool
+	RoundingStep      int64
+}
+
+// Apply fills the breakdown of bill from subtotal and discount, all amounts are in satang.
+// The service charge is added on the discounted subtotal, VAT is then charged on that plus service
+// charge or, when prices already include VAT, only extracted from it for display.
+func (p BillPolicy) Apply(bill *model.Bill, subtotal int64, discount int64) {
+	discount = min(discount, subtotal)
+	serviceCharge := percentOf(subtotal-discount, p.ServiceChargeRate)
+	taxable := subtotal - discount + serviceCharge
+	var vat, total int64
+	if p.VatInclusive {
+		vat = int64(math.Round(float64(taxable) * p.VatRate / (100 + p.VatRate)))
+		total = taxable
+	} else {
+		vat = percentOf(taxable, p.VatRate)
+		total = taxable + vat
+	}
+	rounded := total
+	if p.RoundingStep > 1 {
+		rounded = int64(math.Round(float64(total)/float64(p.RoundingStep))) * p.RoundingStep
+	}
+
+	bill.Subtotal = fromCents(subtotal)
+	bill.Discount = fromCents(discount)
+	bill.ServiceChargeRate = p.ServiceChargeRate
+	bill.ServiceCharge = fromCents(serviceCharge)
+	bill.VatRate = p.VatRate
+	bill.VatInclusive = p.VatInclusive
+	bill.VatAmount = fromCents(vat)
+	bill.Rounding = fromCents(rounded - total)
+	bill.TotalAmount = fromCents(rounded)
+}
+
+// allocateLines spreads the bill total over the items in proportion to what is left of each line
+// after item promotions, so order discounts, service charge, VAT and rounding fall on the items
+// that carry them. The last line that was not free absorbs the leftover satang.
+func allocateLines(items []model.BillItem, lineLeft map[int]int64, total int64) {
+	var discounted int64
+	last := len(items) - 1
+	for i, item := range items {
+		discounted += lineLeft[item.OrderItemId]
+		if lineLeft[item.OrderItemId] > 0 {
+			last = i
+		}
+	}
+	var allocated int64
+	for i := range items {
+		var amount int64
+		switch {
+		case i == last:
+			amount = total - allocated
+		case discounted > 0:
+			amount = lineLeft[items[i].OrderItemId] * total / discounted
+		}
+		allocated += amount
+		items[i].Charged = fromCents(amount)
+	}
+}
+
+func percentOf(amount int64, rate float64) int64 {
+	return int64(math.Round(float64(amount) * rate / 100))
+}
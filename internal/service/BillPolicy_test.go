@@ -0,0 +1,122 @@
+package service
+
+import (
+	"Restaurant/internal/model"
+	"reflect"
+	"testing"
+)
+
+func TestAllocateLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		lineLeft map[int]int64
+		total    int64
+		want     []float64
+	}{
+		{name: "follows the discounted lines", lineLeft: map[int]int64{1: 10000, 2: 5000, 3: 5000}, total: 21400, want: []float64{107, 53.5, 53.5}},
+		{name: "bogo line carries less", lineLeft: map[int]int64{1: 10000, 2: 5000, 3: 0}, total: 17655, want: []float64{117.70, 58.85, 0}},
+		{name: "last paying line takes the leftover", lineLeft: map[int]int64{1: 1000, 2: 1000, 3: 1000}, total: 3001, want: []float64{10, 10, 10.01}},
+		{name: "everything free", lineLeft: map[int]int64{1: 0, 2: 0, 3: 0}, total: 0, want: []float64{0, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := []model.BillItem{{OrderItemId: 1}, {OrderItemId: 2}, {OrderItemId: 3}}
+			allocateLines(items, tt.lineLeft, tt.total)
+			var got []float64
+			for _, item := range items {
+				got = append(got, item.Charged)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("allocateLines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBillPolicyApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   BillPolicy
+		subtotal int64
+		discount int64
+		want     model.Bill
+	}{
+		{
+			name:     "vat exclusive",
+			policy:   BillPolicy{VatRate: 7},
+			subtotal: 10000,
+			want:     model.Bill{Subtotal: 100, VatRate: 7, VatAmount: 7, TotalAmount: 107},
+		},
+		{
+			name:     "vat exclusive with service charge",
+			policy:   BillPolicy{ServiceChargeRate: 10, VatRate: 7},
+			subtotal: 10000,
+			want:     model.Bill{Subtotal: 100, ServiceChargeRate: 10, ServiceCharge: 10, VatRate: 7, VatAmount: 7.70, TotalAmount: 117.70},
+		},
+		{
+			name:     "vat inclusive",
+			policy:   BillPolicy{VatRate: 7, VatInclusive: true},
+			subtotal: 10000,
+			want:     model.Bill{Subtotal: 100, VatRate: 7, VatInclusive: true, VatAmount: 6.54, TotalAmount: 100},
+		},
+		{
+			name:     "vat inclusive with service charge",
+			policy:   BillPolicy{ServiceChargeRate: 10, VatRate: 7, VatInclusive: true},
+			subtotal: 10000,
+			want:     model.Bill{Subtotal: 100, ServiceChargeRate: 10, ServiceCharge: 10, VatRate: 7, VatInclusive: true, VatAmount: 7.20, TotalAmount: 110},
+		},
+		{
+			name:     "service charge on the discounted subtotal",
+			policy:   BillPolicy{ServiceChargeRate: 10, VatRate: 7},
+			subtotal: 10000,
+			discount: 2000,
+			want:     model.Bill{Subtotal: 100, Discount: 20, ServiceChargeRate: 10, ServiceCharge: 8, VatRate: 7, VatAmount: 6.16, TotalAmount: 94.16},
+		},
+		{
+			name:     "discount capped at the subtotal",
+			policy:   BillPolicy{ServiceChargeRate: 10, VatRate: 7},
+			subtotal: 5000,
+			discount: 6000,
+			want:     model.Bill{Subtotal: 50, Discount: 50, ServiceChargeRate: 10, VatRate: 7},
+		},
+		{
+			name:     "rounding step of one satang",
+			policy:   BillPolicy{VatRate: 7, RoundingStep: 1},
+			subtotal: 12355,
+			want:     model.Bill{Subtotal: 123.55, VatRate: 7, VatAmount: 8.65, TotalAmount: 132.20},
+		},
+		{
+			name:     "rounding up to a quarter baht",
+			policy:   BillPolicy{VatRate: 7, RoundingStep: 25},
+			subtotal: 12355,
+			want:     model.Bill{Subtotal: 123.55, VatRate: 7, VatAmount: 8.65, Rounding: 0.05, TotalAmount: 132.25},
+		},
+		{
+			name:     "rounding down to a quarter baht",
+			policy:   BillPolicy{VatRate: 7, RoundingStep: 25},
+			subtotal: 12345,
+			want:     model.Bill{Subtotal: 123.45, VatRate: 7, VatAmount: 8.64, Rounding: -0.09, TotalAmount: 132},
+		},
+		{
+			name:     "rounding to a whole baht",
+			policy:   BillPolicy{ServiceChargeRate: 10, VatRate: 7, RoundingStep: 100},
+			subtotal: 12355,
+			want:     model.Bill{Subtotal: 123.55, ServiceChargeRate: 10, ServiceCharge: 12.36, VatRate: 7, VatAmount: 9.51, Rounding: -0.42, TotalAmount: 145},
+		},
+		{
+			name:     "rounding a vat inclusive total",
+			policy:   BillPolicy{ServiceChargeRate: 10, VatRate: 7, VatInclusive: true, RoundingStep: 100},
+			subtotal: 12355,
+			want:     model.Bill{Subtotal: 123.55, ServiceChargeRate: 10, ServiceCharge: 12.36, VatRate: 7, VatInclusive: true, VatAmount: 8.89, Rounding: 0.09, TotalAmount: 136},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got model.Bill
+			tt.policy.Apply(&got, tt.subtotal, tt.discount)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"Restaurant/config"
+	"Restaurant/internal/model"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	KitchenOrderCreated       = "order.created"
+	KitchenOrderStatusChanged = "order.status_changed"
+	KitchenOrderCanceled      = "order.canceled"
+	KitchenOrderUpdated       = "order.updated"
+	KitchenItemStatusChanged  = "order_item.status_changed"
+)
+
+// KitchenFeed fans order events out to every connected kitchen display.
+// Displays that fall behind miss events rather than blocking order handling.
+type KitchenFeed struct {
+	mu          sync.Mutex
+	subscribers map[chan model.KitchenEvent]struct{}
+}
+
+func NewKitchenFeed() *KitchenFeed {
+	return &KitchenFeed{subscribers: make(map[chan model.KitchenEvent]struct{})}
+}
+
+func (f *KitchenFeed) Subscribe() (<-chan model.KitchenEvent, func()) {
+	ch := make(chan model.KitchenEvent, 32)
+	f.mu.Lock()
+	f.subscribers[ch] = struct{}{}
+	f.mu.Unlock()
+	return ch, func() {
+		f.mu.Lock()
+		delete(f.subscribers, ch)
+		f.mu.Unlock()
+	}
+}
+
+func (f *KitchenFeed) Publish(eventType string, orderId int, tableId int, status string) {
+	f.publish(model.KitchenEvent{
+		Type:    eventType,
+		OrderId: orderId,
+		TableId: tableId,
+		Status:  status,
+	})
+}
+
+func (f *KitchenFeed) PublishItem(orderItemId int, orderId int, tableId int, status string) {
+	f.publish(model.KitchenEvent{
+		Type:        KitchenItemStatusChanged,
+		OrderId:     orderId,
+		OrderItemId: orderItemId,
+		TableId:     tableId,
+		Status:      status,
+	})
+}
+
+func (f *KitchenFeed) publish(event model.KitchenEvent) {
+	if f == nil {
+		return
+	}
+	event.OccurredAt = config.FormatTime(time.Now())
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for ch := range f.subscribers {
+		select {
+		case ch <- event:
+		default:
+			log.Println("KitchenFeed -> Subscriber is full, dropping event", event.Type, "for order", event.OrderId)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"Restaurant/internal/model"
+	"Restaurant/utils/enums"
+	"sort"
+	"strings"
+	"time"
+)
+
+// applyPromotions works out what each promotion takes off the bill items. Item rules and BOGO
+// go first and never take more than what is left of a line, order rules then apply to what is
+// left of the subtotal. Promotions outside their happy hour, under their minimum subtotal or
+// matching no item are left out. It also returns what is left of each line after the item rules.
+// Amounts are in satang.
+func applyPromotions(items []model.BillItem, promotions []model.Promotion, now time.Time) ([]model.AppliedPromotion, map[int]int64) {
+	var subtotal int64
+	lineLeft := make(map[int]int64)
+	for _, item := range items {
+		subtotal += toCents(item.Amount)
+		lineLeft[item.OrderItemId] = toCents(item.Amount)
+	}
+	ordered := make([]model.Promotion, len(promotions))
+	copy(ordered, promotions)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return enums.IsItemPromotion(ordered[i].RuleType) && !enums.IsItemPromotion(ordered[j].RuleType)
+	})
+
+	remaining := subtotal
+	var applied []model.AppliedPromotion
+	for _, promotion := range ordered {
+		if subtotal < toCents(promotion.MinSubtotal) || !inHappyHour(promotion, now) {
+			continue
+		}
+		var discount int64
+		switch promotion.RuleType {
+		case enums.PromotionItemPercent, enums.PromotionItemFixed:
+			for _, item := range items {
+				if item.MenuItemId != promotion.MenuItemId {
+					continue
+				}
+				lineDiscount := percentOf(toCents(item.Amount), promotion.Value)
+				if promotion.RuleType == enums.PromotionItemFixed {
+					lineDiscount = toCents(promotion.Value) * int64(item.Quantity)
+				}
+				lineDiscount = min(lineDiscount, lineLeft[item.OrderItemId])
+				lineLeft[item.OrderItemId] -= lineDiscount
+				discount += lineDiscount
+			}
+		case enums.PromotionBogo:
+			discount = applyBogo(items, promotion.MenuItemId, lineLeft)
+		case enums.PromotionPercent:
+			discount = percentOf(remaining, promotion.Value)
+		case enums.PromotionFixed:
+			discount = min(toCents(promotion.Value), remaining)
+		}
+		if discount <= 0 {
+			continue
+		}
+		remaining -= discount
+		applied = append(applied, model.AppliedPromotion{
+			PromotionId: promotion.PromotionId,
+			Name:        promotion.Name,
+			RuleType:    promotion.RuleType,
+			CouponCode:  promotion.CouponCode,
+			Discount:    fromCents(discount),
+		})
+	}
+	return applied, lineLeft
+}
+
+// applyBogo gives every second unit of the menu item for free, starting with the cheapest units.
+func applyBogo(items []model.BillItem, menuItemId int, lineLeft map[int]int64) int64 {
+	var lines []model.BillItem
+	var quantity int
+	for _, item := range items {
+		if item.MenuItemId == menuItemId {
+			lines = append(lines, item)
+			quantity += item.Quantity
+		}
+	}
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].Price < lines[j].Price })
+	free := quantity / 2
+	var discount int64
+	for _, line := range lines {
+		if free == 0 {
+			break
+		}
+		units := min(free, line.Quantity)
+		free -= units
+		lineDiscount := min(toCents(line.Price)*int64(units), lineLeft[line.OrderItemId])
+		lineLeft[line.OrderItemId] -= lineDiscount
+		discount += lineDiscount
+	}
+	return discount
+}
+
+// inHappyHour reports whether now falls in the promotion's daily window, windows that end
+// before they start run past midnight. Promotions without a window always apply.
+func inHappyHour(promotion model.Promotion, now time.Time) bool {
+	if promotion.HappyHourStart == "" || promotion.HappyHourEnd == "" {
+		return true
+	}
+	start, err := time.Parse("15:04", promotion.HappyHourStart)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse("15:04", promotion.HappyHourEnd)
+	if err != nil {
+		return false
+	}
+	minute := now.Hour()*60 + now.Minute()
+	startMinute := start.Hour()*60 + start.Minute()
+	endMinute := end.Hour()*60 + end.Minute()
+	if startMinute <= endMinute {
+		return minute >= startMinute && minute < endMinute
+	}
+	return minute >= startMinute || minute < endMinute
+}
+
+// normalizeCouponCodes trims, upper-cases and de-duplicates coupon codes.
+func normalizeCouponCodes(codes []string) []string {
+	seen := make(map[string]bool)
+	var normalized []string
+	for _, code := range codes {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+		normalized = append(normalized, code)
+	}
+	return normalized
+}
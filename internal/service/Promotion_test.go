@@ -0,0 +1,154 @@
+package service
+
+import (
+	"Restaurant/internal/model"
+	"Restaurant/utils/enums"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var promotionItems = []model.BillItem{
+	{OrderItemId: 1, MenuItemId: 10, Quantity: 2, Price: 100, Amount: 200},
+	{OrderItemId: 2, MenuItemId: 20, Quantity: 1, Price: 50, Amount: 50},
+}
+
+func applied(promotion model.Promotion, discount float64) model.AppliedPromotion {
+	return model.AppliedPromotion{
+		PromotionId: promotion.PromotionId,
+		Name:        promotion.Name,
+		RuleType:    promotion.RuleType,
+		CouponCode:  promotion.CouponCode,
+		Discount:    discount,
+	}
+}
+
+func TestApplyPromotions(t *testing.T) {
+	noon := time.Date(2026, 10, 18, 12, 0, 0, 0, time.Local)
+	percent := model.Promotion{PromotionId: 1, Name: "10% off", RuleType: enums.PromotionPercent, Value: 10}
+	fixed := model.Promotion{PromotionId: 2, Name: "300 off", RuleType: enums.PromotionFixed, Value: 300}
+	itemPercent := model.Promotion{PromotionId: 3, Name: "Half price", RuleType: enums.PromotionItemPercent, Value: 50, MenuItemId: 10}
+	itemFixed := model.Promotion{PromotionId: 4, Name: "60 off", RuleType: enums.PromotionItemFixed, Value: 60, MenuItemId: 20}
+	bogo := model.Promotion{PromotionId: 5, Name: "BOGO", RuleType: enums.PromotionBogo, MenuItemId: 10}
+	coupon := model.Promotion{PromotionId: 6, Name: "Coupon", RuleType: enums.PromotionFixed, Value: 20, CouponCode: "SAVE20"}
+	minSubtotal := percent
+	minSubtotal.MinSubtotal = 300
+	happyHour := percent
+	happyHour.HappyHourStart, happyHour.HappyHourEnd = "17:00", "19:00"
+	otherItem := itemPercent
+	otherItem.MenuItemId = 99
+
+	tests := []struct {
+		name       string
+		promotions []model.Promotion
+		want       []model.AppliedPromotion
+	}{
+		{name: "order percent", promotions: []model.Promotion{percent}, want: []model.AppliedPromotion{applied(percent, 25)}},
+		{name: "order fixed capped at subtotal", promotions: []model.Promotion{fixed}, want: []model.AppliedPromotion{applied(fixed, 250)}},
+		{name: "item percent", promotions: []model.Promotion{itemPercent}, want: []model.AppliedPromotion{applied(itemPercent, 100)}},
+		{name: "item fixed capped at line", promotions: []model.Promotion{itemFixed}, want: []model.AppliedPromotion{applied(itemFixed, 50)}},
+		{name: "bogo", promotions: []model.Promotion{bogo}, want: []model.AppliedPromotion{applied(bogo, 100)}},
+		{
+			name:       "item rules before order rules",
+			promotions: []model.Promotion{percent, itemPercent},
+			want:       []model.AppliedPromotion{applied(itemPercent, 100), applied(percent, 15)},
+		},
+		{
+			name:       "item rules share a line",
+			promotions: []model.Promotion{itemPercent, bogo},
+			want:       []model.AppliedPromotion{applied(itemPercent, 100), applied(bogo, 100)},
+		},
+		{name: "coupon", promotions: []model.Promotion{coupon}, want: []model.AppliedPromotion{applied(coupon, 20)}},
+		{name: "under min subtotal", promotions: []model.Promotion{minSubtotal}},
+		{name: "outside happy hour", promotions: []model.Promotion{happyHour}},
+		{name: "no matching item", promotions: []model.Promotion{otherItem}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := applyPromotions(promotionItems, tt.promotions, noon)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("applyPromotions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyBogo(t *testing.T) {
+	tests := []struct {
+		name     string
+		items    []model.BillItem
+		lineLeft map[int]int64
+		want     int64
+	}{
+		{
+			name:     "single unit",
+			items:    []model.BillItem{{OrderItemId: 1, MenuItemId: 10, Quantity: 1, Price: 100, Amount: 100}},
+			lineLeft: map[int]int64{1: 10000},
+			want:     0,
+		},
+		{
+			name: "cheapest units go free",
+			items: []model.BillItem{
+				{OrderItemId: 1, MenuItemId: 10, Quantity: 1, Price: 120, Amount: 120},
+				{OrderItemId: 2, MenuItemId: 10, Quantity: 2, Price: 80, Amount: 160},
+				{OrderItemId: 3, MenuItemId: 20, Quantity: 4, Price: 10, Amount: 40},
+			},
+			lineLeft: map[int]int64{1: 12000, 2: 16000, 3: 4000},
+			want:     8000,
+		},
+		{
+			name: "free units spread over lines",
+			items: []model.BillItem{
+				{OrderItemId: 1, MenuItemId: 10, Quantity: 3, Price: 120, Amount: 360},
+				{OrderItemId: 2, MenuItemId: 10, Quantity: 1, Price: 80, Amount: 80},
+			},
+			lineLeft: map[int]int64{1: 36000, 2: 8000},
+			want:     20000,
+		},
+		{
+			name:     "capped at what is left of the line",
+			items:    []model.BillItem{{OrderItemId: 1, MenuItemId: 10, Quantity: 2, Price: 100, Amount: 200}},
+			lineLeft: map[int]int64{1: 5000},
+			want:     5000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applyBogo(tt.items, 10, tt.lineLeft); got != tt.want {
+				t.Errorf("applyBogo() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInHappyHour(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, 10, 18, hour, minute, 0, 0, time.Local)
+	}
+	evening := model.Promotion{HappyHourStart: "17:00", HappyHourEnd: "19:00"}
+	overnight := model.Promotion{HappyHourStart: "22:00", HappyHourEnd: "02:00"}
+	tests := []struct {
+		name      string
+		promotion model.Promotion
+		now       time.Time
+		want      bool
+	}{
+		{name: "no window", promotion: model.Promotion{}, now: at(3, 0), want: true},
+		{name: "start", promotion: evening, now: at(17, 0), want: true},
+		{name: "inside", promotion: evening, now: at(18, 30), want: true},
+		{name: "before", promotion: evening, now: at(16, 59), want: false},
+		{name: "end is excluded", promotion: evening, now: at(19, 0), want: false},
+		{name: "overnight before midnight", promotion: overnight, now: at(23, 30), want: true},
+		{name: "overnight after midnight", promotion: overnight, now: at(1, 0), want: true},
+		{name: "overnight end", promotion: overnight, now: at(2, 0), want: false},
+		{name: "overnight midday", promotion: overnight, now: at(12, 0), want: false},
+		{name: "invalid window", promotion: model.Promotion{HappyHourStart: "5pm", HappyHourEnd: "19:00"}, now: at(18, 0), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inHappyHour(tt.promotion, tt.now); got != tt.want {
+				t.Errorf("inHappyHour() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"Restaurant/config"
+	"Restaurant/database"
+	"database/sql"
+	"log"
+	"time"
+)
+
+// ReservationPolicy decides how long a booking keeps its table, how long before the booking
+// the table is held and how late the party may be before it counts as a no-show.
+type ReservationPolicy struct {
+	Duration time.Duration
+	Hold     time.Duration
+	Grace    time.Duration
+}
+
+// RunReservationSweeper keeps table statuses in line with the bookings every interval, so
+// tables are reserved ahead of their bookings and released once a party does not show up.
+func (s *RestaurantService) RunReservationSweeper(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.sweepReservations(); err != nil {
+			log.Println("RestaurantService -> Error sweeping reservations:", err)
+		}
+		<-ticker.C
+	}
+}
+
+func (s *RestaurantService) sweepReservations() error {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return err
+	}
+	if err := s.syncReservations(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// syncReservations applies the reservation policy at the current time inside tx.
+func (s *RestaurantService) syncReservations(tx *sql.Tx) error {
+	now := time.Now()
+	noShows, err := s.RestaurantRepo.SyncReservedTables(config.FormatTime(now.Add(s.Reservations.Hold)),
+		config.FormatTime(now.Add(-s.Reservations.Grace)), tx)
+	if err != nil {
+		return err
+	}
+	if noShows > 0 {
+		log.Printf("RestaurantService -> Marked %d reservations as no-show", noShows)
+	}
+	return nil
+}
@@ -1,22 +1,61 @@
 package service
 
 import (
+	"Restaurant/config"
 	"Restaurant/database"
+	"Restaurant/internal/model"
+	"Restaurant/internal/payment"
+	"Restaurant/internal/receipt"
+	"Restaurant/internal/report"
 	"Restaurant/internal/repository"
 	"Restaurant/internal/request"
 	"Restaurant/internal/response"
+	"Restaurant/internal/storage"
 	"Restaurant/utils/enums"
+	"Restaurant/utils/security"
+	"bytes"
+	"context"
+	"database/sql"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"github.com/labstack/echo/v4"
+	"io"
 	"log"
+	"math"
 	"net/http"
-	"os"
+	"net/url"
+	"slices"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 type RestaurantService struct {
-	RestaurantRepo repository.RestaurantRepository
+	RestaurantRepo   repository.RestaurantRepository
+	ImageStorage     storage.ImageStorage
+	TokenSecret      []byte
+	TableSessionTTL  time.Duration
+	OrderBaseURL     string
+	KitchenFeed      *KitchenFeed
+	PaymentProviders payment.Registry
+	BillPolicy       BillPolicy
+	ReceiptTitle     string
+	ReceiptFont      *receipt.Font
+	ReceiptCodePage  byte
+	Reservations     ReservationPolicy
+}
+
+const maxMenuImageSize = 5 << 20
+
+const maxBillSplitParts = 20
+
+const paymentChargeTimeout = 30 * time.Second
+
+var allowedMenuImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
 }
 
 func HealthyCheck(c echo.Context) error {
@@ -58,6 +97,14 @@ func (s *RestaurantService) FindTable(r *request.TableRequest) (response.CustomR
 			Message: enums.Invalid.GetMessage() + ", Table ID " + fmt.Sprint(r.TableId) + " is already occupied.",
 		}, http.StatusBadRequest
 	}
+	// A reserved table is held for its booking, the party is seated through /reservation/status
+	if tableStatus == enums.TableReserved {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table ID " + fmt.Sprint(r.TableId) + " is reserved.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Table ID " + fmt.Sprint(r.TableId) + " is reserved.",
+		}, http.StatusBadRequest
+	}
 	return response.CustomResponse{
 		Code:    enums.Success.GetCode(),
 		Message: enums.Success.GetMessage(),
@@ -80,7 +127,14 @@ func (s *RestaurantService) UpdateTable(r *request.TableRequest) (response.Custo
 			Message: enums.Invalid.GetMessage() + ", Table status must not be empty.",
 		}, http.StatusBadRequest
 	}
-	exists, _, err := s.RestaurantRepo.FindTableByTableRequestId(r)
+	if r.TableStatus != "available" && r.TableStatus != "occupied" {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table status must be available or occupied.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Table status must be available or occupied.",
+		}, http.StatusBadRequest
+	}
+	exists, tableStatus, err := s.RestaurantRepo.FindTableByTableRequestId(r)
 	if err != nil {
 		log.Printf("Service error fetching table: %v", err)
 		return response.CustomResponse{
@@ -95,23 +149,97 @@ func (s *RestaurantService) UpdateTable(r *request.TableRequest) (response.Custo
 			Message: enums.NotFound.GetMessage() + ", Table ID " + fmt.Sprint(r.TableId) + " not found.",
 		}, http.StatusNotFound
 	}
-	err = s.RestaurantRepo.UpdateTable(r)
+	// A reserved table is held for its booking, the party is seated through /reservation/status
+	if r.TableStatus == enums.TableOccupied && tableStatus == enums.TableReserved {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table ID " + fmt.Sprint(r.TableId) + " is reserved.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Table ID " + fmt.Sprint(r.TableId) + " is reserved, seat the booking through /reservation/status.",
+		}, http.StatusBadRequest
+	}
+	// Occupying it again would end the seated party's session and its QR code with it
+	if r.TableStatus == enums.TableOccupied && tableStatus == enums.TableOccupied {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table ID " + fmt.Sprint(r.TableId) + " is already occupied.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Table ID " + fmt.Sprint(r.TableId) + " is already occupied.",
+		}, http.StatusBadRequest
+	}
+	// Freeing the table by hand would leave the party's orders on it for the next party
+	if r.TableStatus == enums.TableAvailable {
+		liveOrders, err := s.RestaurantRepo.CountLiveOrdersByTable(r.TableId)
+		if err != nil {
+			log.Printf("Service error counting live orders: %v", err)
+			return response.CustomResponse{
+				Code:    enums.Error.GetCode(),
+				Message: enums.Error.GetMessage(),
+			}, http.StatusInternalServerError
+		}
+		if liveOrders > 0 {
+			log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Table has orders, check it out through /table/checkout.")
+			return response.CustomResponse{
+				Code:    enums.InvalidTransition.GetCode(),
+				Message: enums.InvalidTransition.GetMessage() + ", Table ID " + fmt.Sprint(r.TableId) + " has orders, check the party out through /table/checkout.",
+			}, http.StatusConflict
+		}
+	}
+	resp, status, err := s.checkTableNotMerged(r.TableId, true)
+	if err != nil {
+		return resp, status
+	}
+	// Start transaction
+	tx, err := database.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	err = s.RestaurantRepo.UpdateTableWithTx(r.TableId, r.TableStatus, tx)
+	var tableSession *model.TableSession
+	if err == nil && r.TableStatus != enums.TableOccupied {
+		err = s.RestaurantRepo.CloseTableSessionsWithTx(r.TableId, tx)
+	} else if err == nil {
+		// Occupying a table starts a new customer session, the token is shown as a QR code
+		tableSession, err = s.openTableSession(r.TableId, tx)
+	}
 	if err != nil {
+		tx.Rollback()
 		log.Println("RestaurantService -> Error updating table:", err)
 		return response.CustomResponse{
 			Code:    enums.Error.GetCode(),
 			Message: enums.Error.GetMessage(),
 		}, http.StatusInternalServerError
 	}
+	err = tx.Commit()
+	if err != nil {
+		log.Println("RestaurantService -> Error committing transaction:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	log.Println("Transaction committed successfully")
+	if tableSession == nil {
+		// A freed table goes to an upcoming booking or is suggested to the next waiting party
+		return s.freedTableResponse(r.TableId)
+	}
 	return response.CustomResponse{
 		Code:    enums.Success.GetCode(),
 		Message: enums.Success.GetMessage(),
+		Data:    tableSession,
 	}, http.StatusOK
 }
 
-func (s *RestaurantService) GetAllMenu() (response.CustomResponse, int) {
+func (s *RestaurantService) GetAllMenu(f *request.MenuFilterRequest) (response.CustomResponse, int) {
 	log.Println("RestaurantService -> GetAllMenu")
-	menus, err := s.RestaurantRepo.GetAllMenu()
+	//check input
+	resp, status, err := validateMenuFilterRequest(f)
+	if err != nil {
+		return resp, status
+	}
+	menus, total, err := s.RestaurantRepo.GetAllMenu(f)
 	if err != nil {
 		log.Printf("Service error fetching menus: %v", err)
 		return response.CustomResponse{
@@ -121,8 +249,11 @@ func (s *RestaurantService) GetAllMenu() (response.CustomResponse, int) {
 	}
 	for i, menu := range menus {
 		for j, fileObject := range menu.FileObjects {
-			filePath := fmt.Sprintf("K:\\IdeaProjects\\GoLand\\26Sep\\Restaurant\\assets\\images\\%s", fileObject.FileName)
-			base64Content, err := s.convertFileToBase64(filePath)
+			menus[i].FileObjects[j].Url = s.ImageStorage.URL(fileObject.FileName)
+			if !f.IncludeBase64 {
+				continue
+			}
+			base64Content, err := s.convertFileToBase64(fileObject.FileName)
 			if err != nil {
 				log.Printf("Error converting file to base64: %v", err)
 				return response.CustomResponse{
@@ -133,10 +264,19 @@ func (s *RestaurantService) GetAllMenu() (response.CustomResponse, int) {
 			menus[i].FileObjects[j].Base64 = base64Content
 		}
 	}
+	pageSize := f.PageSize
+	if pageSize <= 0 {
+		pageSize = total
+	}
 	return response.CustomResponse{
 		Code:    enums.Success.GetCode(),
 		Message: enums.Success.GetMessage(),
-		Data:    menus}, http.StatusOK
+		Data:    menus,
+		Paging: &response.Paging{
+			Page:     f.Page,
+			PageSize: pageSize,
+			Total:    total,
+		}}, http.StatusOK
 }
 
 func (s *RestaurantService) OrderMenu(c *request.OrderRequest) (response.CustomResponse, int) {
@@ -171,21 +311,10 @@ func (s *RestaurantService) OrderMenu(c *request.OrderRequest) (response.CustomR
 	if err != nil {
 		return resp, status
 	}
-	//find menu id
-	notFoundItems, err := s.RestaurantRepo.FindMenuItemById(c.MenuItems)
+	// A merged table is billed with its group, its orders go on the group's main table
+	resp, status, err = s.checkTableNotMerged(c.TableId, false)
 	if err != nil {
-		return response.CustomResponse{
-			Code:    enums.Error.GetCode(),
-			Message: enums.Error.GetMessage(),
-		}, http.StatusInternalServerError
-	}
-	if len(notFoundItems) > 0 {
-		joinIDS := joinWithComma(notFoundItems)
-		log.Println("RestaurantService -> "+enums.NotFound.GetMessage()+", MenuItem IDs not found: ", joinIDS)
-		return response.CustomResponse{
-			Code:    enums.NotFound.GetCode(),
-			Message: enums.NotFound.GetMessage() + ", MenuItem IDs not found: " + joinIDS,
-		}, http.StatusNotFound
+		return resp, status
 	}
 	// Start transaction
 	tx, err := database.DB.Begin()
@@ -196,6 +325,12 @@ func (s *RestaurantService) OrderMenu(c *request.OrderRequest) (response.CustomR
 			Message: enums.Error.GetMessage(),
 		}, http.StatusInternalServerError
 	}
+	//find menu id
+	resp, status, err = s.checkMenuItems(c.MenuItems, tx)
+	if err != nil {
+		tx.Rollback()
+		return resp, status
+	}
 	orderId, err := s.RestaurantRepo.InsertOrder(c, tx)
 	if err != nil {
 		tx.Rollback()
@@ -223,6 +358,7 @@ func (s *RestaurantService) OrderMenu(c *request.OrderRequest) (response.CustomR
 		}, http.StatusInternalServerError
 	}
 	log.Println("Transaction committed successfully")
+	s.KitchenFeed.Publish(KitchenOrderCreated, int(orderId), c.TableId, enums.OrderCreated)
 	return response.CustomResponse{
 		Code:    enums.Success.GetCode(),
 		Message: enums.Success.GetMessage(),
@@ -263,82 +399,84 @@ func (s *RestaurantService) UpdateOrder(r *request.OrderRequest) (response.Custo
 	if err != nil {
 		return respOrder, status
 	}
-	err = s.RestaurantRepo.UpdateOrder(r.TableId, r.OrderId, r.Status)
-	if err != nil {
-		log.Println("RestaurantService -> Error updating order:", err)
-	}
-	return response.CustomResponse{
-		Code:    enums.Success.GetCode(),
-		Message: enums.Success.GetMessage(),
-	}, http.StatusOK
-}
-
-func (s *RestaurantService) DeleteOrder(r *request.OrderRequest) (response.CustomResponse, int) {
-	log.Println("RestaurantService -> DeleteOrder")
-	//check input
-	if r.TableId <= 0 {
-		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table ID must be greater than 0.")
+	if !enums.IsOrderStatus(r.Status) {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Status " + r.Status + " is not a valid order status.")
 		return response.CustomResponse{
 			Code:    enums.Invalid.GetCode(),
-			Message: enums.Invalid.GetMessage() + ", Table ID must be greater than 0.",
+			Message: enums.Invalid.GetMessage() + ", Status " + r.Status + " is not a valid order status.",
 		}, http.StatusBadRequest
 	}
-	if r.OrderId <= 0 {
-		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Order ID must be greater than 0.")
+	// Paying needs a bill, so it only happens through PayOrder
+	if r.Status == enums.OrderPaid {
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Orders can only be paid through /order/pay.")
 		return response.CustomResponse{
-			Code:    enums.Invalid.GetCode(),
-			Message: enums.Invalid.GetMessage() + ", Order ID must be greater than 0.",
-		}, http.StatusBadRequest
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Orders can only be paid through /order/pay.",
+		}, http.StatusConflict
 	}
-	//find table id
-	resp, status, err := s.CheckTableId(r)
-	if err != nil {
-		return resp, status
+	if r.Status == enums.OrderRefunded {
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Orders can only be refunded through /order/refund.")
+		return response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Orders can only be refunded through /order/refund.",
+		}, http.StatusConflict
 	}
-	//find order id
-	respOrder, status, err := s.CheckOrderId(r)
+	// Start transaction
+	tx, err := database.DB.Begin()
 	if err != nil {
-		return respOrder, status
+		log.Printf("Error starting transaction: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
 	}
-	err = s.RestaurantRepo.DeleteOrder(r)
+	respTransition, status, err := s.lockOrderTransition(r.OrderId, r.TableId, r.Status, tx)
 	if err != nil {
-		log.Println("RestaurantService -> Error deleting order:", err)
+		tx.Rollback()
+		return respTransition, status
 	}
-	return response.CustomResponse{
-		Code:    enums.Success.GetCode(),
-		Message: enums.Success.GetMessage(),
-	}, http.StatusOK
-}
-
-func (s *RestaurantService) DeleteAllOrderWhenCheckOut(r *request.TableRequest) (response.CustomResponse, int) {
-	log.Println("RestaurantService -> DeleteAllOrderWhenCheckOut")
-	//check input
-	if r.TableId <= 0 {
-		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table ID must be greater than 0.")
-		return response.CustomResponse{
-			Code:    enums.Invalid.GetCode(),
-			Message: enums.Invalid.GetMessage() + ", Table ID must be greater than 0.",
-		}, http.StatusBadRequest
+	// Cooking progress comes from the items, so it cannot get ahead of the kitchen
+	if r.Status == enums.OrderPrepare || r.Status == enums.OrderCompleted {
+		itemStatuses, err := s.RestaurantRepo.GetOrderItemStatuses(r.OrderId, tx)
+		if err != nil {
+			tx.Rollback()
+			log.Println("RestaurantService -> Error getting order item statuses:", err)
+			return response.CustomResponse{
+				Code:    enums.Error.GetCode(),
+				Message: enums.Error.GetMessage(),
+			}, http.StatusInternalServerError
+		}
+		if len(itemStatuses) > 0 {
+			tx.Rollback()
+			log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Order status follows its items, update them through /order/item/update.")
+			return response.CustomResponse{
+				Code:    enums.InvalidTransition.GetCode(),
+				Message: enums.InvalidTransition.GetMessage() + ", Order ID " + fmt.Sprint(r.OrderId) + " is " + r.Status + " once its items are, update them through /order/item/update.",
+			}, http.StatusConflict
+		}
 	}
-	//find table id
-	exists, _, err := s.RestaurantRepo.FindTableByTableRequestId(r)
+	err = s.RestaurantRepo.UpdateOrderWithTx(r.TableId, r.OrderId, r.Status, tx)
 	if err != nil {
-		log.Printf("Service error fetching table: %v", err)
+		tx.Rollback()
+		log.Println("RestaurantService -> Error updating order:", err)
 		return response.CustomResponse{
 			Code:    enums.Error.GetCode(),
 			Message: enums.Error.GetMessage(),
 		}, http.StatusInternalServerError
 	}
-	if !exists {
-		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Table ID not found.")
+	err = tx.Commit()
+	if err != nil {
+		log.Println("RestaurantService -> Error committing transaction:", err)
 		return response.CustomResponse{
-			Code:    enums.NotFound.GetCode(),
-			Message: enums.NotFound.GetMessage() + ", Table ID " + fmt.Sprint(r.TableId) + " not found.",
-		}, http.StatusNotFound
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
 	}
-	err = s.RestaurantRepo.DeleteAllOrderWhenCheckOut(r)
-	if err != nil {
-		log.Println("RestaurantService -> Error deleting all orders:", err)
+	log.Println("Transaction committed successfully")
+	if r.Status == enums.OrderCanceled {
+		s.KitchenFeed.Publish(KitchenOrderCanceled, r.OrderId, r.TableId, r.Status)
+	} else {
+		s.KitchenFeed.Publish(KitchenOrderStatusChanged, r.OrderId, r.TableId, r.Status)
 	}
 	return response.CustomResponse{
 		Code:    enums.Success.GetCode(),
@@ -346,8 +484,8 @@ func (s *RestaurantService) DeleteAllOrderWhenCheckOut(r *request.TableRequest)
 	}, http.StatusOK
 }
 
-func (s *RestaurantService) PayOrder(r *request.OrderRequest) (response.CustomResponse, int) {
-	log.Println("RestaurantService -> PayOrder")
+func (s *RestaurantService) DeleteOrder(r *request.OrderRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> DeleteOrder")
 	//check input
 	if r.TableId <= 0 {
 		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table ID must be greater than 0.")
@@ -382,39 +520,16 @@ func (s *RestaurantService) PayOrder(r *request.OrderRequest) (response.CustomRe
 			Message: enums.Error.GetMessage(),
 		}, http.StatusInternalServerError
 	}
-	// Check order status
-	statusOrder, err := s.RestaurantRepo.CheckOrderStatus(r, tx)
-	if err != nil {
-		tx.Rollback()
-		log.Println("RestaurantService -> Error checking order status:", err)
-		return response.CustomResponse{
-			Code:    enums.NotFound.GetCode(),
-			Message: "Order not found or already deleted.",
-		}, http.StatusNotFound
-	}
-
-	// Check if status is not "completed"
-	if statusOrder != "completed" {
-		tx.Rollback()
-		log.Println("RestaurantService -> Order is not in 'completed' status")
-		return response.CustomResponse{
-			Code:    enums.Invalid.GetCode(),
-			Message: enums.Invalid.GetMessage() + ", Order is not completed. Cannot proceed with payment.",
-		}, http.StatusBadRequest
-	}
-	err = s.RestaurantRepo.PayOrder(r, tx)
+	respTransition, status, err := s.lockOrderTransition(r.OrderId, r.TableId, enums.OrderCanceled, tx)
 	if err != nil {
 		tx.Rollback()
-		log.Println("RestaurantService -> Error paying order:", err)
-		return response.CustomResponse{
-			Code:    enums.Error.GetCode(),
-			Message: enums.Error.GetMessage(),
-		}, http.StatusInternalServerError
+		return respTransition, status
 	}
-	err = s.RestaurantRepo.UpdateOrderWithTx(r.TableId, r.OrderId, "paid", tx)
+	r.Status = enums.OrderCanceled
+	err = s.RestaurantRepo.DeleteOrder(r, tx)
 	if err != nil {
 		tx.Rollback()
-		log.Println("RestaurantService -> Error updating order:", err)
+		log.Println("RestaurantService -> Error deleting order:", err)
 		return response.CustomResponse{
 			Code:    enums.Error.GetCode(),
 			Message: enums.Error.GetMessage(),
@@ -429,33 +544,34 @@ func (s *RestaurantService) PayOrder(r *request.OrderRequest) (response.CustomRe
 		}, http.StatusInternalServerError
 	}
 	log.Println("Transaction committed successfully")
+	s.KitchenFeed.Publish(KitchenOrderCanceled, r.OrderId, r.TableId, enums.OrderCanceled)
 	return response.CustomResponse{
 		Code:    enums.Success.GetCode(),
 		Message: enums.Success.GetMessage(),
 	}, http.StatusOK
 }
 
-func (s *RestaurantService) ReviewOrder(r *request.OrderRequest) (response.CustomResponse, int) {
+// CheckoutTable closes a party's visit. Every order must be paid, canceled or refunded unless the
+// checkout is forced with a reason. The orders are archived under the checkout, the QR session is
+// closed and the table, with any tables merged into it, is freed for the next party.
+func (s *RestaurantService) CheckoutTable(r *request.CheckoutRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> CheckoutTable")
 	//check input
-	if r.OrderId <= 0 {
-		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Order ID must be greater than 0.")
+	if r.TableId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table ID must be greater than 0.")
 		return response.CustomResponse{
 			Code:    enums.Invalid.GetCode(),
-			Message: enums.Invalid.GetMessage() + ", Order ID must be greater than 0.",
+			Message: enums.Invalid.GetMessage() + ", Table ID must be greater than 0.",
 		}, http.StatusBadRequest
 	}
-	if r.Rating < 1 || r.Rating > 5 {
-		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Rating must be between 1 and 5.")
+	r.Reason = strings.TrimSpace(r.Reason)
+	if r.Force && (r.Reason == "" || utf8.RuneCountInString(r.Reason) > 255) {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", A forced checkout needs a reason of at most 255 characters.")
 		return response.CustomResponse{
 			Code:    enums.Invalid.GetCode(),
-			Message: enums.Invalid.GetMessage() + ", Rating must be between 1 and 5.",
+			Message: enums.Invalid.GetMessage() + ", A forced checkout needs a reason of at most 255 characters.",
 		}, http.StatusBadRequest
 	}
-	//find order id
-	respOrder, status, err := s.CheckOrderId(r)
-	if err != nil {
-		return respOrder, status
-	}
 	// Start transaction
 	tx, err := database.DB.Begin()
 	if err != nil {
@@ -465,48 +581,115 @@ func (s *RestaurantService) ReviewOrder(r *request.OrderRequest) (response.Custo
 			Message: enums.Error.GetMessage(),
 		}, http.StatusInternalServerError
 	}
-	// Check order status
-	statusOrder, err := s.RestaurantRepo.CheckOrderStatus(r, tx)
+	tables, resp, status, err := s.lockTableStates([]int{r.TableId}, tx)
 	if err != nil {
 		tx.Rollback()
-		log.Println("RestaurantService -> Error checking order status:", err)
+		return resp, status
+	}
+	table := tables[r.TableId]
+	if table.MergedInto != 0 {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Table is merged into another table.")
 		return response.CustomResponse{
-			Code:    enums.NotFound.GetCode(),
-			Message: "Order not found or already deleted.",
-		}, http.StatusNotFound
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Table ID " + fmt.Sprint(r.TableId) + " is merged into table ID " + fmt.Sprint(table.MergedInto) + ", check out that table instead.",
+		}, http.StatusConflict
 	}
-
-	// Check if status is not "completed"
-	if statusOrder != "paid" {
+	members, err := s.RestaurantRepo.GetGroupMembers(r.TableId, tx)
+	if err != nil {
 		tx.Rollback()
-		log.Println("RestaurantService -> Order is not in 'paid' status")
+		log.Println("RestaurantService -> Error finding merged tables:", err)
 		return response.CustomResponse{
-			Code:    enums.Invalid.GetCode(),
-			Message: enums.Invalid.GetMessage() + ", Order is not paid. Cannot proceed with payment.",
-		}, http.StatusBadRequest
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
 	}
-	// Check if the order has already been reviewed
-	hasReviewed, err := s.RestaurantRepo.HasOrderBeenReviewed(r, tx)
+	orders, err := s.RestaurantRepo.GetCheckoutOrders(r.TableId, tx)
 	if err != nil {
 		tx.Rollback()
-		log.Println("RestaurantService -> Error checking if order has been reviewed:", err)
+		log.Println("RestaurantService -> Error fetching table orders:", err)
 		return response.CustomResponse{
 			Code:    enums.Error.GetCode(),
 			Message: enums.Error.GetMessage(),
 		}, http.StatusInternalServerError
 	}
-	if hasReviewed {
+	if table.TableStatus != enums.TableOccupied && len(orders) == 0 {
 		tx.Rollback()
-		log.Println("RestaurantService -> Order has already been reviewed")
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Table has no party to check out.")
 		return response.CustomResponse{
-			Code:    enums.Invalid.GetCode(),
-			Message: enums.Invalid.GetMessage() + ", Order has already been reviewed.",
-		}, http.StatusBadRequest
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Table ID " + fmt.Sprint(r.TableId) + " is " + table.TableStatus + " and has no orders to check out.",
+		}, http.StatusConflict
+	}
+	var totalCents, paidCents, refundedCents, dueCents int64
+	unpaid := []int{}
+	items := 0
+	for i := range orders {
+		order := &orders[i]
+		switch order.Status {
+		case enums.OrderCreated, enums.OrderPrepare, enums.OrderCompleted:
+			due := max(toCents(order.Total)-toCents(order.Paid), 0)
+			order.Due = fromCents(due)
+			dueCents += due
+			unpaid = append(unpaid, order.OrderId)
+		}
+		totalCents += toCents(order.Total)
+		paidCents += toCents(order.Paid)
+		refundedCents += toCents(order.Refunded)
+		items += order.Items
+	}
+	if len(unpaid) > 0 && !r.Force {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Table has unpaid orders.")
+		return response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Order ID " + joinWithComma(unpaid) + " not paid yet, " + fmt.Sprintf("%.2f", fromCents(dueCents)) + " due.",
+			Data:    orders,
+		}, http.StatusConflict
+	}
+	now := time.Now()
+	checkout := &model.Checkout{
+		TableId:        r.TableId,
+		ClosedAt:       config.FormatTime(now),
+		OrderCount:     len(orders),
+		ItemCount:      items,
+		TotalAmount:    fromCents(totalCents),
+		PaidAmount:     fromCents(paidCents),
+		RefundedAmount: fromCents(refundedCents),
+		UnpaidAmount:   fromCents(dueCents),
+		Forced:         len(unpaid) > 0,
+		Orders:         orders,
+		MergedTables:   members,
+	}
+	if checkout.Forced {
+		checkout.Reason = r.Reason
+	}
+	checkout.SessionId, checkout.StartedAt, checkout.DurationMinutes, err = s.RestaurantRepo.FindCheckoutStart(r.TableId, checkout.ClosedAt, tx)
+	var checkoutId int64
+	if err == nil {
+		checkoutId, err = s.RestaurantRepo.InsertCheckout(checkout, r.UserId, tx)
+	}
+	if err == nil {
+		checkout.CheckoutId = int(checkoutId)
+		err = s.RestaurantRepo.ArchiveTableOrders(r.TableId, checkout.CheckoutId, tx)
+	}
+	for _, tableId := range append([]int{r.TableId}, members...) {
+		if err == nil && tableId != r.TableId {
+			err = s.RestaurantRepo.SetTableMergedInto(tableId, 0, tx)
+		}
+		if err == nil {
+			err = s.RestaurantRepo.CloseTableSessionsWithTx(tableId, tx)
+		}
+		if err == nil {
+			err = s.RestaurantRepo.CompleteSeatedReservations(tableId, tx)
+		}
+		if err == nil {
+			err = s.RestaurantRepo.UpdateTableWithTx(tableId, enums.TableAvailable, tx)
+		}
 	}
-	err = s.RestaurantRepo.ReviewOrder(r, tx)
 	if err != nil {
 		tx.Rollback()
-		log.Println("RestaurantService -> Error reviewing order:", err)
+		log.Println("RestaurantService -> Error checking out table:", err)
 		return response.CustomResponse{
 			Code:    enums.Error.GetCode(),
 			Message: enums.Error.GetMessage(),
@@ -521,14 +704,25 @@ func (s *RestaurantService) ReviewOrder(r *request.OrderRequest) (response.Custo
 		}, http.StatusInternalServerError
 	}
 	log.Println("Transaction committed successfully")
+	if checkout.Forced {
+		log.Println("RestaurantService -> Table", r.TableId, "force checked out with", joinWithComma(unpaid), "unpaid:", r.Reason)
+	}
+	// The checkout is committed, handing the freed tables over is best effort
+	for _, tableId := range append([]int{r.TableId}, members...) {
+		move := s.freedTableMove(tableId)
+		if checkout.NextParty == nil {
+			checkout.NextParty = move.NextParty
+		}
+	}
 	return response.CustomResponse{
 		Code:    enums.Success.GetCode(),
 		Message: enums.Success.GetMessage(),
+		Data:    checkout,
 	}, http.StatusOK
 }
 
-func (s *RestaurantService) OrderDetails(r *request.OrderRequest) (response.CustomResponse, int) {
-	log.Println("RestaurantService -> OrderDetails")
+func (s *RestaurantService) PayOrder(r *request.OrderRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> PayOrder")
 	//check input
 	if r.TableId <= 0 {
 		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table ID must be greater than 0.")
@@ -544,8 +738,12 @@ func (s *RestaurantService) OrderDetails(r *request.OrderRequest) (response.Cust
 			Message: enums.Invalid.GetMessage() + ", Order ID must be greater than 0.",
 		}, http.StatusBadRequest
 	}
+	resp, status, err := validatePaymentTenders(r.Tenders)
+	if err != nil {
+		return resp, status
+	}
 	//find table id
-	resp, status, err := s.CheckTableId(r)
+	resp, status, err = s.CheckTableId(r)
 	if err != nil {
 		return resp, status
 	}
@@ -554,101 +752,5219 @@ func (s *RestaurantService) OrderDetails(r *request.OrderRequest) (response.Cust
 	if err != nil {
 		return respOrder, status
 	}
-	orderDetails, err := s.RestaurantRepo.GetOrderDetails(r)
+	// Start transaction
+	tx, err := database.DB.Begin()
 	if err != nil {
-		log.Println("RestaurantService -> Error getting order details:", err)
+		log.Printf("Error starting transaction: %v", err)
 		return response.CustomResponse{
 			Code:    enums.Error.GetCode(),
 			Message: enums.Error.GetMessage(),
 		}, http.StatusInternalServerError
 	}
-	return response.CustomResponse{
-		Code:    enums.Success.GetCode(),
-		Message: enums.Success.GetMessage(),
-		Data:    orderDetails,
-	}, http.StatusOK
-}
-
-func (s *RestaurantService) OrderHistory(r *request.OrderRequest) (response.CustomResponse, int) {
-	log.Println("RestaurantService -> OrderHistory")
-	//check input
-	if r.TableId <= 0 {
-		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table ID must be greater than 0.")
+	orderStatus, _, err := s.RestaurantRepo.LockOrder(r.OrderId, r.TableId, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error locking order:", err)
 		return response.CustomResponse{
-			Code:    enums.Invalid.GetCode(),
-			Message: enums.Invalid.GetMessage() + ", Table ID must be greater than 0.",
-		}, http.StatusBadRequest
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
 	}
-	//find table id
+	if orderStatus == "" {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Order ID not found.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Order ID " + fmt.Sprint(r.OrderId) + " not found.",
+		}, http.StatusNotFound
+	}
+
+	// Check if the order may move to "paid"
+	respTransition, status, err := checkOrderTransition(orderStatus, enums.OrderPaid)
+	if err != nil {
+		tx.Rollback()
+		return respTransition, status
+	}
+	bill, respBill, status, err := s.openBill(r.OrderId, r.TableId, r.CouponCodes, tx)
+	if err != nil {
+		tx.Rollback()
+		return respBill, status
+	}
+	// Paying the order in one go drops any split share nobody has paid yet
+	err = s.RestaurantRepo.CancelPendingPayments(bill.BillId, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error canceling pending payments:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	remaining := toCents(bill.TotalAmount) - toCents(bill.PaidAmount)
+	tenders, respTender, status, err := matchTenders(remaining, r.Tenders)
+	if err != nil {
+		tx.Rollback()
+		return respTender, status
+	}
+	if remaining == 0 {
+		// Nothing is left to charge, the order is paid straight away
+		err = s.RestaurantRepo.UpdateOrderWithTx(r.TableId, r.OrderId, enums.OrderPaid, tx)
+		if err != nil {
+			tx.Rollback()
+			log.Println("RestaurantService -> Error updating order:", err)
+			return response.CustomResponse{
+				Code:    enums.Error.GetCode(),
+				Message: enums.Error.GetMessage(),
+			}, http.StatusInternalServerError
+		}
+		bill, err = s.loadBill(r.OrderId, tx)
+		if err != nil {
+			tx.Rollback()
+			log.Println("RestaurantService -> Error loading bill:", err)
+			return response.CustomResponse{
+				Code:    enums.Error.GetCode(),
+				Message: enums.Error.GetMessage(),
+			}, http.StatusInternalServerError
+		}
+		err = tx.Commit()
+		if err != nil {
+			log.Println("RestaurantService -> Error committing transaction:", err)
+			return response.CustomResponse{
+				Code:    enums.Error.GetCode(),
+				Message: enums.Error.GetMessage(),
+			}, http.StatusInternalServerError
+		}
+		log.Println("Transaction committed successfully")
+		return response.CustomResponse{
+			Code:    enums.Success.GetCode(),
+			Message: enums.Success.GetMessage(),
+			Data:    bill,
+		}, http.StatusOK
+	}
+	paymentId, err := s.RestaurantRepo.InsertPayment(&model.Payment{
+		BillId:    bill.BillId,
+		Label:     "Full bill",
+		SplitMode: enums.SplitFull,
+		Amount:    fromCents(remaining),
+	}, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error creating payment:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Println("RestaurantService -> Error committing transaction:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	log.Println("Transaction committed successfully")
+
+	// Providers are charged once the pending payment is committed, so no lock is held while they answer
+	charged, respTender, status, err := s.collectTenders(remaining, tenders)
+	if err != nil {
+		s.cancelPayment(int(paymentId))
+		return respTender, status
+	}
+	return s.settleTenders(r.OrderId, r.TableId, int(paymentId), charged)
+}
+
+func (s *RestaurantService) ReviewOrder(r *request.OrderRequest) (response.CustomResponse, int) {
+	//check input
+	if r.TableId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Table ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	if r.OrderId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Order ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Order ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	// The order rating may be left out when only dishes are rated
+	if (r.Rating != 0 || len(r.ItemReviews) == 0) && (r.Rating < 1 || r.Rating > 5) {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Rating must be between 1 and 5.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Rating must be between 1 and 5.",
+		}, http.StatusBadRequest
+	}
+	resp, status, err := validateItemReviews(r.ItemReviews)
+	if err != nil {
+		return resp, status
+	}
+	//find order id
+	respOrder, status, err := s.CheckOrderId(r)
+	if err != nil {
+		return respOrder, status
+	}
+	// Start transaction
+	tx, err := database.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	// Check order status
+	statusOrder, sessionId, err := s.RestaurantRepo.LockOrder(r.OrderId, r.TableId, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error locking order:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	// Customers can only review orders placed during their own table session
+	if statusOrder == "" || (r.SessionId > 0 && sessionId != r.SessionId) {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Order ID not found.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Order ID " + fmt.Sprint(r.OrderId) + " not found.",
+		}, http.StatusNotFound
+	}
+
+	// Check if status is not "paid"
+	if statusOrder != enums.OrderPaid {
+		tx.Rollback()
+		log.Println("RestaurantService -> Order is not in 'paid' status")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Order is not paid. Cannot proceed with payment.",
+		}, http.StatusBadRequest
+	}
+	if r.Rating != 0 {
+		// Check if the order has already been reviewed
+		hasReviewed, err := s.RestaurantRepo.HasOrderBeenReviewed(r, tx)
+		if err != nil {
+			tx.Rollback()
+			log.Println("RestaurantService -> Error checking if order has been reviewed:", err)
+			return response.CustomResponse{
+				Code:    enums.Error.GetCode(),
+				Message: enums.Error.GetMessage(),
+			}, http.StatusInternalServerError
+		}
+		if hasReviewed {
+			tx.Rollback()
+			log.Println("RestaurantService -> Order has already been reviewed")
+			return response.CustomResponse{
+				Code:    enums.Invalid.GetCode(),
+				Message: enums.Invalid.GetMessage() + ", Order has already been reviewed.",
+			}, http.StatusBadRequest
+		}
+		err = s.RestaurantRepo.ReviewOrder(r, tx)
+		if err != nil {
+			tx.Rollback()
+			log.Println("RestaurantService -> Error reviewing order:", err)
+			return response.CustomResponse{
+				Code:    enums.Error.GetCode(),
+				Message: enums.Error.GetMessage(),
+			}, http.StatusInternalServerError
+		}
+	}
+	if len(r.ItemReviews) > 0 {
+		respItems, status, err := s.checkReviewableItems(r.OrderId, r.ItemReviews, tx)
+		if err != nil {
+			tx.Rollback()
+			return respItems, status
+		}
+		err = s.RestaurantRepo.ReviewOrderItems(r.OrderId, r.ItemReviews, tx)
+		if errors.Is(err, repository.ErrAlreadyReviewed) {
+			// another request rated the same dish after the check above
+			tx.Rollback()
+			log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Menu item has already been reviewed on this order.")
+			return response.CustomResponse{
+				Code:    enums.InvalidTransition.GetCode(),
+				Message: enums.InvalidTransition.GetMessage() + ", A menu item has already been reviewed on this order.",
+			}, http.StatusConflict
+		}
+		if err != nil {
+			tx.Rollback()
+			log.Println("RestaurantService -> Error reviewing order items:", err)
+			return response.CustomResponse{
+				Code:    enums.Error.GetCode(),
+				Message: enums.Error.GetMessage(),
+			}, http.StatusInternalServerError
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Println("RestaurantService -> Error committing transaction:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	log.Println("Transaction committed successfully")
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) OrderDetails(r *request.OrderRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> OrderDetails")
+	//check input
+	if r.TableId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Table ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	if r.OrderId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Order ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Order ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	//find table id
+	resp, status, err := s.CheckTableId(r)
+	if err != nil {
+		return resp, status
+	}
+	//find order id
+	respOrder, status, err := s.CheckOrderId(r)
+	if err != nil {
+		return respOrder, status
+	}
+	orderDetails, err := s.RestaurantRepo.GetOrderDetails(r)
+	if err != nil {
+		log.Println("RestaurantService -> Error getting order details:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	// Customers can only see orders placed during their own table session
+	if orderDetails == nil {
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Order ID not found.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Order ID " + fmt.Sprint(r.OrderId) + " not found.",
+		}, http.StatusNotFound
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data:    orderDetails,
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) OrderHistory(r *request.OrderRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> OrderHistory")
+	//check input
+	if r.TableId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Table ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	//find table id
 	resp, status, err := s.CheckTableId(r)
 	if err != nil {
-		return resp, status
+		return resp, status
+	}
+	orders, err := s.RestaurantRepo.GetOrderHistory(r)
+	if err != nil {
+		log.Println("RestaurantService -> Error getting order history:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data:    orders,
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) CreateMenu(r *request.MenuRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> CreateMenu")
+	//check input
+	resp, status, err := validateMenuRequest(r)
+	if err != nil {
+		return resp, status
+	}
+	//find category id
+	if r.CategoryId > 0 {
+		respCategory, status, err := s.CheckCategoryId(r.CategoryId)
+		if err != nil {
+			return respCategory, status
+		}
+	}
+	menuItemId, err := s.RestaurantRepo.InsertMenuItem(r)
+	if err != nil {
+		log.Println("RestaurantService -> Error creating menu item:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	isAvailable := r.IsAvailable == nil || *r.IsAvailable
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data: model.Menus{
+			MenuItemsId: int(menuItemId),
+			Name:        r.Name,
+			Description: r.Description,
+			Price:       r.Price,
+			IsAvailable: isAvailable,
+			CategoryId:  r.CategoryId,
+		},
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) UpdateMenu(r *request.MenuRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> UpdateMenu")
+	//check input
+	if r.MenuItemsId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", MenuItem ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", MenuItem ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	resp, status, err := validateMenuRequest(r)
+	if err != nil {
+		return resp, status
+	}
+	//find category id
+	if r.CategoryId > 0 {
+		respCategory, status, err := s.CheckCategoryId(r.CategoryId)
+		if err != nil {
+			return respCategory, status
+		}
+	}
+	//find menu id
+	respMenu, status, err := s.CheckMenuItemId(r)
+	if err != nil {
+		return respMenu, status
+	}
+	err = s.RestaurantRepo.UpdateMenuItem(r)
+	if err != nil {
+		log.Println("RestaurantService -> Error updating menu item:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) UpdateMenuAvailability(r *request.MenuRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> UpdateMenuAvailability")
+	//check input
+	if r.MenuItemsId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", MenuItem ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", MenuItem ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	if r.IsAvailable == nil {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", isAvailable must not be empty.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", isAvailable must not be empty.",
+		}, http.StatusBadRequest
+	}
+	//find menu id
+	respMenu, status, err := s.CheckMenuItemId(r)
+	if err != nil {
+		return respMenu, status
+	}
+	err = s.RestaurantRepo.UpdateMenuItemAvailability(r)
+	if err != nil {
+		log.Println("RestaurantService -> Error updating menu item availability:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) DeleteMenu(r *request.MenuRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> DeleteMenu")
+	//check input
+	if r.MenuItemsId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", MenuItem ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", MenuItem ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	//find menu id
+	respMenu, status, err := s.CheckMenuItemId(r)
+	if err != nil {
+		return respMenu, status
+	}
+	err = s.RestaurantRepo.DeleteMenuItem(r)
+	if err != nil {
+		log.Println("RestaurantService -> Error deleting menu item:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) UploadMenuImage(r *request.MenuImageRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> UploadMenuImage")
+	//check input
+	if r.MenuItemsId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", MenuItem ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", MenuItem ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	if r.File == nil {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", file must not be empty.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", file must not be empty.",
+		}, http.StatusBadRequest
+	}
+	if r.File.Size > maxMenuImageSize {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", file must not exceed 5 MB.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", file must not exceed 5 MB.",
+		}, http.StatusBadRequest
+	}
+	//find menu id
+	respMenu, status, err := s.CheckMenuItemId(&request.MenuRequest{MenuItemsId: r.MenuItemsId})
+	if err != nil {
+		return respMenu, status
+	}
+	src, err := r.File.Open()
+	if err != nil {
+		log.Println("RestaurantService -> Error opening uploaded file:", err)
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		}, http.StatusBadRequest
+	}
+	defer src.Close()
+	// Detect the real content type instead of trusting the client header
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		log.Println("RestaurantService -> Error reading uploaded file:", err)
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage(),
+		}, http.StatusBadRequest
+	}
+	ext, ok := allowedMenuImageTypes[http.DetectContentType(head[:n])]
+	if !ok {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", file must be a JPEG, PNG or WebP image.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", file must be a JPEG, PNG or WebP image.",
+		}, http.StatusBadRequest
+	}
+	oldFileName, err := s.RestaurantRepo.GetMenuItemImage(r.MenuItemsId)
+	if err != nil {
+		log.Println("RestaurantService -> Error fetching menu item image:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	fileName := fmt.Sprintf("menu-%d-%d%s", r.MenuItemsId, time.Now().UnixNano(), ext)
+	err = s.ImageStorage.Save(fileName, io.MultiReader(bytes.NewReader(head[:n]), src))
+	if err != nil {
+		log.Println("RestaurantService -> Error saving menu image:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	err = s.RestaurantRepo.UpdateMenuItemImage(r.MenuItemsId, fileName)
+	if err != nil {
+		s.ImageStorage.Delete(fileName)
+		log.Println("RestaurantService -> Error updating menu item image:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	// Only remove images uploaded through this endpoint, the seeded ones may be shared
+	if strings.HasPrefix(oldFileName, "menu-") {
+		if err := s.ImageStorage.Delete(oldFileName); err != nil {
+			log.Println("RestaurantService -> Error deleting old menu image:", err)
+		}
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data: model.FileObject{
+			FileName: fileName,
+			Url:      s.ImageStorage.URL(fileName),
+		},
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) GetMenuImage(fileName string) (*storage.ImageFile, response.CustomResponse, int) {
+	log.Println("RestaurantService -> GetMenuImage")
+	image, err := s.ImageStorage.Open(fileName)
+	if err != nil {
+		log.Println("RestaurantService -> Error opening menu image:", err)
+		return nil, response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Image " + fileName + " not found.",
+		}, http.StatusNotFound
+	}
+	return image, response.CustomResponse{}, http.StatusOK
+}
+
+func (s *RestaurantService) GetAllCategories() (response.CustomResponse, int) {
+	log.Println("RestaurantService -> GetAllCategories")
+	categories, err := s.RestaurantRepo.GetAllCategories()
+	if err != nil {
+		log.Printf("Service error fetching categories: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data:    categories,
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) CreateCategory(r *request.CategoryRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> CreateCategory")
+	//check input
+	resp, status, err := validateCategoryRequest(r)
+	if err != nil {
+		return resp, status
+	}
+	categoryId, err := s.RestaurantRepo.InsertCategory(r)
+	if err != nil {
+		log.Println("RestaurantService -> Error creating category:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data: model.Category{
+			CategoryId: int(categoryId),
+			Name:       r.Name,
+			SortOrder:  r.SortOrder,
+		},
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) UpdateCategory(r *request.CategoryRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> UpdateCategory")
+	//check input
+	if r.CategoryId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Category ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Category ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	resp, status, err := validateCategoryRequest(r)
+	if err != nil {
+		return resp, status
+	}
+	//find category id
+	respCategory, status, err := s.CheckCategoryId(r.CategoryId)
+	if err != nil {
+		return respCategory, status
+	}
+	err = s.RestaurantRepo.UpdateCategory(r)
+	if err != nil {
+		log.Println("RestaurantService -> Error updating category:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) DeleteCategory(r *request.CategoryRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> DeleteCategory")
+	//check input
+	if r.CategoryId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Category ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Category ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	//find category id
+	respCategory, status, err := s.CheckCategoryId(r.CategoryId)
+	if err != nil {
+		return respCategory, status
+	}
+	err = s.RestaurantRepo.DeleteCategory(r)
+	if err != nil {
+		log.Println("RestaurantService -> Error deleting category:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) GetAllTables() (response.CustomResponse, int) {
+	log.Println("RestaurantService -> GetAllTables")
+	tables, err := s.RestaurantRepo.GetAllTables()
+	if err != nil {
+		log.Printf("Service error fetching tables: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data:    tables,
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) FindTableByNumber(r *request.TableRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> FindTableByNumber")
+	//check input
+	if r.TableNumber <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table number must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Table number must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	table, err := s.RestaurantRepo.FindTableByNumber(r.TableNumber)
+	if err != nil {
+		log.Printf("Service error fetching table: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	if table == nil {
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Table number not found.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Table number " + fmt.Sprint(r.TableNumber) + " not found.",
+		}, http.StatusNotFound
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data:    table,
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) CreateTable(r *request.TableRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> CreateTable")
+	//check input
+	if r.TableNumber <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table number must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Table number must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	if r.Capacity == 0 {
+		r.Capacity = defaultTableCapacity
+	}
+	if r.Zone == "" {
+		r.Zone = enums.ZoneIndoor
+	}
+	resp, status, err := validateTableLayout(r)
+	if err != nil {
+		return resp, status
+	}
+	resp, status, err = s.CheckTableNumberAvailable(r.TableNumber)
+	if err != nil {
+		return resp, status
+	}
+	// A retired table keeps its number, bring it back instead of inserting a duplicate
+	retiredTableId, err := s.RestaurantRepo.FindRetiredTableByNumber(r.TableNumber)
+	if err != nil {
+		log.Printf("Service error fetching retired table: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	tableId := int64(retiredTableId)
+	if retiredTableId > 0 {
+		err = s.RestaurantRepo.RestoreTable(retiredTableId)
+		if err == nil {
+			r.TableId = retiredTableId
+			err = s.RestaurantRepo.UpdateTableLayout(r)
+		}
+	} else {
+		tableId, err = s.RestaurantRepo.InsertTable(r)
+	}
+	if err != nil {
+		log.Println("RestaurantService -> Error creating table:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data: model.Table{
+			TableId:     int(tableId),
+			TableNumber: r.TableNumber,
+			TableStatus: "available",
+			Capacity:    r.Capacity,
+			Zone:        r.Zone,
+			X:           r.X,
+			Y:           r.Y,
+		},
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) RenumberTable(r *request.TableRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> RenumberTable")
+	//check input
+	if r.TableId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Table ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	if r.TableNumber <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table number must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Table number must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	//find table id
+	exists, _, err := s.RestaurantRepo.FindTableByTableRequestId(r)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("Service error fetching table: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	if !exists {
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Table ID not found.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Table ID " + fmt.Sprint(r.TableId) + " not found.",
+		}, http.StatusNotFound
+	}
+	resp, status, err := s.CheckTableNumberAvailable(r.TableNumber)
+	if err != nil {
+		return resp, status
+	}
+	retiredTableId, err := s.RestaurantRepo.FindRetiredTableByNumber(r.TableNumber)
+	if err != nil {
+		log.Printf("Service error fetching retired table: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	if retiredTableId > 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table number is held by a retired table.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Table number " + fmt.Sprint(r.TableNumber) + " is held by a retired table.",
+		}, http.StatusBadRequest
+	}
+	err = s.RestaurantRepo.UpdateTableNumber(r)
+	if err != nil {
+		log.Println("RestaurantService -> Error renumbering table:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) DeleteTable(r *request.TableRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> DeleteTable")
+	//check input
+	if r.TableId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Table ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	//find table id
+	exists, tableStatus, err := s.RestaurantRepo.FindTableByTableRequestId(r)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("Service error fetching table: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	if !exists {
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Table ID not found.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Table ID " + fmt.Sprint(r.TableId) + " not found.",
+		}, http.StatusNotFound
+	}
+	resp, status, err := s.checkTableNotMerged(r.TableId, true)
+	if err != nil {
+		return resp, status
+	}
+	openOrders, err := s.RestaurantRepo.CountOpenOrdersByTable(r.TableId)
+	if err != nil {
+		log.Printf("Service error counting open orders: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	if tableStatus == "occupied" || openOrders > 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table is in use and cannot be retired.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Table ID " + fmt.Sprint(r.TableId) + " is in use and cannot be retired.",
+		}, http.StatusBadRequest
+	}
+	err = s.RestaurantRepo.DeleteTable(r)
+	if err != nil {
+		log.Println("RestaurantService -> Error retiring table:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) KitchenQueue() (response.CustomResponse, int) {
+	log.Println("RestaurantService -> KitchenQueue")
+	queue, err := s.RestaurantRepo.GetKitchenQueue()
+	if err != nil {
+		log.Printf("Service error fetching kitchen queue: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data:    queue,
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) UpdateOrderItem(r *request.OrderItemRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> UpdateOrderItem")
+	//check input
+	if r.OrderItemId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Order item ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Order item ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	if !enums.IsItemStatus(r.Status) {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Status must be one of queued, cooking, ready, served, voided.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Status must be one of queued, cooking, ready, served, voided.",
+		}, http.StatusBadRequest
+	}
+	// Start transaction
+	tx, err := database.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	item, err := s.RestaurantRepo.FindOrderItemById(r.OrderItemId, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error fetching order item:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	if item == nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Order item ID not found.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Order item ID " + fmt.Sprint(r.OrderItemId) + " not found.",
+		}, http.StatusNotFound
+	}
+	if item.OrderStatus != enums.OrderCreated && item.OrderStatus != enums.OrderPrepare {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Order is " + item.OrderStatus + ", items can no longer change.")
+		return response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Order is " + item.OrderStatus + ", items can no longer change.",
+		}, http.StatusConflict
+	}
+	if !enums.CanTransitionItem(item.ItemStatus, r.Status) {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Item cannot move from " + item.ItemStatus + " to " + r.Status + ".")
+		return response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Item cannot move from " + item.ItemStatus + " to " + r.Status + ".",
+		}, http.StatusConflict
+	}
+	err = s.RestaurantRepo.UpdateOrderItemStatus(r.OrderItemId, r.Status, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error updating order item:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	orderStatus, err := s.syncOrderStatus(item.OrderId, item.TableId, item.OrderStatus, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error deriving order status:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Println("RestaurantService -> Error committing transaction:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	log.Println("Transaction committed successfully")
+	s.KitchenFeed.PublishItem(item.OrderItemId, item.OrderId, item.TableId, r.Status)
+	if orderStatus != item.OrderStatus {
+		if orderStatus == enums.OrderCanceled {
+			s.KitchenFeed.Publish(KitchenOrderCanceled, item.OrderId, item.TableId, orderStatus)
+		} else {
+			s.KitchenFeed.Publish(KitchenOrderStatusChanged, item.OrderId, item.TableId, orderStatus)
+		}
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data: model.Order{
+			OrderId: item.OrderId,
+			TableId: item.TableId,
+			Status:  orderStatus,
+		},
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) AmendOrder(r *request.OrderAmendRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> AmendOrder")
+	//check input
+	if r.TableId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Table ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	if r.OrderId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Order ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Order ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	if len(r.AddItems) == 0 && len(r.UpdateItems) == 0 && len(r.RemoveItemIds) == 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", addItems, updateItems or removeItemIds must not be empty.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", addItems, updateItems or removeItemIds must not be empty.",
+		}, http.StatusBadRequest
+	}
+	for _, menuItem := range r.AddItems {
+		if menuItem.Quantity <= 0 {
+			log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", MenuItem ID " + fmt.Sprint(menuItem.MenuItemID) + ", quantity must be greater than 0.")
+			return response.CustomResponse{
+				Code:    enums.Invalid.GetCode(),
+				Message: enums.Invalid.GetMessage() + ", MenuItem ID " + fmt.Sprint(menuItem.MenuItemID) + ", quantity must be greater than 0.",
+			}, http.StatusBadRequest
+		}
+	}
+	for _, item := range r.UpdateItems {
+		if item.Quantity <= 0 {
+			log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Order item ID " + fmt.Sprint(item.OrderItemId) + ", quantity must be greater than 0.")
+			return response.CustomResponse{
+				Code:    enums.Invalid.GetCode(),
+				Message: enums.Invalid.GetMessage() + ", Order item ID " + fmt.Sprint(item.OrderItemId) + ", quantity must be greater than 0.",
+			}, http.StatusBadRequest
+		}
+	}
+	//find table id
+	resp, status, err := s.CheckTableId(&request.OrderRequest{TableId: r.TableId})
+	if err != nil {
+		return resp, status
+	}
+	// Start transaction
+	tx, err := database.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	//find menu id
+	resp, status, err = s.checkMenuItems(r.AddItems, tx)
+	if err != nil {
+		tx.Rollback()
+		return resp, status
+	}
+	orderStatus, sessionId, err := s.RestaurantRepo.LockOrder(r.OrderId, r.TableId, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error locking order:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	// Customers can only change orders placed during their own table session
+	if orderStatus == "" || (r.SessionId > 0 && sessionId != r.SessionId) {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Order ID not found.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Order ID " + fmt.Sprint(r.OrderId) + " not found.",
+		}, http.StatusNotFound
+	}
+	if orderStatus != enums.OrderCreated && orderStatus != enums.OrderPrepare {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Order is " + orderStatus + " and can no longer be changed.")
+		return response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Order is " + orderStatus + " and can no longer be changed.",
+		}, http.StatusConflict
+	}
+	// Only dishes the kitchen has not started can be changed or removed
+	var lockedItems []int
+	for _, item := range r.UpdateItems {
+		updated, err := s.RestaurantRepo.UpdateOrderItemQuantity(r.OrderId, item.OrderItemId, item.Quantity, tx)
+		if err != nil {
+			tx.Rollback()
+			log.Println("RestaurantService -> Error updating order item quantity:", err)
+			return response.CustomResponse{
+				Code:    enums.Error.GetCode(),
+				Message: enums.Error.GetMessage(),
+			}, http.StatusInternalServerError
+		}
+		if !updated {
+			lockedItems = append(lockedItems, item.OrderItemId)
+		}
+	}
+	for _, orderItemId := range r.RemoveItemIds {
+		removed, err := s.RestaurantRepo.VoidOrderItem(r.OrderId, orderItemId, tx)
+		if err != nil {
+			tx.Rollback()
+			log.Println("RestaurantService -> Error removing order item:", err)
+			return response.CustomResponse{
+				Code:    enums.Error.GetCode(),
+				Message: enums.Error.GetMessage(),
+			}, http.StatusInternalServerError
+		}
+		if !removed {
+			lockedItems = append(lockedItems, orderItemId)
+		}
+	}
+	if len(lockedItems) > 0 {
+		tx.Rollback()
+		joinIDS := joinWithComma(lockedItems)
+		log.Println("RestaurantService -> "+enums.InvalidTransition.GetMessage()+", Order items not queued on this order: ", joinIDS)
+		return response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Order items not queued on this order: " + joinIDS,
+		}, http.StatusConflict
+	}
+	if len(r.AddItems) > 0 {
+		err = s.RestaurantRepo.InsertOrderItems(int64(r.OrderId), r.AddItems, tx)
+		if err != nil {
+			tx.Rollback()
+			log.Println("RestaurantService -> Error inserting order items:", err)
+			return response.CustomResponse{
+				Code:    enums.Error.GetCode(),
+				Message: enums.Error.GetMessage(),
+			}, http.StatusInternalServerError
+		}
+	}
+	newStatus, err := s.syncOrderStatus(r.OrderId, r.TableId, orderStatus, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error deriving order status:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Println("RestaurantService -> Error committing transaction:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	log.Println("Transaction committed successfully")
+	if newStatus == enums.OrderCanceled {
+		s.KitchenFeed.Publish(KitchenOrderCanceled, r.OrderId, r.TableId, newStatus)
+	} else {
+		s.KitchenFeed.Publish(KitchenOrderUpdated, r.OrderId, r.TableId, newStatus)
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data: model.Order{
+			OrderId: r.OrderId,
+			TableId: r.TableId,
+			Status:  newStatus,
+		},
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) GetBill(r *request.BillRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> GetBill")
+	//check input
+	resp, status, err := validateBillIds(r.OrderId, r.TableId)
+	if err != nil {
+		return resp, status
+	}
+	// Start transaction
+	tx, err := database.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	defer tx.Rollback()
+	orderStatus, _, err := s.RestaurantRepo.LockOrder(r.OrderId, r.TableId, tx)
+	if err != nil {
+		log.Println("RestaurantService -> Error locking order:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	if orderStatus == "" {
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Order ID not found.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Order ID " + fmt.Sprint(r.OrderId) + " not found.",
+		}, http.StatusNotFound
+	}
+	bill, err := s.loadBill(r.OrderId, tx)
+	if err != nil {
+		log.Println("RestaurantService -> Error loading bill:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	if bill == nil {
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", No bill opened for order.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", No bill opened for order " + fmt.Sprint(r.OrderId) + ".",
+		}, http.StatusNotFound
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data:    bill,
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) SplitBill(r *request.BillSplitRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> SplitBill")
+	//check input
+	resp, status, err := validateBillIds(r.OrderId, r.TableId)
+	if err != nil {
+		return resp, status
+	}
+	resp, status, err = validateBillSplitRequest(r)
+	if err != nil {
+		return resp, status
+	}
+	// Start transaction
+	tx, err := database.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	orderStatus, _, err := s.RestaurantRepo.LockOrder(r.OrderId, r.TableId, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error locking order:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	if orderStatus == "" {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Order ID not found.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Order ID " + fmt.Sprint(r.OrderId) + " not found.",
+		}, http.StatusNotFound
+	}
+	// Only an order that could be paid right now can have its bill split
+	respTransition, status, err := checkOrderTransition(orderStatus, enums.OrderPaid)
+	if err != nil {
+		tx.Rollback()
+		return respTransition, status
+	}
+	bill, respBill, status, err := s.openBill(r.OrderId, r.TableId, r.CouponCodes, tx)
+	if err != nil {
+		tx.Rollback()
+		return respBill, status
+	}
+	// A new split replaces the shares nobody has paid yet, shares already paid are kept
+	err = s.RestaurantRepo.CancelPendingPayments(bill.BillId, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error canceling pending payments:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	remaining := toCents(bill.TotalAmount) - toCents(bill.PaidAmount)
+	var payments []model.Payment
+	switch r.Mode {
+	case enums.SplitEven:
+		payments = splitEvenly(bill.BillId, remaining, r.Parts)
+	case enums.SplitCustom:
+		payments, resp, status, err = splitByAmount(bill.BillId, remaining, r.Shares)
+	case enums.SplitItem:
+		items, itemErr := s.RestaurantRepo.GetUnpaidBillItems(r.OrderId, tx)
+		if itemErr != nil {
+			tx.Rollback()
+			log.Println("RestaurantService -> Error getting unpaid bill items:", itemErr)
+			return response.CustomResponse{
+				Code:    enums.Error.GetCode(),
+				Message: enums.Error.GetMessage(),
+			}, http.StatusInternalServerError
+		}
+		paid, paidErr := s.RestaurantRepo.GetBillPayments(bill.BillId, tx)
+		if paidErr != nil {
+			tx.Rollback()
+			log.Println("RestaurantService -> Error getting bill payments:", paidErr)
+			return response.CustomResponse{
+				Code:    enums.Error.GetCode(),
+				Message: enums.Error.GetMessage(),
+			}, http.StatusInternalServerError
+		}
+		// Items can only be split while every paid share so far paid for items
+		for _, paidShare := range paid {
+			if paidShare.Status == enums.PaymentPaid && paidShare.SplitMode != enums.SplitItem {
+				tx.Rollback()
+				log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Bill was partly paid by amount and can no longer be split by item.")
+				return response.CustomResponse{
+					Code:    enums.InvalidTransition.GetCode(),
+					Message: enums.InvalidTransition.GetMessage() + ", Bill was partly paid by amount and can no longer be split by item.",
+				}, http.StatusConflict
+			}
+		}
+		payments, resp, status, err = splitByItem(bill.BillId, remaining, items, r.Shares)
+	}
+	if err != nil {
+		tx.Rollback()
+		return resp, status
+	}
+	for _, payment := range payments {
+		_, err = s.RestaurantRepo.InsertPayment(&payment, tx)
+		if err != nil {
+			tx.Rollback()
+			log.Println("RestaurantService -> Error inserting payment:", err)
+			return response.CustomResponse{
+				Code:    enums.Error.GetCode(),
+				Message: enums.Error.GetMessage(),
+			}, http.StatusInternalServerError
+		}
+	}
+	bill, err = s.loadBill(r.OrderId, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error loading bill:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Println("RestaurantService -> Error committing transaction:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	log.Println("Transaction committed successfully")
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data:    bill,
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) PayBillShare(r *request.BillPaymentRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> PayBillShare")
+	//check input
+	resp, status, err := validateBillIds(r.OrderId, r.TableId)
+	if err != nil {
+		return resp, status
+	}
+	if r.PaymentId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Payment ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Payment ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	resp, status, err = validatePaymentTenders(r.Tenders)
+	if err != nil {
+		return resp, status
+	}
+	// Start transaction
+	tx, err := database.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	orderStatus, _, err := s.RestaurantRepo.LockOrder(r.OrderId, r.TableId, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error locking order:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	if orderStatus == "" {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Order ID not found.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Order ID " + fmt.Sprint(r.OrderId) + " not found.",
+		}, http.StatusNotFound
+	}
+	respTransition, status, err := checkOrderTransition(orderStatus, enums.OrderPaid)
+	if err != nil {
+		tx.Rollback()
+		return respTransition, status
+	}
+	bill, err := s.RestaurantRepo.FindBillByOrderId(r.OrderId, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error finding bill:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	payment, err := s.RestaurantRepo.FindPaymentById(r.PaymentId, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error finding payment:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	if bill == nil || payment == nil || payment.BillId != bill.BillId {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Payment ID not found on this bill.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Payment ID " + fmt.Sprint(r.PaymentId) + " not found on this bill.",
+		}, http.StatusNotFound
+	}
+	if payment.Status != enums.PaymentPending {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Payment is already " + payment.Status + ".")
+		return response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Payment is already " + payment.Status + ".",
+		}, http.StatusConflict
+	}
+	if toCents(payment.Amount) > toCents(bill.TotalAmount)-toCents(bill.PaidAmount) {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Payment is larger than the amount left on the bill.")
+		return response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Payment is larger than the amount left on the bill.",
+		}, http.StatusConflict
+	}
+	tenders, respTender, status, err := matchTenders(toCents(payment.Amount), r.Tenders)
+	if err != nil {
+		tx.Rollback()
+		return respTender, status
+	}
+	// Nothing was written, the locks are only let go before the providers are charged
+	err = tx.Commit()
+	if err != nil {
+		log.Println("RestaurantService -> Error committing transaction:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	charged, respTender, status, err := s.collectTenders(toCents(payment.Amount), tenders)
+	if err != nil {
+		return respTender, status
+	}
+	return s.settleTenders(r.OrderId, r.TableId, payment.PaymentId, charged)
+}
+
+func (s *RestaurantService) GetAllPromotions() (response.CustomResponse, int) {
+	log.Println("RestaurantService -> GetAllPromotions")
+	promotions, err := s.RestaurantRepo.GetAllPromotions()
+	if err != nil {
+		log.Printf("Service error fetching promotions: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data:    promotions,
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) CreatePromotion(r *request.PromotionRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> CreatePromotion")
+	//check input
+	r.PromotionId = 0
+	resp, status, err := s.validatePromotionRequest(r)
+	if err != nil {
+		return resp, status
+	}
+	promotionId, err := s.RestaurantRepo.InsertPromotion(r)
+	if err != nil {
+		log.Println("RestaurantService -> Error creating promotion:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data: model.Promotion{
+			PromotionId:    int(promotionId),
+			Name:           r.Name,
+			RuleType:       r.RuleType,
+			Value:          r.Value,
+			MenuItemId:     r.MenuItemId,
+			MinSubtotal:    r.MinSubtotal,
+			StartsAt:       r.StartsAt,
+			EndsAt:         r.EndsAt,
+			HappyHourStart: r.HappyHourStart,
+			HappyHourEnd:   r.HappyHourEnd,
+			CouponCode:     r.CouponCode,
+			UsageLimit:     r.UsageLimit,
+			IsActive:       r.IsActive == nil || *r.IsActive,
+		},
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) UpdatePromotion(r *request.PromotionRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> UpdatePromotion")
+	//check input
+	if r.PromotionId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Promotion ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Promotion ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	//find promotion id
+	respPromotion, status, err := s.CheckPromotionId(r.PromotionId)
+	if err != nil {
+		return respPromotion, status
+	}
+	resp, status, err := s.validatePromotionRequest(r)
+	if err != nil {
+		return resp, status
+	}
+	err = s.RestaurantRepo.UpdatePromotion(r)
+	if err != nil {
+		log.Println("RestaurantService -> Error updating promotion:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) DeletePromotion(r *request.PromotionRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> DeletePromotion")
+	//check input
+	if r.PromotionId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Promotion ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Promotion ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	//find promotion id
+	respPromotion, status, err := s.CheckPromotionId(r.PromotionId)
+	if err != nil {
+		return respPromotion, status
+	}
+	err = s.RestaurantRepo.DeletePromotion(r)
+	if err != nil {
+		log.Println("RestaurantService -> Error deleting promotion:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) GetReceipt(r *request.ReceiptRequest) (*receipt.Document, response.CustomResponse, int) {
+	log.Println("RestaurantService -> GetReceipt")
+	//check input
+	resp, status, err := validateBillIds(r.OrderId, r.TableId)
+	if err != nil {
+		return nil, resp, status
+	}
+	if r.Format == "" {
+		r.Format = receipt.FormatPDF
+	}
+	if !receipt.IsFormat(r.Format) {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Format must be pdf or escpos.")
+		return nil, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Format must be pdf or escpos.",
+		}, http.StatusBadRequest
+	}
+	if r.Width == 0 {
+		r.Width = receipt.DefaultWidth
+	}
+	if r.Width < receipt.MinWidth || r.Width > receipt.MaxWidth {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Width must be between " + fmt.Sprint(receipt.MinWidth) + " and " + fmt.Sprint(receipt.MaxWidth) + ".")
+		return nil, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Width must be between " + fmt.Sprint(receipt.MinWidth) + " and " + fmt.Sprint(receipt.MaxWidth) + ".",
+		}, http.StatusBadRequest
+	}
+	order, err := s.RestaurantRepo.GetOrderDetails(&request.OrderRequest{OrderId: r.OrderId, TableId: r.TableId, SessionId: r.SessionId})
+	if err != nil {
+		log.Println("RestaurantService -> Error getting order details:", err)
+		return nil, response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	if order == nil {
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Order ID not found.")
+		return nil, response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Order ID " + fmt.Sprint(r.OrderId) + " not found.",
+		}, http.StatusNotFound
+	}
+	tableNumber, err := s.RestaurantRepo.FindTableNumberById(r.TableId)
+	if err != nil {
+		log.Println("RestaurantService -> Error finding table number:", err)
+		return nil, response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	// Start transaction
+	tx, err := database.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return nil, response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	defer tx.Rollback()
+	bill, err := s.loadBill(r.OrderId, tx)
+	if err != nil {
+		log.Println("RestaurantService -> Error loading bill:", err)
+		return nil, response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	if bill == nil || toCents(bill.PaidAmount) == 0 {
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Nothing has been paid on order yet.")
+		return nil, response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Nothing has been paid on order " + fmt.Sprint(r.OrderId) + " yet.",
+		}, http.StatusNotFound
+	}
+	document := receipt.Render(&receipt.Receipt{
+		Title:       s.ReceiptTitle,
+		TableNumber: tableNumber,
+		Order:       *order,
+		Bill:        *bill,
+		PrintedAt:   time.Now(),
+		Font:        s.ReceiptFont,
+		CodePage:    s.ReceiptCodePage,
+	}, r.Format, r.Width)
+	return document, response.CustomResponse{}, http.StatusOK
+}
+
+// RefundOrder gives money back on a paid order. It first commits the refund as pending: a negative
+// payment with one pending negative tender per charge it returns, the refund record with its items
+// and the amount held on the bill. Only then are the providers asked to return the money, and
+// finishRefund records the outcome. The order becomes refunded once nothing is left to give back.
+func (s *RestaurantService) RefundOrder(r *request.RefundRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> RefundOrder")
+	//check input
+	resp, status, err := validateRefundRequest(r)
+	if err != nil {
+		return resp, status
+	}
+	// Start transaction
+	tx, err := database.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	orderStatus, _, err := s.RestaurantRepo.LockOrder(r.OrderId, r.TableId, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error locking order:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	if orderStatus == "" {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Order ID not found.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Order ID " + fmt.Sprint(r.OrderId) + " not found.",
+		}, http.StatusNotFound
+	}
+	respTransition, status, err := checkOrderTransition(orderStatus, enums.OrderRefunded)
+	if err != nil {
+		tx.Rollback()
+		return respTransition, status
+	}
+	bill, err := s.RestaurantRepo.FindBillByOrderId(r.OrderId, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error finding bill:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	if bill == nil || bill.Status != enums.BillSettled {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Order has no settled bill to refund.")
+		return response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Order has no settled bill to refund.",
+		}, http.StatusConflict
+	}
+	if r.Kind == enums.RefundKindVoid && toCents(bill.RefundedAmount) > 0 {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Bill already has refunds and can no longer be voided.")
+		return response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Bill already has refunds and can no longer be voided.",
+		}, http.StatusConflict
+	}
+	refund, resp, status, err := s.buildRefund(bill, r, tx)
+	if err != nil {
+		tx.Rollback()
+		return resp, status
+	}
+	refundable, err := s.RestaurantRepo.GetRefundableTenders(bill.BillId, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error getting refundable tenders:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	tenders, err := allocateRefund(toCents(refund.Amount), refundable)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error allocating refund to tenders:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	paymentId, err := s.RestaurantRepo.InsertPayment(&model.Payment{
+		BillId:    bill.BillId,
+		Label:     refundLabel(r.Kind, r.ReasonCode),
+		SplitMode: r.Kind,
+		Amount:    -refund.Amount,
+	}, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error creating refund payment:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	refund.PaymentId = int(paymentId)
+	for _, tender := range tenders {
+		if _, ok := s.PaymentProviders.Provider(tender.Method); !ok {
+			tx.Rollback()
+			log.Println("RestaurantService -> No payment provider configured for", tender.Method)
+			return response.CustomResponse{
+				Code:    enums.Error.GetCode(),
+				Message: enums.Error.GetMessage(),
+			}, http.StatusInternalServerError
+		}
+	}
+	err = s.RestaurantRepo.InsertTenders(refund.PaymentId, tenders, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error recording refund tenders:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	refundId, err := s.RestaurantRepo.InsertRefund(refund, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error recording refund:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	refund.RefundId = int(refundId)
+	// The amount is held on the bill while the refund is pending so it cannot be refunded twice
+	err = s.RestaurantRepo.AddBillRefund(bill.BillId, refund.Amount, bill.Status, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error updating bill:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Println("RestaurantService -> Error committing transaction:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	log.Println("Transaction committed successfully")
+
+	// Money only moves once the pending refund is committed, the outcome is recorded afterwards
+	tenders = s.returnTenders(r.Kind, refund.PaymentId, tenders)
+	fullyRefunded := toCents(bill.RefundedAmount)+toCents(refund.Amount) >= toCents(bill.PaidAmount)
+	return s.finishRefund(r, bill, refund, tenders, fullyRefunded)
+}
+
+// finishRefund records what the providers did with a pending refund in a second transaction. When
+// every tender went through the refund payment is settled and the bill and order take their new
+// status. When none did the refund is undone. Otherwise it stays pending with the declined
+// tenders marked failed, for staff to retry through RetryRefund.
+func (s *RestaurantService) finishRefund(r *request.RefundRequest, bill *model.Bill, refund *model.Refund, tenders []model.Tender, fullyRefunded bool) (response.CustomResponse, int) {
+	// Start transaction
+	tx, err := database.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting transaction, refund %d stays pending: %v", refund.RefundId, err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	internalError := func(action string, err error) (response.CustomResponse, int) {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error "+action+", refund "+fmt.Sprint(refund.RefundId)+" stays pending:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	_, _, err = s.RestaurantRepo.LockOrder(r.OrderId, r.TableId, tx)
+	if err != nil {
+		return internalError("locking order", err)
+	}
+	payment, err := s.RestaurantRepo.FindPaymentById(refund.PaymentId, tx)
+	if err != nil {
+		return internalError("finding refund payment", err)
+	}
+	if payment == nil || payment.Status != enums.PaymentPending {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Refund " + fmt.Sprint(refund.RefundId) + " was finished by another request.")
+		return response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Refund ID " + fmt.Sprint(refund.RefundId) + " is no longer pending.",
+		}, http.StatusConflict
+	}
+	var failed []string
+	for _, tender := range tenders {
+		err = s.RestaurantRepo.UpdateRefundTender(refund.PaymentId, tender, tx)
+		if err != nil {
+			return internalError("recording refund tender", err)
+		}
+		if tender.Status == enums.TenderFailed {
+			failed = append(failed, tender.Method)
+		}
+	}
+	switch {
+	case len(failed) == 0:
+		err = s.RestaurantRepo.SettlePayment(refund.PaymentId, tx)
+		if err != nil {
+			return internalError("settling refund payment", err)
+		}
+		billStatus := enums.BillSettled
+		switch {
+		case r.Kind == enums.RefundKindVoid:
+			billStatus = enums.BillVoided
+		case fullyRefunded:
+			billStatus = enums.BillRefunded
+		}
+		err = s.RestaurantRepo.AddBillRefund(bill.BillId, 0, billStatus, tx)
+		if err != nil {
+			return internalError("updating bill", err)
+		}
+		if fullyRefunded {
+			err = s.RestaurantRepo.UpdateOrderWithTx(r.TableId, r.OrderId, enums.OrderRefunded, tx)
+			if err != nil {
+				return internalError("updating order", err)
+			}
+		}
+	case len(failed) == len(tenders):
+		err = s.RestaurantRepo.CancelPayment(refund.PaymentId, tx)
+		if err != nil {
+			return internalError("canceling refund payment", err)
+		}
+		err = s.RestaurantRepo.DeleteRefund(refund.RefundId, tx)
+		if err != nil {
+			return internalError("deleting refund", err)
+		}
+		err = s.RestaurantRepo.AddBillRefund(bill.BillId, -refund.Amount, bill.Status, tx)
+		if err != nil {
+			return internalError("updating bill", err)
+		}
+	}
+	updated, err := s.loadBill(r.OrderId, tx)
+	if err != nil {
+		return internalError("loading bill", err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Println("RestaurantService -> Error committing transaction, refund "+fmt.Sprint(refund.RefundId)+" stays pending:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	log.Println("Transaction committed successfully")
+	switch {
+	case len(failed) == len(tenders):
+		log.Println("RestaurantService -> " + enums.PaymentDeclined.GetMessage() + ", " + r.Kind + " was declined, nothing was returned.")
+		return response.CustomResponse{
+			Code:    enums.PaymentDeclined.GetCode(),
+			Message: enums.PaymentDeclined.GetMessage() + ", " + strings.Join(failed, ", ") + " " + r.Kind + " was declined.",
+			Data:    updated,
+		}, http.StatusPaymentRequired
+	case len(failed) > 0:
+		log.Println("RestaurantService -> " + enums.PaymentDeclined.GetMessage() + ", Refund " + fmt.Sprint(refund.RefundId) + " went through in part and stays pending.")
+		return response.CustomResponse{
+			Code:    enums.PaymentDeclined.GetCode(),
+			Message: enums.PaymentDeclined.GetMessage() + ", " + strings.Join(failed, ", ") + " " + r.Kind + " was declined, the rest was returned. The declined part stays pending, retry it through /order/refund/retry.",
+			Data:    updated,
+		}, http.StatusPaymentRequired
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data:    updated,
+	}, http.StatusOK
+}
+
+// RetryRefund asks the providers again for a refund left pending, either because some of its
+// tenders were declined or because their outcome could not be recorded. The tenders already
+// returned are left alone and finishRefund records the outcome as for a new refund.
+func (s *RestaurantService) RetryRefund(r *request.RefundRetryRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> RetryRefund")
+	//check input
+	resp, status, err := validateBillIds(r.OrderId, r.TableId)
+	if err != nil {
+		return resp, status
+	}
+	if r.RefundId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Refund ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Refund ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	// Start transaction
+	tx, err := database.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	orderStatus, _, err := s.RestaurantRepo.LockOrder(r.OrderId, r.TableId, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error locking order:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	if orderStatus == "" {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Order ID not found.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Order ID " + fmt.Sprint(r.OrderId) + " not found.",
+		}, http.StatusNotFound
+	}
+	bill, err := s.RestaurantRepo.FindBillByOrderId(r.OrderId, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error finding bill:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	refund, err := s.RestaurantRepo.FindRefundById(r.RefundId, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error finding refund:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	if bill == nil || refund == nil || refund.BillId != bill.BillId {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Refund ID not found on this order.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Refund ID " + fmt.Sprint(r.RefundId) + " not found on this order.",
+		}, http.StatusNotFound
+	}
+	payment, err := s.RestaurantRepo.FindPaymentById(refund.PaymentId, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error finding refund payment:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	if payment == nil || payment.Status != enums.PaymentPending {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Refund is not pending.")
+		return response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Refund ID " + fmt.Sprint(r.RefundId) + " is no longer pending.",
+		}, http.StatusConflict
+	}
+	tenders, err := s.RestaurantRepo.GetRefundTenders(refund.PaymentId, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error getting refund tenders:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	err = s.RestaurantRepo.ReopenRefundTenders(refund.PaymentId, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error reopening refund tenders:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Println("RestaurantService -> Error committing transaction:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	log.Println("Transaction committed successfully")
+
+	// Only the tenders not returned yet go back to the providers
+	var settled, open []model.Tender
+	for _, tender := range tenders {
+		if tender.Status == enums.TenderSettled {
+			settled = append(settled, tender)
+		} else {
+			open = append(open, tender)
+		}
+	}
+	tenders = append(settled, s.returnTenders(refund.Kind, refund.PaymentId, open)...)
+	// The refund already holds its amount on the bill
+	fullyRefunded := toCents(bill.RefundedAmount) >= toCents(bill.PaidAmount)
+	return s.finishRefund(&request.RefundRequest{OrderId: r.OrderId, TableId: r.TableId, Kind: refund.Kind}, bill, refund, tenders, fullyRefunded)
+}
+
+func (s *RestaurantService) GetSalesReport(r *request.ReportRequest) (*report.Document, response.CustomResponse, int) {
+	log.Println("RestaurantService -> GetSalesReport")
+	//check input
+	from, to, resp, status, err := validateReportRequest(r)
+	if err != nil {
+		return nil, resp, status
+	}
+	if r.GroupBy == "" {
+		r.GroupBy = enums.ReportByDay
+	}
+	if !enums.IsReportGroup(r.GroupBy) {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Group by must be day, week, month, hour or table.")
+		return nil, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Group by must be day, week, month, hour or table.",
+		}, http.StatusBadRequest
+	}
+	rows, err := s.RestaurantRepo.GetSalesReport(config.FormatTime(from), config.FormatTime(to), r.GroupBy)
+	if err != nil {
+		log.Println("RestaurantService -> Error getting sales report:", err)
+		return nil, response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	sales := &model.SalesReport{
+		From:     r.From,
+		To:       r.To,
+		TimeZone: time.Local.String(),
+		GroupBy:  r.GroupBy,
+		Rows:     []model.SalesRow{},
+		Totals:   model.SalesRow{Period: "TOTAL"},
+	}
+	var gross, discount, serviceCharge, vat, refunded int64
+	for _, row := range rows {
+		finishSalesRow(&row)
+		sales.Rows = append(sales.Rows, row)
+		sales.Totals.Orders += row.Orders
+		gross += toCents(row.Gross)
+		discount += toCents(row.Discount)
+		serviceCharge += toCents(row.ServiceCharge)
+		vat += toCents(row.Vat)
+		refunded += toCents(row.Refunded)
+	}
+	sales.Totals.Gross = fromCents(gross)
+	sales.Totals.Discount = fromCents(discount)
+	sales.Totals.ServiceCharge = fromCents(serviceCharge)
+	sales.Totals.Vat = fromCents(vat)
+	sales.Totals.Refunded = fromCents(refunded)
+	finishSalesRow(&sales.Totals)
+
+	if r.Format == report.FormatCSV {
+		document, err := report.SalesCSV(sales)
+		if err != nil {
+			log.Println("RestaurantService -> Error writing sales report:", err)
+			return nil, response.CustomResponse{
+				Code:    enums.Error.GetCode(),
+				Message: enums.Error.GetMessage(),
+			}, http.StatusInternalServerError
+		}
+		return document, response.CustomResponse{}, http.StatusOK
+	}
+	return nil, response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data:    sales,
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) GetMenuItemReport(r *request.ReportRequest) (*report.Document, response.CustomResponse, int) {
+	log.Println("RestaurantService -> GetMenuItemReport")
+	//check input
+	from, to, resp, status, err := validateReportRequest(r)
+	if err != nil {
+		return nil, resp, status
+	}
+	items, err := s.RestaurantRepo.GetMenuItemStats(config.FormatTime(from), config.FormatTime(to))
+	if err != nil {
+		log.Println("RestaurantService -> Error getting menu item stats:", err)
+		return nil, response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	menuItems := &model.MenuItemReport{
+		From:     r.From,
+		To:       r.To,
+		TimeZone: time.Local.String(),
+		Items:    []model.MenuItemStats{},
+	}
+	for _, item := range items {
+		if item.UnitsOrdered > 0 {
+			item.CancellationRate = math.Round(float64(item.UnitsCanceled)*10000/float64(item.UnitsOrdered)) / 100
+		}
+		item.AverageRating = math.Round(item.AverageRating*100) / 100
+		menuItems.Items = append(menuItems.Items, item)
+	}
+
+	if r.Format == report.FormatCSV {
+		document, err := report.MenuItemsCSV(menuItems)
+		if err != nil {
+			log.Println("RestaurantService -> Error writing menu item report:", err)
+			return nil, response.CustomResponse{
+				Code:    enums.Error.GetCode(),
+				Message: enums.Error.GetMessage(),
+			}, http.StatusInternalServerError
+		}
+		return document, response.CustomResponse{}, http.StatusOK
+	}
+	return nil, response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data:    menuItems,
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) GetAllReviews(f *request.ReviewFilterRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> GetAllReviews")
+	//check input
+	resp, status, err := validateReviewFilterRequest(f)
+	if err != nil {
+		return resp, status
+	}
+	reviews, total, err := s.RestaurantRepo.GetAllReviews(f)
+	if err != nil {
+		log.Printf("Service error fetching reviews: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	pageSize := f.PageSize
+	if pageSize <= 0 {
+		pageSize = total
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data: model.ReviewPage{
+			Reviews:  reviews,
+			Page:     f.Page,
+			PageSize: pageSize,
+			Total:    total,
+		}}, http.StatusOK
+}
+
+func (s *RestaurantService) GetReviewSummary(f *request.ReviewFilterRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> GetReviewSummary")
+	//check input
+	resp, status, err := validateReviewFilterRequest(f)
+	if err != nil {
+		return resp, status
+	}
+	summary, err := s.RestaurantRepo.GetReviewSummary(f)
+	if err != nil {
+		log.Printf("Service error summarizing reviews: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	summary.AverageRating = math.Round(summary.AverageRating*100) / 100
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data:    summary,
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) ReplyReview(r *request.ReviewReplyRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> ReplyReview")
+	//check input
+	if r.ReviewId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Review ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Review ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	r.Reply = strings.TrimSpace(r.Reply)
+	if r.Reply == "" || utf8.RuneCountInString(r.Reply) > 1000 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Reply must be between 1 and 1000 characters.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Reply must be between 1 and 1000 characters.",
+		}, http.StatusBadRequest
+	}
+	//find review id
+	respReview, status, err := s.CheckReviewId(r.ReviewId)
+	if err != nil {
+		return respReview, status
+	}
+	err = s.RestaurantRepo.ReplyReview(r)
+	if err != nil {
+		log.Println("RestaurantService -> Error replying to review:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+	}, http.StatusOK
+}
+
+// ModerateReview hides a review from the public aggregates or shows it again.
+func (s *RestaurantService) ModerateReview(r *request.ReviewModerationRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> ModerateReview")
+	//check input
+	resp, status, err := validateReviewModerationRequest(r)
+	if err != nil {
+		return resp, status
+	}
+	//find review id
+	respReview, status, err := s.CheckReviewId(r.ReviewId)
+	if err != nil {
+		return respReview, status
+	}
+	err = s.RestaurantRepo.ModerateReview(r)
+	if err != nil {
+		log.Println("RestaurantService -> Error moderating review:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) DeleteReview(r *request.ReviewModerationRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> DeleteReview")
+	//check input
+	resp, status, err := validateReviewModerationRequest(r)
+	if err != nil {
+		return resp, status
+	}
+	//find review id
+	respReview, status, err := s.CheckReviewId(r.ReviewId)
+	if err != nil {
+		return respReview, status
+	}
+	err = s.RestaurantRepo.DeleteReview(r)
+	if err != nil {
+		log.Println("RestaurantService -> Error deleting review:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) GetAllReservations(f *request.ReservationFilterRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> GetAllReservations")
+	//check input
+	if f.Date == "" {
+		f.Date = time.Now().Format(time.DateOnly)
+	}
+	if _, err := time.ParseInLocation(time.DateOnly, f.Date, time.Local); err != nil {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Date must be a date like 2006-01-02.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Date must be a date like 2006-01-02.",
+		}, http.StatusBadRequest
+	}
+	if f.Status != "" && !enums.IsReservationStatus(f.Status) {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Status " + f.Status + " is not a valid reservation status.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Status " + f.Status + " is not a valid reservation status.",
+		}, http.StatusBadRequest
+	}
+	reservations, err := s.RestaurantRepo.GetAllReservations(f)
+	if err != nil {
+		log.Printf("Service error fetching reservations: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data:    reservations,
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) CreateReservation(r *request.ReservationRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> CreateReservation")
+	//check input
+	reservedAt, resp, status, err := s.validateReservationRequest(r)
+	if err != nil {
+		return resp, status
+	}
+	// Start transaction
+	tx, err := database.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	resp, status, err = s.checkReservationTable(r, reservedAt, tx)
+	if err != nil {
+		tx.Rollback()
+		return resp, status
+	}
+	reservationId, err := s.RestaurantRepo.InsertReservation(r, config.FormatTime(reservedAt), tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error creating reservation:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return s.commitReservation(int(reservationId), tx)
+}
+
+func (s *RestaurantService) UpdateReservation(r *request.ReservationRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> UpdateReservation")
+	//check input
+	if r.ReservationId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Reservation ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Reservation ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	reservedAt, resp, status, err := s.validateReservationRequest(r)
+	if err != nil {
+		return resp, status
+	}
+	// Start transaction
+	tx, err := database.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	reservation, resp, status, err := s.lockReservation(r.ReservationId, tx)
+	if err != nil {
+		tx.Rollback()
+		return resp, status
+	}
+	if reservation.Status != enums.ReservationBooked {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Only booked reservations can be changed.")
+		return response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Reservation is " + reservation.Status + ", only booked reservations can be changed.",
+		}, http.StatusConflict
+	}
+	resp, status, err = s.checkReservationTable(r, reservedAt, tx)
+	if err != nil {
+		tx.Rollback()
+		return resp, status
+	}
+	err = s.RestaurantRepo.UpdateReservation(r, config.FormatTime(reservedAt), tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error updating reservation:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return s.commitReservation(r.ReservationId, tx)
+}
+
+// UpdateReservationStatus seats, cancels, completes or marks a booking as a no-show. Seating occupies
+// the booked table and starts its customer session, canceling or a no-show releases the table.
+func (s *RestaurantService) UpdateReservationStatus(r *request.ReservationStatusRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> UpdateReservationStatus")
+	//check input
+	if r.ReservationId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Reservation ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Reservation ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	if !enums.IsReservationStatus(r.Status) {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Status " + r.Status + " is not a valid reservation status.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Status " + r.Status + " is not a valid reservation status.",
+		}, http.StatusBadRequest
+	}
+	// Start transaction
+	tx, err := database.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	reservation, resp, status, err := s.lockReservation(r.ReservationId, tx)
+	if err != nil {
+		tx.Rollback()
+		return resp, status
+	}
+	if !enums.CanTransitionReservation(reservation.Status, r.Status) {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Reservation cannot move from " + reservation.Status + " to " + r.Status + ".")
+		return response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Reservation cannot move from " + reservation.Status + " to " + r.Status + ".",
+		}, http.StatusConflict
+	}
+	var tableSession *model.TableSession
+	if r.Status == enums.ReservationSeated {
+		resp, status, err = s.occupyReservedTable(reservation, tx)
+		if err != nil {
+			tx.Rollback()
+			return resp, status
+		}
+		// The seated party orders through the QR code of a new table session
+		tableSession, err = s.openTableSession(reservation.TableId, tx)
+		if err != nil {
+			tx.Rollback()
+			log.Println("RestaurantService -> Error starting table session:", err)
+			return response.CustomResponse{
+				Code:    enums.Error.GetCode(),
+				Message: enums.Error.GetMessage(),
+			}, http.StatusInternalServerError
+		}
+	}
+	err = s.RestaurantRepo.UpdateReservationStatus(r.ReservationId, r.Status, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error updating reservation status:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	resp, status = s.commitReservation(r.ReservationId, tx)
+	if status == http.StatusOK && tableSession != nil {
+		resp.Data.(*model.Reservation).TableSession = tableSession
+	}
+	return resp, status
+}
+
+// GetWaitlist lists the walk-in parties still waiting or notified, with their queue position and
+// estimated wait.
+func (s *RestaurantService) GetWaitlist() (response.CustomResponse, int) {
+	log.Println("RestaurantService -> GetWaitlist")
+	entries, err := s.RestaurantRepo.GetWaitlist()
+	if err == nil {
+		err = s.fillWaitEstimates(entries)
+	}
+	if err != nil {
+		log.Printf("Service error fetching waitlist: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data:    entries,
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) AddToWaitlist(r *request.WaitlistRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> AddToWaitlist")
+	//check input
+	resp, status, err := validateWaitlistRequest(r)
+	if err != nil {
+		return resp, status
+	}
+	entries, err := s.RestaurantRepo.GetWaitlist()
+	if err != nil {
+		log.Printf("Service error fetching waitlist: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	waits, err := s.estimateQueue(entries, r.PartySize)
+	if err != nil {
+		log.Printf("Service error estimating wait: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	quotedWait := waits[len(waits)-1]
+	if quotedWait < 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", No table seats a party of " + fmt.Sprint(r.PartySize) + ".")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", No table seats a party of " + fmt.Sprint(r.PartySize) + ".",
+		}, http.StatusBadRequest
+	}
+	waitlistId, err := s.RestaurantRepo.InsertWaitlistEntry(r, quotedWait)
+	if err != nil {
+		log.Println("RestaurantService -> Error adding waitlist entry:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	entries, err = s.RestaurantRepo.GetWaitlist()
+	if err == nil {
+		err = s.fillWaitEstimates(entries)
+	}
+	if err != nil {
+		log.Printf("Service error fetching waitlist: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	for _, entry := range entries {
+		if entry.WaitlistId == int(waitlistId) {
+			return response.CustomResponse{
+				Code:    enums.Success.GetCode(),
+				Message: enums.Success.GetMessage(),
+				Data:    entry,
+			}, http.StatusOK
+		}
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+	}, http.StatusOK
+}
+
+// UpdateWaitlistStatus seats a waiting party or takes it off the waitlist. Seating occupies the table
+// and starts its customer session, a notified party that leaves passes its table to the next party.
+func (s *RestaurantService) UpdateWaitlistStatus(r *request.WaitlistStatusRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> UpdateWaitlistStatus")
+	//check input
+	if r.WaitlistId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Waitlist ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Waitlist ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	if r.Status != enums.WaitlistSeated && r.Status != enums.WaitlistLeft {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Status must be seated or left.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Status must be seated or left.",
+		}, http.StatusBadRequest
+	}
+	if r.TableId < 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table ID must not be negative.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Table ID must not be negative.",
+		}, http.StatusBadRequest
+	}
+	// Start transaction
+	tx, err := database.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	entry, err := s.RestaurantRepo.FindWaitlistEntryById(r.WaitlistId, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error finding waitlist entry:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	if entry == nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Waitlist ID not found.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Waitlist ID " + fmt.Sprint(r.WaitlistId) + " not found.",
+		}, http.StatusNotFound
+	}
+	if !enums.CanTransitionWaitlist(entry.Status, r.Status) {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Waitlist entry cannot move from " + entry.Status + " to " + r.Status + ".")
+		return response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Waitlist entry cannot move from " + entry.Status + " to " + r.Status + ".",
+		}, http.StatusConflict
+	}
+	freedTableId := 0
+	tableId := 0
+	var tableSession *model.TableSession
+	if r.Status == enums.WaitlistSeated {
+		tableId = r.TableId
+		if tableId == 0 {
+			tableId = entry.TableId
+		}
+		seatedTableId, resp, status, err := s.occupyWaitlistTable(entry, tableId, tx)
+		if err != nil {
+			tx.Rollback()
+			return resp, status
+		}
+		tableId = seatedTableId
+		// The seated party orders through the QR code of a new table session
+		tableSession, err = s.openTableSession(tableId, tx)
+		if err != nil {
+			tx.Rollback()
+			log.Println("RestaurantService -> Error starting table session:", err)
+			return response.CustomResponse{
+				Code:    enums.Error.GetCode(),
+				Message: enums.Error.GetMessage(),
+			}, http.StatusInternalServerError
+		}
+	} else if entry.Status == enums.WaitlistNotified {
+		freedTableId = entry.TableId
+	}
+	err = s.RestaurantRepo.UpdateWaitlistStatus(r.WaitlistId, r.Status, tableId, tx)
+	if err == nil {
+		entry, err = s.RestaurantRepo.FindWaitlistEntryById(r.WaitlistId, tx)
+	}
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error updating waitlist status:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Println("RestaurantService -> Error committing transaction:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	log.Println("Transaction committed successfully")
+	if freedTableId > 0 {
+		if _, err := s.suggestNextParty(freedTableId); err != nil {
+			log.Println("RestaurantService -> Error suggesting next waitlist party:", err)
+		}
+	}
+	entry.TableSession = tableSession
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data:    entry,
+	}, http.StatusOK
+}
+
+// GetFloorPlan returns the dining room zone by zone with each table's live status, open order
+// value, seated time and next booking today.
+func (s *RestaurantService) GetFloorPlan() (response.CustomResponse, int) {
+	log.Println("RestaurantService -> GetFloorPlan")
+	now := time.Now()
+	dayEnd := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.Local)
+	tables, err := s.RestaurantRepo.GetFloorPlan(config.FormatTime(now), config.FormatTime(dayEnd))
+	if err != nil {
+		log.Printf("Service error fetching floor plan: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	floorPlan := model.FloorPlan{Zones: []model.FloorZone{}}
+	var planTotal int64
+	for _, zone := range enums.TableZones {
+		floorZone := model.FloorZone{Zone: zone, Tables: []model.FloorTable{}}
+		var zoneTotal int64
+		for _, table := range tables {
+			if table.Zone != zone {
+				continue
+			}
+			floorZone.Tables = append(floorZone.Tables, table)
+			floorZone.Seats += table.Capacity
+			if table.TableStatus == enums.TableAvailable {
+				floorZone.FreeSeats += table.Capacity
+			}
+			zoneTotal += toCents(table.OpenOrderTotal)
+		}
+		floorZone.OpenOrderTotal = fromCents(zoneTotal)
+		floorPlan.Zones = append(floorPlan.Zones, floorZone)
+		floorPlan.Seats += floorZone.Seats
+		floorPlan.FreeSeats += floorZone.FreeSeats
+		planTotal += zoneTotal
+	}
+	floorPlan.OpenOrderTotal = fromCents(planTotal)
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data:    floorPlan,
+	}, http.StatusOK
+}
+
+// SuggestTables finds the free tables that seat a party, best fit first, or the wait the party would
+// have when none is free.
+func (s *RestaurantService) SuggestTables(r *request.TableSuggestionRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> SuggestTables")
+	//check input
+	if r.PartySize < 1 || r.PartySize > maxPartySize {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Party size must be between 1 and " + fmt.Sprint(maxPartySize) + ".")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Party size must be between 1 and " + fmt.Sprint(maxPartySize) + ".",
+		}, http.StatusBadRequest
+	}
+	if r.Zone != "" && !enums.IsTableZone(r.Zone) {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Zone must be indoor, terrace or bar.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Zone must be indoor, terrace or bar.",
+		}, http.StatusBadRequest
+	}
+	suggestion, err := s.suggestTables(r.PartySize, r.Zone)
+	if err != nil {
+		log.Printf("Service error suggesting tables: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data:    suggestion,
+	}, http.StatusOK
+}
+
+func (s *RestaurantService) UpdateTableLayout(r *request.TableRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> UpdateTableLayout")
+	//check input
+	if r.TableId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Table ID must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	resp, status, err := validateTableLayout(r)
+	if err != nil {
+		return resp, status
+	}
+	exists, _, err := s.RestaurantRepo.FindTableByTableRequestId(r)
+	if err != nil {
+		log.Printf("Service error fetching table: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	if !exists {
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Table ID not found.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Table ID " + fmt.Sprint(r.TableId) + " not found.",
+		}, http.StatusNotFound
+	}
+	err = s.RestaurantRepo.UpdateTableLayout(r)
+	if err != nil {
+		log.Println("RestaurantService -> Error updating table layout:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+	}, http.StatusOK
+}
+
+// TransferTable moves a party to a free table that no booking needs within an average sitting: its
+// orders and bills follow it, the old table is freed and the party gets a new QR session at the new table.
+func (s *RestaurantService) TransferTable(r *request.TableTransferRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> TransferTable")
+	//check input
+	if r.FromTableId <= 0 || r.ToTableId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table IDs must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Table IDs must be greater than 0.",
+		}, http.StatusBadRequest
+	}
+	if r.FromTableId == r.ToTableId {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Tables must be different.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Tables must be different.",
+		}, http.StatusBadRequest
+	}
+	dining, err := s.diningMinutes()
+	if err != nil {
+		log.Println("RestaurantService -> Error getting average dining time:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	// Start transaction
+	tx, err := database.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	tables, resp, status, err := s.lockTableStates([]int{r.FromTableId, r.ToTableId}, tx)
+	if err == nil {
+		resp, status, err = checkTableState(tables[r.FromTableId], enums.TableOccupied)
+	}
+	if err == nil {
+		resp, status, err = checkTableState(tables[r.ToTableId], enums.TableAvailable)
+	}
+	if err != nil {
+		tx.Rollback()
+		return resp, status
+	}
+	// The party would still be at the new table when its booking is due
+	now := time.Now()
+	booked, err := s.RestaurantRepo.HasReservationConflict(r.ToTableId, config.FormatTime(now),
+		config.FormatTime(now.Add(time.Duration(dining)*time.Minute)), 0, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error checking reservations:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	if booked {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Table ID " + fmt.Sprint(r.ToTableId) + " is booked within the next " + fmt.Sprint(dining) + " minutes.")
+		return response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Table ID " + fmt.Sprint(r.ToTableId) + " is booked within the next " + fmt.Sprint(dining) + " minutes.",
+		}, http.StatusConflict
+	}
+	moved, err := s.RestaurantRepo.MoveTableOrders(r.FromTableId, r.ToTableId, false, tx)
+	if err == nil {
+		err = s.RestaurantRepo.UpdateTableWithTx(r.ToTableId, enums.TableOccupied, tx)
+	}
+	if err == nil {
+		err = s.RestaurantRepo.UpdateTableWithTx(r.FromTableId, enums.TableAvailable, tx)
+	}
+	if err == nil {
+		err = s.RestaurantRepo.CloseTableSessionsWithTx(r.FromTableId, tx)
+	}
+	if err == nil {
+		// A party offered the new table waits for the next one
+		err = s.RestaurantRepo.ReleaseNotifiedParties(r.ToTableId, 0, tx)
+	}
+	var tableSession *model.TableSession
+	if err == nil {
+		tableSession, err = s.moveTableSession(r.ToTableId, tx)
+	}
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error transferring table:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Println("RestaurantService -> Error committing transaction:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	log.Println("Transaction committed successfully")
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data: []model.TableMove{
+			s.freedTableMove(r.FromTableId),
+			{TableId: r.ToTableId, TableStatus: enums.TableOccupied, OrdersMoved: int(moved), TableSession: tableSession},
+		},
+	}, http.StatusOK
+}
+
+// MergeTables joins tables to the party's table so everything is billed together. Their orders move
+// to the main table, remembering where they came from, and their QR sessions are closed.
+func (s *RestaurantService) MergeTables(r *request.TableGroupRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> MergeTables")
+	//check input
+	resp, status, err := validateTableGroupRequest(r, true)
+	if err != nil {
+		return resp, status
+	}
+	// Start transaction
+	tx, err := database.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	tables, resp, status, err := s.lockTableStates(append([]int{r.TableId}, r.TableIds...), tx)
+	if err != nil {
+		tx.Rollback()
+		return resp, status
+	}
+	mainTable := tables[r.TableId]
+	if mainTable.MergedInto != 0 || mainTable.TableStatus != enums.TableOccupied {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", The main table must be occupied and not merged.")
+		return response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Table ID " + fmt.Sprint(r.TableId) + " must be occupied and not merged into another table.",
+		}, http.StatusConflict
+	}
+	for _, tableId := range r.TableIds {
+		resp, status, err = checkTableState(tables[tableId], "")
+		if err != nil {
+			tx.Rollback()
+			return resp, status
+		}
+	}
+	sessionId, err := s.RestaurantRepo.FindOpenTableSession(r.TableId, tx)
+	var moved int64
+	moves := []model.TableMove{}
+	for _, tableId := range r.TableIds {
+		var tableMoved int64
+		if err == nil {
+			tableMoved, err = s.RestaurantRepo.MoveTableOrders(tableId, r.TableId, true, tx)
+		}
+		if err == nil {
+			err = s.RestaurantRepo.SetTableMergedInto(tableId, r.TableId, tx)
+		}
+		if err == nil {
+			err = s.RestaurantRepo.UpdateTableWithTx(tableId, enums.TableOccupied, tx)
+		}
+		if err == nil {
+			err = s.RestaurantRepo.CloseTableSessionsWithTx(tableId, tx)
+		}
+		if err == nil {
+			err = s.RestaurantRepo.ReleaseNotifiedParties(tableId, 0, tx)
+		}
+		moved += tableMoved
+		moves = append(moves, model.TableMove{TableId: tableId, TableStatus: enums.TableOccupied, MergedInto: r.TableId})
+	}
+	if err == nil && sessionId > 0 {
+		// The party keeps seeing the merged orders in its order history
+		err = s.RestaurantRepo.SetTableOrderSession(r.TableId, sessionId, tx)
+	}
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error merging tables:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Println("RestaurantService -> Error committing transaction:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	log.Println("Transaction committed successfully")
+	moves = append([]model.TableMove{{TableId: r.TableId, TableStatus: enums.TableOccupied, OrdersMoved: int(moved)}}, moves...)
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data:    moves,
+	}, http.StatusOK
+}
+
+// SplitTables takes tables out of a merged group. Each table gets back the orders that came from it,
+// with a new QR session, a table without any orders is freed.
+func (s *RestaurantService) SplitTables(r *request.TableGroupRequest) (response.CustomResponse, int) {
+	log.Println("RestaurantService -> SplitTables")
+	//check input
+	resp, status, err := validateTableGroupRequest(r, false)
+	if err != nil {
+		return resp, status
+	}
+	// Start transaction
+	tx, err := database.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	_, resp, status, err = s.lockTableStates([]int{r.TableId}, tx)
+	if err != nil {
+		tx.Rollback()
+		return resp, status
+	}
+	members, err := s.RestaurantRepo.GetGroupMembers(r.TableId, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error finding merged tables:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	if len(members) == 0 {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Table has no merged tables.")
+		return response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Table ID " + fmt.Sprint(r.TableId) + " has no merged tables.",
+		}, http.StatusConflict
+	}
+	if len(r.TableIds) == 0 {
+		r.TableIds = members
+	}
+	for _, tableId := range r.TableIds {
+		if !slices.Contains(members, tableId) {
+			tx.Rollback()
+			log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table ID " + fmt.Sprint(tableId) + " is not merged into this table.")
+			return response.CustomResponse{
+				Code:    enums.Invalid.GetCode(),
+				Message: enums.Invalid.GetMessage() + ", Table ID " + fmt.Sprint(tableId) + " is not merged into table ID " + fmt.Sprint(r.TableId) + ".",
+			}, http.StatusBadRequest
+		}
+	}
+	moves := []model.TableMove{{TableId: r.TableId, TableStatus: enums.TableOccupied}}
+	for _, tableId := range r.TableIds {
+		move := model.TableMove{TableId: tableId, TableStatus: enums.TableAvailable}
+		var moved int64
+		moved, err = s.RestaurantRepo.ReturnMergedOrders(r.TableId, tableId, tx)
+		if err == nil {
+			err = s.RestaurantRepo.SetTableMergedInto(tableId, 0, tx)
+		}
+		if err == nil && moved > 0 {
+			move.TableStatus = enums.TableOccupied
+			move.OrdersMoved = int(moved)
+			move.TableSession, err = s.moveTableSession(tableId, tx)
+		}
+		if err == nil {
+			err = s.RestaurantRepo.UpdateTableWithTx(tableId, move.TableStatus, tx)
+		}
+		if err != nil {
+			break
+		}
+		moves = append(moves, move)
+	}
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error splitting tables:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Println("RestaurantService -> Error committing transaction:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	log.Println("Transaction committed successfully")
+	for i, move := range moves {
+		if move.TableStatus == enums.TableAvailable {
+			moves[i] = s.freedTableMove(move.TableId)
+		}
+	}
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data:    moves,
+	}, http.StatusOK
+}
+
+func joinWithComma(ids []int) string {
+	notFoundItemsStr := make([]string, len(ids))
+	for i, id := range ids {
+		notFoundItemsStr[i] = fmt.Sprint(id)
+	}
+	return strings.Join(notFoundItemsStr, ", ")
+}
+
+func (s *RestaurantService) CheckTableId(r *request.OrderRequest) (response.CustomResponse, int, error) {
+	existsTableId, err := s.RestaurantRepo.FindTableById(r)
+	if err != nil {
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError, err
+	}
+	if !existsTableId {
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Table ID not found.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Table ID " + fmt.Sprint(r.TableId) + " not found.",
+		}, http.StatusNotFound, fmt.Errorf("table id not found")
+	}
+	return response.CustomResponse{}, http.StatusOK, nil
+}
+
+func (s *RestaurantService) CheckOrderId(r *request.OrderRequest) (response.CustomResponse, int, error) {
+	existsOrderId, err := s.RestaurantRepo.FindOrderById(r)
+	if err != nil {
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError, err
+	}
+	if !existsOrderId {
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Order ID not found.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Order ID " + fmt.Sprint(r.OrderId) + " not found.",
+		}, http.StatusNotFound, fmt.Errorf("order id not found")
+	}
+	return response.CustomResponse{}, http.StatusOK, nil
+}
+func (s *RestaurantService) convertFileToBase64(fileName string) (string, error) {
+	image, err := s.ImageStorage.Open(fileName)
+	if err != nil {
+		return "", err
+	}
+	defer image.Content.Close()
+	data, err := io.ReadAll(image.Content)
+	if err != nil {
+		return "", err
+	}
+	base64Content := base64.StdEncoding.EncodeToString(data)
+	mimeType := http.DetectContentType(data)
+	return "data:" + mimeType + ";base64," + base64Content, nil
+}
+func (s *RestaurantService) CheckMenuItemId(r *request.MenuRequest) (response.CustomResponse, int, error) {
+	existsMenuItemId, err := s.RestaurantRepo.FindMenuItemByMenuRequestId(r)
+	if err != nil {
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError, err
+	}
+	if !existsMenuItemId {
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", MenuItem ID not found.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", MenuItem ID " + fmt.Sprint(r.MenuItemsId) + " not found.",
+		}, http.StatusNotFound, fmt.Errorf("menu item id not found")
+	}
+	return response.CustomResponse{}, http.StatusOK, nil
+}
+
+func validateMenuRequest(r *request.MenuRequest) (response.CustomResponse, int, error) {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Name must not be empty.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Name must not be empty.",
+		}, http.StatusBadRequest, fmt.Errorf("name is empty")
+	}
+	if len(r.Name) > 255 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Name must not exceed 255 characters.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Name must not exceed 255 characters.",
+		}, http.StatusBadRequest, fmt.Errorf("name is too long")
+	}
+	if r.Price <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Price must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Price must be greater than 0.",
+		}, http.StatusBadRequest, fmt.Errorf("price must be greater than 0")
+	}
+	return response.CustomResponse{}, http.StatusOK, nil
+}
+
+func (s *RestaurantService) CheckCategoryId(categoryId int) (response.CustomResponse, int, error) {
+	existsCategoryId, err := s.RestaurantRepo.FindCategoryById(categoryId)
+	if err != nil {
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError, err
+	}
+	if !existsCategoryId {
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Category ID not found.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Category ID " + fmt.Sprint(categoryId) + " not found.",
+		}, http.StatusNotFound, fmt.Errorf("category id not found")
+	}
+	return response.CustomResponse{}, http.StatusOK, nil
+}
+
+func validateCategoryRequest(r *request.CategoryRequest) (response.CustomResponse, int, error) {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Name must not be empty.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Name must not be empty.",
+		}, http.StatusBadRequest, fmt.Errorf("name is empty")
+	}
+	if len(r.Name) > 100 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Name must not exceed 100 characters.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Name must not exceed 100 characters.",
+		}, http.StatusBadRequest, fmt.Errorf("name is too long")
+	}
+	return response.CustomResponse{}, http.StatusOK, nil
+}
+
+func validateMenuFilterRequest(f *request.MenuFilterRequest) (response.CustomResponse, int, error) {
+	invalid := func(reason string) (response.CustomResponse, int, error) {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", " + reason)
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", " + reason,
+		}, http.StatusBadRequest, fmt.Errorf("%s", reason)
+	}
+	f.Name = strings.TrimSpace(f.Name)
+	f.Sort = strings.ToLower(strings.TrimSpace(f.Sort))
+	f.Order = strings.ToLower(strings.TrimSpace(f.Order))
+	if f.MinPrice < 0 || f.MaxPrice < 0 {
+		return invalid("Price range must not be negative.")
+	}
+	if f.MaxPrice > 0 && f.MinPrice > f.MaxPrice {
+		return invalid("minPrice must not be greater than maxPrice.")
+	}
+	switch f.Sort {
+	case "", "category", "name", "price", "newest", "rating":
+	default:
+		return invalid("sort must be one of category, name, price, newest, rating.")
+	}
+	switch f.Order {
+	case "", "asc", "desc":
+	default:
+		return invalid("order must be asc or desc.")
+	}
+	if f.Page < 0 || f.PageSize < 0 {
+		return invalid("page and pageSize must not be negative.")
+	}
+	if f.PageSize > 100 {
+		return invalid("pageSize must not exceed 100.")
+	}
+	if f.Page == 0 {
+		f.Page = 1
+	}
+	return response.CustomResponse{}, http.StatusOK, nil
+}
+
+func (s *RestaurantService) CheckTableNumberAvailable(tableNumber int) (response.CustomResponse, int, error) {
+	table, err := s.RestaurantRepo.FindTableByNumber(tableNumber)
+	if err != nil {
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError, err
+	}
+	if table != nil {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table number already exists.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Table number " + fmt.Sprint(tableNumber) + " already exists.",
+		}, http.StatusBadRequest, fmt.Errorf("table number already exists")
+	}
+	return response.CustomResponse{}, http.StatusOK, nil
+}
+
+// lockOrderTransition locks the order on the table for the rest of tx and checks it may move to
+// nextStatus, so concurrent status changes are checked one after the other.
+func (s *RestaurantService) lockOrderTransition(orderId int, tableId int, nextStatus string, tx *sql.Tx) (response.CustomResponse, int, error) {
+	currentStatus, _, err := s.RestaurantRepo.LockOrder(orderId, tableId, tx)
+	if err != nil {
+		log.Println("RestaurantService -> Error locking order:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError, err
+	}
+	if currentStatus == "" {
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Order ID not found on this table.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Order ID " + fmt.Sprint(orderId) + " not found on table ID " + fmt.Sprint(tableId) + ".",
+		}, http.StatusNotFound, fmt.Errorf("order not found")
+	}
+	return checkOrderTransition(currentStatus, nextStatus)
+}
+
+func checkOrderTransition(currentStatus string, nextStatus string) (response.CustomResponse, int, error) {
+	if !enums.CanTransitionOrder(currentStatus, nextStatus) {
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Order cannot move from " + currentStatus + " to " + nextStatus + ".")
+		return response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Order cannot move from " + currentStatus + " to " + nextStatus + ".",
+		}, http.StatusConflict, fmt.Errorf("invalid order status transition")
+	}
+	return response.CustomResponse{}, http.StatusOK, nil
+}
+
+// openTableSession closes any previous session on the table and issues, inside tx, a signed
+// token that customers present with their orders until checkout.
+func (s *RestaurantService) openTableSession(tableId int, tx *sql.Tx) (*model.TableSession, error) {
+	err := s.RestaurantRepo.CloseTableSessionsWithTx(tableId, tx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	expiresAt := now.Add(s.TableSessionTTL)
+	sessionId, err := s.RestaurantRepo.InsertTableSessionWithTx(tableId, expiresAt, tx)
+	if err != nil {
+		return nil, err
+	}
+	return s.signTableSession(tableId, int(sessionId), now, expiresAt)
+}
+
+// signTableSession issues the token and QR content of a table session.
+func (s *RestaurantService) signTableSession(tableId int, sessionId int, now time.Time, expiresAt time.Time) (*model.TableSession, error) {
+	token, err := security.SignToken(security.Claims{
+		Role:      enums.RoleTable,
+		TableId:   tableId,
+		SessionId: sessionId,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	}, s.TokenSecret)
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	query.Set("table", fmt.Sprint(tableId))
+	query.Set("session", token)
+	return &model.TableSession{
+		SessionId: sessionId,
+		TableId:   tableId,
+		Token:     token,
+		ExpiresAt: config.FormatTime(expiresAt),
+		QrContent: s.OrderBaseURL + "?" + query.Encode(),
+	}, nil
+}
+
+// syncOrderStatus moves the order to the status derived from its items when the
+// order state machine allows it, and returns the resulting order status.
+func (s *RestaurantService) syncOrderStatus(orderId int, tableId int, currentStatus string, tx *sql.Tx) (string, error) {
+	itemStatuses, err := s.RestaurantRepo.GetOrderItemStatuses(orderId, tx)
+	if err != nil {
+		return "", err
+	}
+	derivedStatus := enums.DeriveOrderStatus(itemStatuses)
+	if derivedStatus == currentStatus || !enums.CanTransitionOrder(currentStatus, derivedStatus) {
+		return currentStatus, nil
+	}
+	err = s.RestaurantRepo.UpdateOrderWithTx(tableId, orderId, derivedStatus, tx)
+	if err != nil {
+		return "", err
+	}
+	return derivedStatus, nil
+}
+
+// openBill returns the bill of the order, pricing it from the order items the first time. Coupon
+// codes given later price the bill again, which is only allowed before anything was paid.
+func (s *RestaurantService) openBill(orderId int, tableId int, couponCodes []string, tx *sql.Tx) (*model.Bill, response.CustomResponse, int, error) {
+	couponCodes = normalizeCouponCodes(couponCodes)
+	bill, err := s.RestaurantRepo.FindBillByOrderId(orderId, tx)
+	if err != nil {
+		log.Println("RestaurantService -> Error finding bill:", err)
+		return nil, response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError, err
+	}
+	if bill != nil {
+		if len(couponCodes) == 0 {
+			return bill, response.CustomResponse{}, http.StatusOK, nil
+		}
+		if toCents(bill.PaidAmount) > 0 {
+			log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Coupons can only be applied before the first payment.")
+			return nil, response.CustomResponse{
+				Code:    enums.InvalidTransition.GetCode(),
+				Message: enums.InvalidTransition.GetMessage() + ", Coupons can only be applied before the first payment.",
+			}, http.StatusConflict, fmt.Errorf("bill already partly paid")
+		}
+		err = s.RestaurantRepo.ReleaseBillPromotions(bill.BillId, tx)
+		if err != nil {
+			log.Println("RestaurantService -> Error releasing bill promotions:", err)
+			return nil, response.CustomResponse{
+				Code:    enums.Error.GetCode(),
+				Message: enums.Error.GetMessage(),
+			}, http.StatusInternalServerError, err
+		}
+	} else {
+		bill = &model.Bill{OrderId: orderId, TableId: tableId}
+	}
+	resp, status, err := s.priceBill(bill, couponCodes, tx)
+	if err != nil {
+		return nil, resp, status, err
+	}
+	bill, err = s.RestaurantRepo.FindBillByOrderId(orderId, tx)
+	if err != nil {
+		log.Println("RestaurantService -> Error finding bill:", err)
+		return nil, response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError, err
+	}
+	return bill, response.CustomResponse{}, http.StatusOK, nil
+}
+
+// priceBill applies the promotions in effect and the bill policy to the order items and saves
+// the result, inserting the bill when it has no id yet. Every coupon code must take something off.
+func (s *RestaurantService) priceBill(bill *model.Bill, couponCodes []string, tx *sql.Tx) (response.CustomResponse, int, error) {
+	internalError := func(action string, err error) (response.CustomResponse, int, error) {
+		log.Println("RestaurantService -> Error "+action+":", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError, err
+	}
+	items, err := s.RestaurantRepo.GetUnpaidBillItems(bill.OrderId, tx)
+	if err != nil {
+		return internalError("getting bill items", err)
+	}
+	if len(items) == 0 {
+		return internalError("pricing bill", fmt.Errorf("order %d has no billable items", bill.OrderId))
+	}
+	promotions, err := s.RestaurantRepo.GetActivePromotions(couponCodes, tx)
+	if err != nil {
+		return internalError("getting promotions", err)
+	}
+	var usedUp []string
+	for _, promotion := range promotions {
+		if promotion.CouponCode != "" && promotion.UsageLimit > 0 && promotion.UsedCount >= promotion.UsageLimit {
+			usedUp = append(usedUp, promotion.CouponCode)
+		}
+	}
+	if len(usedUp) > 0 {
+		joinCodes := strings.Join(usedUp, ", ")
+		log.Println("RestaurantService -> "+enums.InvalidTransition.GetMessage()+", Coupons are used up: ", joinCodes)
+		return response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Coupons are used up: " + joinCodes,
+		}, http.StatusConflict, fmt.Errorf("coupons used up")
+	}
+	applied, lineLeft := applyPromotions(items, promotions, time.Now())
+	usedCodes := make(map[string]bool)
+	for _, promotion := range applied {
+		usedCodes[promotion.CouponCode] = true
+	}
+	var rejectedCodes []string
+	for _, code := range couponCodes {
+		if !usedCodes[code] {
+			rejectedCodes = append(rejectedCodes, code)
+		}
+	}
+	if len(rejectedCodes) > 0 {
+		joinCodes := strings.Join(rejectedCodes, ", ")
+		log.Println("RestaurantService -> "+enums.Invalid.GetMessage()+", Coupons cannot be used on this bill: ", joinCodes)
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Coupons cannot be used on this bill: " + joinCodes,
+		}, http.StatusBadRequest, fmt.Errorf("coupons rejected")
+	}
+
+	var subtotal, discount int64
+	for _, item := range items {
+		subtotal += toCents(item.Amount)
+	}
+	for _, promotion := range applied {
+		discount += toCents(promotion.Discount)
+		if promotion.CouponCode == "" {
+			continue
+		}
+		// The coupon rows are locked, so the usage checked above still holds
+		used, err := s.RestaurantRepo.UsePromotion(promotion.PromotionId, tx)
+		if err != nil {
+			return internalError("using promotion", err)
+		}
+		if !used {
+			return internalError("using promotion", fmt.Errorf("coupon %s has no uses left", promotion.CouponCode))
+		}
+	}
+	s.BillPolicy.Apply(bill, subtotal, discount)
+	allocateLines(items, lineLeft, toCents(bill.TotalAmount))
+	if bill.BillId == 0 {
+		billId, err := s.RestaurantRepo.InsertBill(bill, tx)
+		if err != nil {
+			return internalError("creating bill", err)
+		}
+		bill.BillId = int(billId)
+	} else {
+		err = s.RestaurantRepo.UpdateBillAmounts(bill, tx)
+		if err != nil {
+			return internalError("updating bill", err)
+		}
+	}
+	err = s.RestaurantRepo.InsertBillPromotions(bill.BillId, applied, tx)
+	if err != nil {
+		return internalError("recording bill promotions", err)
+	}
+	err = s.RestaurantRepo.InsertBillItems(bill.BillId, items, tx)
+	if err != nil {
+		return internalError("recording bill items", err)
+	}
+	return response.CustomResponse{}, http.StatusOK, nil
+}
+
+// loadBill returns the bill of the order with its live payments, or nil when no bill was opened.
+func (s *RestaurantService) loadBill(orderId int, tx *sql.Tx) (*model.Bill, error) {
+	bill, err := s.RestaurantRepo.FindBillByOrderId(orderId, tx)
+	if err != nil || bill == nil {
+		return bill, err
+	}
+	bill.Promotions, err = s.RestaurantRepo.GetBillPromotions(bill.BillId, tx)
+	if err != nil {
+		return nil, err
+	}
+	bill.Payments, err = s.RestaurantRepo.GetBillPayments(bill.BillId, tx)
+	if err != nil {
+		return nil, err
+	}
+	bill.Refunds, err = s.RestaurantRepo.GetBillRefunds(bill.BillId, tx)
+	if err != nil {
+		return nil, err
+	}
+	bill.Remaining = fromCents(toCents(bill.TotalAmount) - toCents(bill.PaidAmount))
+	return bill, nil
+}
+
+// settleTenders records the tenders charged for a pending payment in a second transaction, once
+// the providers took the money. The payment must still be pending and fit what is left on the
+// bill, otherwise another payment got there first and the charges are voided. The order becomes
+// paid once its bill is settled.
+func (s *RestaurantService) settleTenders(orderId int, tableId int, paymentId int, tenders []model.Tender) (response.CustomResponse, int) {
+	committed := false
+	defer func() {
+		if !committed {
+			s.voidTenders(tenders)
+		}
+	}()
+	// Start transaction
+	tx, err := database.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting transaction, payment %d stays pending: %v", paymentId, err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	internalError := func(action string, err error) (response.CustomResponse, int) {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error "+action+", payment "+fmt.Sprint(paymentId)+" stays pending:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	orderStatus, _, err := s.RestaurantRepo.LockOrder(orderId, tableId, tx)
+	if err != nil {
+		return internalError("locking order", err)
+	}
+	respTransition, status, err := checkOrderTransition(orderStatus, enums.OrderPaid)
+	if err != nil {
+		tx.Rollback()
+		return respTransition, status
+	}
+	bill, err := s.RestaurantRepo.FindBillByOrderId(orderId, tx)
+	if err != nil {
+		return internalError("finding bill", err)
+	}
+	payment, err := s.RestaurantRepo.FindPaymentById(paymentId, tx)
+	if err != nil {
+		return internalError("finding payment", err)
+	}
+	if bill == nil || payment == nil || payment.BillId != bill.BillId || payment.Status != enums.PaymentPending ||
+		toCents(payment.Amount) > toCents(bill.TotalAmount)-toCents(bill.PaidAmount) {
+		tx.Rollback()
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Payment ID " + fmt.Sprint(paymentId) + " changed while it was being charged.")
+		return response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Payment ID " + fmt.Sprint(paymentId) + " changed while it was being charged, the charge was voided.",
+		}, http.StatusConflict
+	}
+	err = s.RestaurantRepo.InsertTenders(paymentId, tenders, tx)
+	if err != nil {
+		return internalError("recording tenders", err)
+	}
+	err = s.RestaurantRepo.SettlePayment(paymentId, tx)
+	if err != nil {
+		return internalError("settling payment", err)
+	}
+	err = s.RestaurantRepo.AddBillPaidAmount(bill.BillId, payment.Amount, tx)
+	if err != nil {
+		return internalError("updating bill", err)
+	}
+	bill, err = s.loadBill(orderId, tx)
+	if err != nil {
+		return internalError("loading bill", err)
+	}
+	// The order is paid once the last share of its bill is settled
+	if bill.Status == enums.BillSettled {
+		err = s.RestaurantRepo.UpdateOrderWithTx(tableId, orderId, enums.OrderPaid, tx)
+		if err != nil {
+			return internalError("updating order", err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Println("RestaurantService -> Error committing transaction, payment "+fmt.Sprint(paymentId)+" stays pending:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	committed = true
+	log.Println("Transaction committed successfully")
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data:    bill,
+	}, http.StatusOK
+}
+
+// cancelPayment drops a pending payment whose tenders could not be charged. When that fails the
+// payment stays pending until the next payment of the order cancels it.
+func (s *RestaurantService) cancelPayment(paymentId int) {
+	// Start transaction
+	tx, err := database.DB.Begin()
+	if err != nil {
+		log.Printf("Error starting transaction, payment %d stays pending: %v", paymentId, err)
+		return
+	}
+	err = s.RestaurantRepo.CancelPayment(paymentId, tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error canceling payment "+fmt.Sprint(paymentId)+":", err)
+		return
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Println("RestaurantService -> Error committing transaction, payment "+fmt.Sprint(paymentId)+" stays pending:", err)
+		return
+	}
+	log.Println("Transaction committed successfully")
+}
+
+// matchTenders checks that the tenders pay exactly the amount due. No tenders pay it all in cash
+// and a single tender without an amount pays all of it.
+func matchTenders(due int64, tenders []request.PaymentTender) ([]request.PaymentTender, response.CustomResponse, int, error) {
+	if len(tenders) == 0 {
+		if due == 0 {
+			return nil, response.CustomResponse{}, http.StatusOK, nil
+		}
+		tenders = []request.PaymentTender{{Method: enums.MethodCash, Amount: fromCents(due)}}
+	}
+	if len(tenders) == 1 && toCents(tenders[0].Amount) == 0 {
+		tenders[0].Amount = fromCents(due)
+	}
+	var total int64
+	for _, tender := range tenders {
+		total += toCents(tender.Amount)
+	}
+	if total != due {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Tenders do not add up to the amount due.")
+		return nil, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Tenders add up to " + fmt.Sprintf("%.2f", fromCents(total)) + " but " + fmt.Sprintf("%.2f", fromCents(due)) + " is due.",
+		}, http.StatusBadRequest, fmt.Errorf("tenders do not match amount due")
+	}
+	return tenders, response.CustomResponse{}, http.StatusOK, nil
+}
+
+// collectTenders charges each tender through the provider of its method so that together they pay
+// exactly the amount due. When a tender fails the tenders charged before it are voided. It runs
+// outside any transaction, a provider may take up to paymentChargeTimeout to answer.
+func (s *RestaurantService) collectTenders(due int64, tenders []request.PaymentTender) ([]model.Tender, response.CustomResponse, int, error) {
+	tenders, resp, status, err := matchTenders(due, tenders)
+	if err != nil {
+		return nil, resp, status, err
+	}
+	if len(tenders) == 0 {
+		return nil, response.CustomResponse{}, http.StatusOK, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), paymentChargeTimeout)
+	defer cancel()
+	var charged []model.Tender
+	for _, tender := range tenders {
+		provider, ok := s.PaymentProviders.Provider(tender.Method)
+		if !ok {
+			s.voidTenders(charged)
+			log.Println("RestaurantService -> No payment provider configured for", tender.Method)
+			return nil, response.CustomResponse{
+				Code:    enums.Error.GetCode(),
+				Message: enums.Error.GetMessage(),
+			}, http.StatusInternalServerError, fmt.Errorf("no payment provider for %s", tender.Method)
+		}
+		result, err := provider.Charge(ctx, payment.ChargeRequest{
+			Method:    tender.Method,
+			Amount:    toCents(tender.Amount),
+			Tendered:  toCents(tender.Tendered),
+			Reference: tender.Reference,
+		})
+		if err != nil {
+			s.voidTenders(charged)
+			switch {
+			case errors.Is(err, payment.ErrInsufficientCash):
+				log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Cash tendered is less than the amount due.")
+				return nil, response.CustomResponse{
+					Code:    enums.Invalid.GetCode(),
+					Message: enums.Invalid.GetMessage() + ", Cash tendered is less than the amount due.",
+				}, http.StatusBadRequest, err
+			case errors.Is(err, payment.ErrDeclined):
+				log.Println("RestaurantService -> " + enums.PaymentDeclined.GetMessage() + ", " + tender.Method + " payment was declined.")
+				return nil, response.CustomResponse{
+					Code:    enums.PaymentDeclined.GetCode(),
+					Message: enums.PaymentDeclined.GetMessage() + ", " + tender.Method + " payment was declined.",
+				}, http.StatusPaymentRequired, err
+			}
+			log.Println("RestaurantService -> Error charging "+tender.Method+":", err)
+			return nil, response.CustomResponse{
+				Code:    enums.Error.GetCode(),
+				Message: enums.Error.GetMessage(),
+			}, http.StatusInternalServerError, err
+		}
+		reference := result.Reference
+		if reference == "" {
+			reference = tender.Reference
+		}
+		charged = append(charged, model.Tender{
+			Method:    tender.Method,
+			Amount:    tender.Amount,
+			Tendered:  fromCents(result.Tendered),
+			Change:    fromCents(result.Change),
+			Reference: reference,
+			Provider:  result.Provider,
+		})
+	}
+	return charged, response.CustomResponse{}, http.StatusOK, nil
+}
+
+// voidTenders reverses charges whose payment could not be recorded.
+func (s *RestaurantService) voidTenders(tenders []model.Tender) {
+	ctx, cancel := context.WithTimeout(context.Background(), paymentChargeTimeout)
+	defer cancel()
+	for _, tender := range tenders {
+		provider, ok := s.PaymentProviders.Provider(tender.Method)
+		if !ok {
+			continue
+		}
+		if err := provider.Void(ctx, tender.Reference); err != nil {
+			log.Println("RestaurantService -> Error voiding "+tender.Method+" charge "+tender.Reference+":", err)
+		}
+	}
+}
+
+func validatePaymentTenders(tenders []request.PaymentTender) (response.CustomResponse, int, error) {
+	for i, tender := range tenders {
+		if !enums.IsPaymentMethod(tender.Method) {
+			log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Tender " + fmt.Sprint(i+1) + " method must be one of cash, card or promptpay.")
+			return response.CustomResponse{
+				Code:    enums.Invalid.GetCode(),
+				Message: enums.Invalid.GetMessage() + ", Tender " + fmt.Sprint(i+1) + " method must be one of cash, card or promptpay.",
+			}, http.StatusBadRequest, fmt.Errorf("invalid tender method")
+		}
+		if tender.Amount < 0 || tender.Tendered < 0 || (len(tenders) > 1 && toCents(tender.Amount) == 0) {
+			log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Tender " + fmt.Sprint(i+1) + " amount must be greater than 0.")
+			return response.CustomResponse{
+				Code:    enums.Invalid.GetCode(),
+				Message: enums.Invalid.GetMessage() + ", Tender " + fmt.Sprint(i+1) + " amount must be greater than 0.",
+			}, http.StatusBadRequest, fmt.Errorf("invalid tender amount")
+		}
+		// Only cash can be handed over in excess and get change back
+		if tender.Method != enums.MethodCash && tender.Tendered != 0 && toCents(tender.Tendered) != toCents(tender.Amount) {
+			log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Tender " + fmt.Sprint(i+1) + " only cash can be tendered above the amount.")
+			return response.CustomResponse{
+				Code:    enums.Invalid.GetCode(),
+				Message: enums.Invalid.GetMessage() + ", Tender " + fmt.Sprint(i+1) + " only cash can be tendered above the amount.",
+			}, http.StatusBadRequest, fmt.Errorf("invalid tendered amount")
+		}
+		if len(tender.Reference) > 100 {
+			log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Tender " + fmt.Sprint(i+1) + " reference must not exceed 100 characters.")
+			return response.CustomResponse{
+				Code:    enums.Invalid.GetCode(),
+				Message: enums.Invalid.GetMessage() + ", Tender " + fmt.Sprint(i+1) + " reference must not exceed 100 characters.",
+			}, http.StatusBadRequest, fmt.Errorf("invalid tender reference")
+		}
+	}
+	return response.CustomResponse{}, http.StatusOK, nil
+}
+
+func validateBillIds(orderId int, tableId int) (response.CustomResponse, int, error) {
+	if tableId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Table ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Table ID must be greater than 0.",
+		}, http.StatusBadRequest, fmt.Errorf("invalid table id")
+	}
+	if orderId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Order ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Order ID must be greater than 0.",
+		}, http.StatusBadRequest, fmt.Errorf("invalid order id")
+	}
+	return response.CustomResponse{}, http.StatusOK, nil
+}
+
+func validateBillSplitRequest(r *request.BillSplitRequest) (response.CustomResponse, int, error) {
+	if !enums.IsSplitMode(r.Mode) {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Mode must be one of item, even or custom.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Mode must be one of item, even or custom.",
+		}, http.StatusBadRequest, fmt.Errorf("invalid split mode")
+	}
+	if r.Mode == enums.SplitEven {
+		if r.Parts < 2 || r.Parts > maxBillSplitParts {
+			log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Parts must be between 2 and " + fmt.Sprint(maxBillSplitParts) + ".")
+			return response.CustomResponse{
+				Code:    enums.Invalid.GetCode(),
+				Message: enums.Invalid.GetMessage() + ", Parts must be between 2 and " + fmt.Sprint(maxBillSplitParts) + ".",
+			}, http.StatusBadRequest, fmt.Errorf("invalid split parts")
+		}
+		return response.CustomResponse{}, http.StatusOK, nil
+	}
+	if len(r.Shares) == 0 || len(r.Shares) > maxBillSplitParts {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Shares must contain between 1 and " + fmt.Sprint(maxBillSplitParts) + " entries.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Shares must contain between 1 and " + fmt.Sprint(maxBillSplitParts) + " entries.",
+		}, http.StatusBadRequest, fmt.Errorf("invalid split shares")
+	}
+	for i, share := range r.Shares {
+		if len(share.Label) > 50 {
+			log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Share label must not exceed 50 characters.")
+			return response.CustomResponse{
+				Code:    enums.Invalid.GetCode(),
+				Message: enums.Invalid.GetMessage() + ", Share label must not exceed 50 characters.",
+			}, http.StatusBadRequest, fmt.Errorf("invalid share label")
+		}
+		if r.Mode == enums.SplitCustom && toCents(share.Amount) <= 0 {
+			log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Share " + fmt.Sprint(i+1) + " amount must be greater than 0.")
+			return response.CustomResponse{
+				Code:    enums.Invalid.GetCode(),
+				Message: enums.Invalid.GetMessage() + ", Share " + fmt.Sprint(i+1) + " amount must be greater than 0.",
+			}, http.StatusBadRequest, fmt.Errorf("invalid share amount")
+		}
+		if r.Mode == enums.SplitItem && len(share.OrderItemIds) == 0 {
+			log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Share " + fmt.Sprint(i+1) + " orderItemIds must not be empty.")
+			return response.CustomResponse{
+				Code:    enums.Invalid.GetCode(),
+				Message: enums.Invalid.GetMessage() + ", Share " + fmt.Sprint(i+1) + " orderItemIds must not be empty.",
+			}, http.StatusBadRequest, fmt.Errorf("invalid share items")
+		}
+	}
+	return response.CustomResponse{}, http.StatusOK, nil
+}
+
+// splitEvenly divides the remaining amount into parts, the first shares absorb the leftover satang.
+func splitEvenly(billId int, remaining int64, parts int) []model.Payment {
+	share := remaining / int64(parts)
+	leftover := remaining % int64(parts)
+	payments := make([]model.Payment, 0, parts)
+	for i := 0; i < parts; i++ {
+		amount := share
+		if int64(i) < leftover {
+			amount++
+		}
+		payments = append(payments, model.Payment{
+			BillId:    billId,
+			Label:     shareLabel("", i),
+			SplitMode: enums.SplitEven,
+			Amount:    fromCents(amount),
+		})
+	}
+	return payments
+}
+
+// splitByAmount turns custom amounts into shares, anything not claimed becomes one more share.
+func splitByAmount(billId int, remaining int64, shares []request.BillSplitShare) ([]model.Payment, response.CustomResponse, int, error) {
+	var payments []model.Payment
+	var total int64
+	for i, share := range shares {
+		amount := toCents(share.Amount)
+		total += amount
+		payments = append(payments, model.Payment{
+			BillId:    billId,
+			Label:     shareLabel(share.Label, i),
+			SplitMode: enums.SplitCustom,
+			Amount:    fromCents(amount),
+		})
+	}
+	if total > remaining {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Shares add up to more than the amount left on the bill.")
+		return nil, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Shares add up to " + fmt.Sprintf("%.2f", fromCents(total)) + " but only " + fmt.Sprintf("%.2f", fromCents(remaining)) + " is left on the bill.",
+		}, http.StatusBadRequest, fmt.Errorf("shares exceed bill")
+	}
+	if total < remaining {
+		payments = append(payments, model.Payment{
+			BillId:    billId,
+			Label:     "Remaining",
+			SplitMode: enums.SplitCustom,
+			Amount:    fromCents(remaining - total),
+		})
+	}
+	return payments, response.CustomResponse{}, http.StatusOK, nil
+}
+
+// splitByItem charges each share for the order items it lists, unlisted items become one more share.
+// Service charge, VAT and rounding are spread over the shares in proportion to their items, the
+// last share absorbs the leftover satang so the shares always add up to the remaining amount.
+func splitByItem(billId int, remaining int64, items []model.BillItem, shares []request.BillSplitShare) ([]model.Payment, response.CustomResponse, int, error) {
+	unpaid := make(map[int]int64)
+	var itemsTotal int64
+	for _, item := range items {
+		unpaid[item.OrderItemId] = toCents(item.Amount)
+		itemsTotal += toCents(item.Amount)
+	}
+	var payments []model.Payment
+	var itemAmounts []int64
+	var unknownItems []int
+	for i, share := range shares {
+		payment := model.Payment{
+			BillId:    billId,
+			Label:     shareLabel(share.Label, i),
+			SplitMode: enums.SplitItem,
+		}
+		var amount int64
+		for _, orderItemId := range share.OrderItemIds {
+			itemAmount, ok := unpaid[orderItemId]
+			if !ok {
+				unknownItems = append(unknownItems, orderItemId)
+				continue
+			}
+			delete(unpaid, orderItemId)
+			amount += itemAmount
+			payment.OrderItemIds = append(payment.OrderItemIds, orderItemId)
+		}
+		payments = append(payments, payment)
+		itemAmounts = append(itemAmounts, amount)
+	}
+	if len(unknownItems) > 0 {
+		joinIDS := joinWithComma(unknownItems)
+		log.Println("RestaurantService -> "+enums.NotFound.GetMessage()+", Order items not unpaid on this bill or listed twice: ", joinIDS)
+		return nil, response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Order items not unpaid on this bill or listed twice: " + joinIDS,
+		}, http.StatusNotFound, fmt.Errorf("unknown order items")
+	}
+	if len(unpaid) > 0 {
+		rest := model.Payment{
+			BillId:    billId,
+			Label:     "Remaining",
+			SplitMode: enums.SplitItem,
+		}
+		var amount int64
+		for _, item := range items {
+			if itemAmount, ok := unpaid[item.OrderItemId]; ok {
+				amount += itemAmount
+				rest.OrderItemIds = append(rest.OrderItemIds, item.OrderItemId)
+			}
+		}
+		payments = append(payments, rest)
+		itemAmounts = append(itemAmounts, amount)
+	}
+	var allocated int64
+	for i := range payments {
+		amount := remaining - allocated
+		if i < len(payments)-1 && itemsTotal > 0 {
+			amount = itemAmounts[i] * remaining / itemsTotal
+		}
+		allocated += amount
+		payments[i].Amount = fromCents(amount)
+	}
+	return payments, response.CustomResponse{}, http.StatusOK, nil
+}
+
+func shareLabel(label string, index int) string {
+	if strings.TrimSpace(label) != "" {
+		return strings.TrimSpace(label)
+	}
+	return "Guest " + fmt.Sprint(index+1)
+}
+
+// toCents converts a baht amount to satang so bill arithmetic stays exact.
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func fromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
+
+func (s *RestaurantService) CheckPromotionId(promotionId int) (response.CustomResponse, int, error) {
+	existsPromotionId, err := s.RestaurantRepo.FindPromotionById(promotionId)
+	if err != nil {
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError, err
+	}
+	if !existsPromotionId {
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Promotion ID not found.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Promotion ID " + fmt.Sprint(promotionId) + " not found.",
+		}, http.StatusNotFound, fmt.Errorf("promotion id not found")
+	}
+	return response.CustomResponse{}, http.StatusOK, nil
+}
+
+func (s *RestaurantService) validatePromotionRequest(r *request.PromotionRequest) (response.CustomResponse, int, error) {
+	invalid := func(reason string) (response.CustomResponse, int, error) {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", " + reason)
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", " + reason,
+		}, http.StatusBadRequest, fmt.Errorf("invalid promotion request")
+	}
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return invalid("Name must not be empty.")
+	}
+	if len(r.Name) > 100 {
+		return invalid("Name must not exceed 100 characters.")
+	}
+	if !enums.IsPromotionType(r.RuleType) {
+		return invalid("Rule type must be one of percent, fixed, item_percent, item_fixed or bogo.")
+	}
+	switch r.RuleType {
+	case enums.PromotionPercent, enums.PromotionItemPercent:
+		if r.Value <= 0 || r.Value > 100 {
+			return invalid("Value must be a percentage between 0 and 100.")
+		}
+	case enums.PromotionFixed, enums.PromotionItemFixed:
+		if toCents(r.Value) <= 0 {
+			return invalid("Value must be greater than 0.")
+		}
+	case enums.PromotionBogo:
+		r.Value = 0
+	}
+	if r.MinSubtotal < 0 {
+		return invalid("Min subtotal must not be negative.")
+	}
+	if r.UsageLimit < 0 {
+		return invalid("Usage limit must not be negative.")
+	}
+	var startsAt, endsAt time.Time
+	var err error
+	if r.StartsAt != "" {
+		if startsAt, err = time.ParseInLocation("2006/01/02 15:04:05", r.StartsAt, time.Local); err != nil {
+			return invalid("Starts at must use the format 2006/01/02 15:04:05.")
+		}
+	}
+	if r.EndsAt != "" {
+		if endsAt, err = time.ParseInLocation("2006/01/02 15:04:05", r.EndsAt, time.Local); err != nil {
+			return invalid("Ends at must use the format 2006/01/02 15:04:05.")
+		}
+	}
+	if r.StartsAt != "" && r.EndsAt != "" && !endsAt.After(startsAt) {
+		return invalid("Ends at must be after starts at.")
+	}
+	if (r.HappyHourStart == "") != (r.HappyHourEnd == "") {
+		return invalid("Happy hour start and end must be given together.")
+	}
+	if r.HappyHourStart != "" {
+		start, errStart := time.Parse("15:04", r.HappyHourStart)
+		end, errEnd := time.Parse("15:04", r.HappyHourEnd)
+		if errStart != nil || errEnd != nil {
+			return invalid("Happy hour start and end must use the format 15:04.")
+		}
+		if start.Equal(end) {
+			return invalid("Happy hour start and end must differ.")
+		}
+	}
+	r.CouponCode = strings.ToUpper(strings.TrimSpace(r.CouponCode))
+	if len(r.CouponCode) > 30 {
+		return invalid("Coupon code must not exceed 30 characters.")
+	}
+	for _, c := range r.CouponCode {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '-' && c != '_' {
+			return invalid("Coupon code may only contain letters, digits, - and _.")
+		}
+	}
+	// Automatic promotions apply to every bill in their window, only coupons count their uses
+	if r.UsageLimit > 0 && r.CouponCode == "" {
+		return invalid("Usage limit only applies to promotions with a coupon code.")
+	}
+	if r.CouponCode != "" {
+		taken, err := s.RestaurantRepo.IsCouponCodeTaken(r.CouponCode, r.PromotionId)
+		if err != nil {
+			return response.CustomResponse{
+				Code:    enums.Error.GetCode(),
+				Message: enums.Error.GetMessage(),
+			}, http.StatusInternalServerError, err
+		}
+		if taken {
+			return invalid("Coupon code " + r.CouponCode + " already exists.")
+		}
+	}
+	if !enums.IsItemPromotion(r.RuleType) {
+		if r.MenuItemId != 0 {
+			return invalid("Menu item ID only applies to item_percent, item_fixed and bogo rules.")
+		}
+		return response.CustomResponse{}, http.StatusOK, nil
+	}
+	if r.MenuItemId <= 0 {
+		return invalid("Menu item ID must be greater than 0 for item rules.")
+	}
+	return s.CheckMenuItemId(&request.MenuRequest{MenuItemsId: r.MenuItemId})
+}
+
+func validateRefundRequest(r *request.RefundRequest) (response.CustomResponse, int, error) {
+	resp, status, err := validateBillIds(r.OrderId, r.TableId)
+	if err != nil {
+		return resp, status, err
+	}
+	if !enums.IsRefundKind(r.Kind) {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Kind must be refund or void.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Kind must be refund or void.",
+		}, http.StatusBadRequest, fmt.Errorf("invalid refund kind")
+	}
+	if !enums.IsRefundReason(r.ReasonCode) {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Reason code " + r.ReasonCode + " is not valid.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Reason code must be one of customer_complaint, wrong_item, quality, duplicate_charge, operator_error or other.",
+		}, http.StatusBadRequest, fmt.Errorf("invalid refund reason")
+	}
+	if r.ReasonCode == enums.ReasonOther && strings.TrimSpace(r.Note) == "" {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Note is required when the reason is other.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Note is required when the reason is other.",
+		}, http.StatusBadRequest, fmt.Errorf("missing refund note")
+	}
+	if len(r.Note) > 255 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Note must not exceed 255 characters.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Note must not exceed 255 characters.",
+		}, http.StatusBadRequest, fmt.Errorf("invalid refund note")
+	}
+	if r.Kind == enums.RefundKindVoid && len(r.OrderItemIds) > 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", A void covers the whole bill and takes no order items.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", A void covers the whole bill and takes no order items.",
+		}, http.StatusBadRequest, fmt.Errorf("void with order items")
+	}
+	if r.UserId <= 0 {
+		log.Println("RestaurantService -> " + enums.Unauthorized.GetMessage() + ", Refunds need an authorizing user.")
+		return response.CustomResponse{
+			Code:    enums.Unauthorized.GetCode(),
+			Message: enums.Unauthorized.GetMessage() + ", Refunds need an authorizing user.",
+		}, http.StatusUnauthorized, fmt.Errorf("missing authorizing user")
+	}
+	seen := make(map[int]bool)
+	for _, orderItemId := range r.OrderItemIds {
+		if orderItemId <= 0 || seen[orderItemId] {
+			log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Order item IDs must be positive and unique.")
+			return response.CustomResponse{
+				Code:    enums.Invalid.GetCode(),
+				Message: enums.Invalid.GetMessage() + ", Order item IDs must be positive and unique.",
+			}, http.StatusBadRequest, fmt.Errorf("invalid refund order item")
+		}
+		seen[orderItemId] = true
+	}
+	return response.CustomResponse{}, http.StatusOK, nil
+}
+
+// buildRefund works out what the refund gives back. Each item returns the share of the bill total it
+// was charged, after its own BOGO or item discount and its part of the order discounts, with service
+// charge and VAT. Refunding the last items of a bill, or the whole bill, gives back exactly what is
+// left so no satang stays behind.
+func (s *RestaurantService) buildRefund(bill *model.Bill, r *request.RefundRequest, tx *sql.Tx) (*model.Refund, response.CustomResponse, int, error) {
+	refund := &model.Refund{
+		BillId:       bill.BillId,
+		Kind:         r.Kind,
+		ReasonCode:   r.ReasonCode,
+		Note:         strings.TrimSpace(r.Note),
+		AuthorizedBy: r.UserId,
+	}
+	left := toCents(bill.PaidAmount) - toCents(bill.RefundedAmount)
+	if left <= 0 {
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Nothing is left to refund on this bill.")
+		return nil, response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Nothing is left to refund on this bill.",
+		}, http.StatusConflict, fmt.Errorf("bill fully refunded")
+	}
+	items, err := s.RestaurantRepo.GetOrderBillItems(bill.OrderId, tx)
+	if err != nil {
+		log.Println("RestaurantService -> Error getting order items:", err)
+		return nil, response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError, err
+	}
+	refundedIds, err := s.RestaurantRepo.GetRefundedItemIds(bill.BillId, tx)
+	if err != nil {
+		log.Println("RestaurantService -> Error getting refunded items:", err)
+		return nil, response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError, err
+	}
+	refunded := make(map[int]bool, len(refundedIds))
+	for _, orderItemId := range refundedIds {
+		refunded[orderItemId] = true
+	}
+	var open []model.BillItem
+	byId := make(map[int]model.BillItem, len(items))
+	for _, item := range items {
+		byId[item.OrderItemId] = item
+		if !refunded[item.OrderItemId] {
+			open = append(open, item)
+		}
+	}
+
+	selected := open
+	if len(r.OrderItemIds) > 0 {
+		selected = nil
+		for _, orderItemId := range r.OrderItemIds {
+			item, ok := byId[orderItemId]
+			if !ok {
+				log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Order item ID not found on this bill.")
+				return nil, response.CustomResponse{
+					Code:    enums.NotFound.GetCode(),
+					Message: enums.NotFound.GetMessage() + ", Order item ID " + fmt.Sprint(orderItemId) + " not found on this bill.",
+				}, http.StatusNotFound, fmt.Errorf("order item not on bill")
+			}
+			if refunded[orderItemId] {
+				log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Order item was already refunded.")
+				return nil, response.CustomResponse{
+					Code:    enums.InvalidTransition.GetCode(),
+					Message: enums.InvalidTransition.GetMessage() + ", Order item ID " + fmt.Sprint(orderItemId) + " was already refunded.",
+				}, http.StatusConflict, fmt.Errorf("order item already refunded")
+			}
+			selected = append(selected, item)
+		}
+	}
+
+	var amount int64
+	for _, item := range selected {
+		share := min(toCents(item.Charged), left-amount)
+		amount += share
+		refund.Items = append(refund.Items, model.RefundItem{OrderItemId: item.OrderItemId, Amount: fromCents(share)})
+	}
+	if len(selected) == len(open) {
+		if len(refund.Items) > 0 {
+			last := &refund.Items[len(refund.Items)-1]
+			last.Amount = fromCents(toCents(last.Amount) + left - amount)
+		}
+		amount = left
+	}
+	if amount <= 0 {
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Selected items have nothing left to refund.")
+		return nil, response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Selected items have nothing left to refund.",
+		}, http.StatusConflict, fmt.Errorf("nothing to refund")
+	}
+	refund.Amount = fromCents(amount)
+	return refund, response.CustomResponse{}, http.StatusOK, nil
+}
+
+// allocateRefund spreads the amount over the charges that can still give money back, latest first.
+// The returned tenders carry negative amounts and point at the charge they return.
+func allocateRefund(amount int64, charges []model.Tender) ([]model.Tender, error) {
+	var tenders []model.Tender
+	for _, charge := range charges {
+		if amount == 0 {
+			break
+		}
+		share := min(amount, toCents(charge.Amount))
+		amount -= share
+		tenders = append(tenders, model.Tender{
+			Method:    charge.Method,
+			Amount:    -fromCents(share),
+			Tendered:  -fromCents(share),
+			Reference: charge.Reference,
+			Provider:  charge.Provider,
+			RefundOf:  charge.TenderId,
+			Status:    enums.TenderPending,
+		})
+	}
+	if amount > 0 {
+		return nil, fmt.Errorf("charges are %.2f short of the refund", fromCents(amount))
+	}
+	return tenders, nil
+}
+
+// returnTenders gives each tender's money back through its provider, voiding the charge for a void
+// and refunding it otherwise. Every tender is tried and marked settled, keeping the provider's
+// refund reference, or failed. Refunds are keyed by the refund payment and the charge they return,
+// so asking again for a tender whose outcome was lost does not give the money back twice.
+func (s *RestaurantService) returnTenders(kind string, paymentId int, tenders []model.Tender) []model.Tender {
+	ctx, cancel := context.WithTimeout(context.Background(), paymentChargeTimeout)
+	defer cancel()
+	for i, tender := range tenders {
+		tenders[i].Status = enums.TenderFailed
+		provider, ok := s.PaymentProviders.Provider(tender.Method)
+		if !ok {
+			log.Println("RestaurantService -> No payment provider configured for", tender.Method)
+			continue
+		}
+		var err error
+		if kind == enums.RefundKindVoid {
+			err = provider.Void(ctx, tender.Reference)
+		} else {
+			var reference string
+			key := fmt.Sprintf("refund-%d-%d", paymentId, tender.RefundOf)
+			reference, err = provider.Refund(ctx, tender.Reference, -toCents(tender.Amount), key)
+			if reference != "" {
+				tenders[i].Reference = reference
+			}
+		}
+		if err != nil {
+			log.Println("RestaurantService -> " + enums.PaymentDeclined.GetMessage() + ", " + tender.Method + " " + kind + " failed: " + err.Error())
+			continue
+		}
+		tenders[i].Status = enums.TenderSettled
+	}
+	return tenders
+}
+
+func refundLabel(kind string, reason string) string {
+	label := "Refund"
+	if kind == enums.RefundKindVoid {
+		label = "Void"
+	}
+	return label + ": " + strings.ReplaceAll(reason, "_", " ")
+}
+
+// maxReportDays keeps a report to about a year of bills.
+const maxReportDays = 366
+
+// validateReportRequest checks the format and dates of a report and returns the local times it
+// covers, from the start of From up to the start of the day after To.
+func validateReportRequest(r *request.ReportRequest) (time.Time, time.Time, response.CustomResponse, int, error) {
+	if r.Format == "" {
+		r.Format = report.FormatJSON
+	}
+	if !report.IsFormat(r.Format) {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Format must be json or csv.")
+		return time.Time{}, time.Time{}, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Format must be json or csv.",
+		}, http.StatusBadRequest, fmt.Errorf("invalid report format")
+	}
+	today := time.Now().Format(time.DateOnly)
+	if r.To == "" {
+		r.To = today
+	}
+	to, err := time.ParseInLocation(time.DateOnly, r.To, time.Local)
+	if err != nil {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", To must be a date like 2006-01-02.")
+		return time.Time{}, time.Time{}, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", To must be a date like 2006-01-02.",
+		}, http.StatusBadRequest, err
+	}
+	if r.From == "" {
+		r.From = to.AddDate(0, 0, -29).Format(time.DateOnly)
+	}
+	from, err := time.ParseInLocation(time.DateOnly, r.From, time.Local)
+	if err != nil {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", From must be a date like 2006-01-02.")
+		return time.Time{}, time.Time{}, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", From must be a date like 2006-01-02.",
+		}, http.StatusBadRequest, err
+	}
+	end := to.AddDate(0, 0, 1)
+	if !from.Before(end) || from.AddDate(0, 0, maxReportDays).Before(end) {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", From must not be after To and a report covers at most " + fmt.Sprint(maxReportDays) + " days.")
+		return time.Time{}, time.Time{}, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", From must not be after To and a report covers at most " + fmt.Sprint(maxReportDays) + " days.",
+		}, http.StatusBadRequest, fmt.Errorf("invalid report range")
+	}
+	return from, end, response.CustomResponse{}, http.StatusOK, nil
+}
+
+// finishSalesRow works out the net and average ticket of a row from its sums.
+func finishSalesRow(row *model.SalesRow) {
+	net := toCents(row.Gross) - toCents(row.Refunded)
+	row.Net = fromCents(net)
+	row.AverageTicket = 0
+	if row.Orders > 0 {
+		row.AverageTicket = fromCents(int64(math.Round(float64(net) / float64(row.Orders))))
+	}
+}
+
+func (s *RestaurantService) CheckReviewId(reviewId int) (response.CustomResponse, int, error) {
+	existsReviewId, err := s.RestaurantRepo.FindReviewById(reviewId)
+	if err != nil {
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError, err
+	}
+	if !existsReviewId {
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Review ID not found.")
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Review ID " + fmt.Sprint(reviewId) + " not found.",
+		}, http.StatusNotFound, fmt.Errorf("review id not found")
+	}
+	return response.CustomResponse{}, http.StatusOK, nil
+}
+
+func validateReviewFilterRequest(f *request.ReviewFilterRequest) (response.CustomResponse, int, error) {
+	invalid := func(reason string) (response.CustomResponse, int, error) {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", " + reason)
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", " + reason,
+		}, http.StatusBadRequest, fmt.Errorf("%s", reason)
+	}
+	f.Visibility = strings.ToLower(strings.TrimSpace(f.Visibility))
+	f.Order = strings.ToLower(strings.TrimSpace(f.Order))
+	for _, rating := range []int{f.Rating, f.MinRating, f.MaxRating} {
+		if rating < 0 || rating > 5 {
+			return invalid("rating, minRating and maxRating must be between 1 and 5.")
+		}
+	}
+	if f.MaxRating > 0 && f.MinRating > f.MaxRating {
+		return invalid("minRating must not be greater than maxRating.")
+	}
+	for _, date := range []string{f.From, f.To} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.ParseInLocation(time.DateOnly, date, time.Local); err != nil {
+			return invalid("from and to must be dates like 2006-01-02.")
+		}
+	}
+	if f.From != "" && f.To != "" && f.From > f.To {
+		return invalid("from must not be after to.")
+	}
+	if f.OrderId < 0 || f.TableId < 0 || f.MenuItemId < 0 {
+		return invalid("orderId, tableId and menuItemId must not be negative.")
+	}
+	f.Kind = strings.ToLower(strings.TrimSpace(f.Kind))
+	switch f.Kind {
+	case "", "order", "dish":
+	default:
+		return invalid("kind must be order or dish.")
+	}
+	switch f.Visibility {
+	case "", "visible", "hidden", "all":
+	default:
+		return invalid("visibility must be visible, hidden or all.")
+	}
+	switch f.Order {
+	case "", "asc", "desc":
+	default:
+		return invalid("order must be asc or desc.")
+	}
+	if f.Page < 0 || f.PageSize < 0 {
+		return invalid("page and pageSize must not be negative.")
+	}
+	if f.PageSize > 100 {
+		return invalid("pageSize must not exceed 100.")
+	}
+	if f.Page == 0 {
+		f.Page = 1
+	}
+	return response.CustomResponse{}, http.StatusOK, nil
+}
+
+func validateReviewModerationRequest(r *request.ReviewModerationRequest) (response.CustomResponse, int, error) {
+	if r.ReviewId <= 0 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Review ID must be greater than 0.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Review ID must be greater than 0.",
+		}, http.StatusBadRequest, fmt.Errorf("invalid review id")
+	}
+	r.Note = strings.TrimSpace(r.Note)
+	if utf8.RuneCountInString(r.Note) > 255 {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Note must not exceed 255 characters.")
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", Note must not exceed 255 characters.",
+		}, http.StatusBadRequest, fmt.Errorf("invalid moderation note")
+	}
+	return response.CustomResponse{}, http.StatusOK, nil
+}
+
+func validateItemReviews(items []request.ItemReview) (response.CustomResponse, int, error) {
+	seen := make(map[int]bool, len(items))
+	for i, item := range items {
+		if item.MenuItemId <= 0 || seen[item.MenuItemId] {
+			log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Item review " + fmt.Sprint(i+1) + " menu item ID must be positive and rated once.")
+			return response.CustomResponse{
+				Code:    enums.Invalid.GetCode(),
+				Message: enums.Invalid.GetMessage() + ", Item review " + fmt.Sprint(i+1) + " menu item ID must be positive and rated once.",
+			}, http.StatusBadRequest, fmt.Errorf("invalid item review menu item")
+		}
+		seen[item.MenuItemId] = true
+		if item.Rating < 1 || item.Rating > 5 {
+			log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Item review " + fmt.Sprint(i+1) + " rating must be between 1 and 5.")
+			return response.CustomResponse{
+				Code:    enums.Invalid.GetCode(),
+				Message: enums.Invalid.GetMessage() + ", Item review " + fmt.Sprint(i+1) + " rating must be between 1 and 5.",
+			}, http.StatusBadRequest, fmt.Errorf("invalid item review rating")
+		}
+	}
+	return response.CustomResponse{}, http.StatusOK, nil
+}
+
+// checkReviewableItems makes sure each rated dish was served on the order and was not rated on it before.
+func (s *RestaurantService) checkReviewableItems(orderId int, items []request.ItemReview, tx *sql.Tx) (response.CustomResponse, int, error) {
+	orderedIds, err := s.RestaurantRepo.GetOrderMenuItemIds(orderId, tx)
+	if err != nil {
+		log.Println("RestaurantService -> Error getting order menu items:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError, err
 	}
-	orders, err := s.RestaurantRepo.GetOrderHistory(r)
+	reviewedIds, err := s.RestaurantRepo.GetReviewedMenuItemIds(orderId, tx)
 	if err != nil {
-		log.Println("RestaurantService -> Error getting order history:", err)
+		log.Println("RestaurantService -> Error getting reviewed menu items:", err)
 		return response.CustomResponse{
 			Code:    enums.Error.GetCode(),
 			Message: enums.Error.GetMessage(),
-		}, http.StatusInternalServerError
+		}, http.StatusInternalServerError, err
 	}
-	return response.CustomResponse{
-		Code:    enums.Success.GetCode(),
-		Message: enums.Success.GetMessage(),
-		Data:    orders,
-	}, http.StatusOK
+	ordered := make(map[int]bool, len(orderedIds))
+	for _, menuItemId := range orderedIds {
+		ordered[menuItemId] = true
+	}
+	reviewed := make(map[int]bool, len(reviewedIds))
+	for _, menuItemId := range reviewedIds {
+		reviewed[menuItemId] = true
+	}
+	for _, item := range items {
+		if !ordered[item.MenuItemId] {
+			log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Menu item ID is not on this order.")
+			return response.CustomResponse{
+				Code:    enums.Invalid.GetCode(),
+				Message: enums.Invalid.GetMessage() + ", Menu item ID " + fmt.Sprint(item.MenuItemId) + " is not on this order.",
+			}, http.StatusBadRequest, fmt.Errorf("menu item not on order")
+		}
+		if reviewed[item.MenuItemId] {
+			log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", Menu item has already been reviewed on this order.")
+			return response.CustomResponse{
+				Code:    enums.Invalid.GetCode(),
+				Message: enums.Invalid.GetMessage() + ", Menu item ID " + fmt.Sprint(item.MenuItemId) + " has already been reviewed on this order.",
+			}, http.StatusBadRequest, fmt.Errorf("menu item already reviewed")
+		}
+	}
+	return response.CustomResponse{}, http.StatusOK, nil
 }
 
-func joinWithComma(ids []int) string {
-	notFoundItemsStr := make([]string, len(ids))
-	for i, id := range ids {
-		notFoundItemsStr[i] = fmt.Sprint(id)
+const (
+	minReservationMinutes = 15
+	maxReservationMinutes = 8 * 60
+	maxPartySize          = 50
+)
+
+// validateReservationRequest checks and trims a booking and returns its local start time.
+func (s *RestaurantService) validateReservationRequest(r *request.ReservationRequest) (time.Time, response.CustomResponse, int, error) {
+	invalid := func(reason string) (time.Time, response.CustomResponse, int, error) {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", " + reason)
+		return time.Time{}, response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", " + reason,
+		}, http.StatusBadRequest, fmt.Errorf("invalid reservation request")
 	}
-	return strings.Join(notFoundItemsStr, ", ")
+	r.GuestName = strings.TrimSpace(r.GuestName)
+	r.Phone = strings.TrimSpace(r.Phone)
+	r.Note = strings.TrimSpace(r.Note)
+	if r.GuestName == "" || utf8.RuneCountInString(r.GuestName) > 100 {
+		return invalid("Guest name must be between 1 and 100 characters.")
+	}
+	if !validPhone(r.Phone) {
+		return invalid("Phone must be 6 to 20 digits, spaces, dashes or a leading plus.")
+	}
+	if r.PartySize < 1 || r.PartySize > maxPartySize {
+		return invalid("Party size must be between 1 and " + fmt.Sprint(maxPartySize) + ".")
+	}
+	reservedAt, err := time.ParseInLocation("2006-01-02 15:04", r.ReservedAt, time.Local)
+	if err != nil {
+		return invalid("Reserved at must be a local time like 2006-01-02 15:04.")
+	}
+	if !reservedAt.After(time.Now()) {
+		return invalid("Reservation time must be in the future.")
+	}
+	if r.DurationMinutes == 0 {
+		r.DurationMinutes = int(s.Reservations.Duration / time.Minute)
+	}
+	if r.DurationMinutes < minReservationMinutes || r.DurationMinutes > maxReservationMinutes {
+		return invalid("Duration must be between " + fmt.Sprint(minReservationMinutes) + " and " + fmt.Sprint(maxReservationMinutes) + " minutes.")
+	}
+	if r.TableId < 0 {
+		return invalid("Table ID must not be negative.")
+	}
+	if utf8.RuneCountInString(r.Note) > 255 {
+		return invalid("Note must not exceed 255 characters.")
+	}
+	return reservedAt, response.CustomResponse{}, http.StatusOK, nil
 }
 
-func (s *RestaurantService) CheckTableId(r *request.OrderRequest) (response.CustomResponse, int, error) {
-	existsTableId, err := s.RestaurantRepo.FindTableById(r)
+// checkReservationTable makes sure the booked table exists and is free for the whole booking.
+func (s *RestaurantService) checkReservationTable(r *request.ReservationRequest, reservedAt time.Time, tx *sql.Tx) (response.CustomResponse, int, error) {
+	if r.TableId == 0 {
+		return response.CustomResponse{}, http.StatusOK, nil
+	}
+	tableStatus, _, err := s.RestaurantRepo.LockTable(r.TableId, tx)
 	if err != nil {
+		log.Println("RestaurantService -> Error locking table:", err)
 		return response.CustomResponse{
 			Code:    enums.Error.GetCode(),
 			Message: enums.Error.GetMessage(),
 		}, http.StatusInternalServerError, err
 	}
-	if !existsTableId {
+	if tableStatus == "" {
 		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Table ID not found.")
 		return response.CustomResponse{
 			Code:    enums.NotFound.GetCode(),
 			Message: enums.NotFound.GetMessage() + ", Table ID " + fmt.Sprint(r.TableId) + " not found.",
 		}, http.StatusNotFound, fmt.Errorf("table id not found")
 	}
+	end := reservedAt.Add(time.Duration(r.DurationMinutes) * time.Minute)
+	conflict, err := s.RestaurantRepo.HasReservationConflict(r.TableId, config.FormatTime(reservedAt), config.FormatTime(end), r.ReservationId, tx)
+	if err != nil {
+		log.Println("RestaurantService -> Error checking reservation conflicts:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError, err
+	}
+	if conflict {
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Table is already booked at that time.")
+		return response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Table ID " + fmt.Sprint(r.TableId) + " is already booked at that time.",
+		}, http.StatusConflict, fmt.Errorf("reservation conflict")
+	}
 	return response.CustomResponse{}, http.StatusOK, nil
 }
 
-func (s *RestaurantService) CheckOrderId(r *request.OrderRequest) (response.CustomResponse, int, error) {
-	existsOrderId, err := s.RestaurantRepo.FindOrderById(r)
+func (s *RestaurantService) lockReservation(reservationId int, tx *sql.Tx) (*model.Reservation, response.CustomResponse, int, error) {
+	reservation, err := s.RestaurantRepo.FindReservationById(reservationId, tx)
+	if err != nil {
+		log.Println("RestaurantService -> Error finding reservation:", err)
+		return nil, response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError, err
+	}
+	if reservation == nil {
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Reservation ID not found.")
+		return nil, response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", Reservation ID " + fmt.Sprint(reservationId) + " not found.",
+		}, http.StatusNotFound, fmt.Errorf("reservation id not found")
+	}
+	return reservation, response.CustomResponse{}, http.StatusOK, nil
+}
+
+// occupyReservedTable seats the party at its booked table, which must not be taken by someone else.
+func (s *RestaurantService) occupyReservedTable(reservation *model.Reservation, tx *sql.Tx) (response.CustomResponse, int, error) {
+	if reservation.TableId == 0 {
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Assign a table before seating the reservation.")
+		return response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Assign a table before seating the reservation.",
+		}, http.StatusConflict, fmt.Errorf("reservation has no table")
+	}
+	tableStatus, _, err := s.RestaurantRepo.LockTable(reservation.TableId, tx)
 	if err != nil {
+		log.Println("RestaurantService -> Error locking table:", err)
 		return response.CustomResponse{
 			Code:    enums.Error.GetCode(),
 			Message: enums.Error.GetMessage(),
 		}, http.StatusInternalServerError, err
 	}
-	if !existsOrderId {
-		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Order ID not found.")
+	if tableStatus == "" || tableStatus == enums.TableOccupied {
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Table is not free to seat the reservation.")
+		return response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Table ID " + fmt.Sprint(reservation.TableId) + " is not free to seat the reservation.",
+		}, http.StatusConflict, fmt.Errorf("table not free")
+	}
+	err = s.RestaurantRepo.UpdateTableWithTx(reservation.TableId, enums.TableOccupied, tx)
+	if err != nil {
+		log.Println("RestaurantService -> Error updating table:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError, err
+	}
+	return response.CustomResponse{}, http.StatusOK, nil
+}
+
+// commitReservation brings the table statuses in line with the changed booking, commits and
+// returns the booking.
+func (s *RestaurantService) commitReservation(reservationId int, tx *sql.Tx) (response.CustomResponse, int) {
+	err := s.syncReservations(tx)
+	if err != nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error syncing reserved tables:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	reservation, err := s.RestaurantRepo.FindReservationById(reservationId, tx)
+	if err != nil || reservation == nil {
+		tx.Rollback()
+		log.Println("RestaurantService -> Error loading reservation:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	err = tx.Commit()
+	if err != nil {
+		log.Println("RestaurantService -> Error committing transaction:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	log.Println("Transaction committed successfully")
+	return response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+		Data:    reservation,
+	}, http.StatusOK
+}
+
+// validPhone accepts 6 to 20 digits, spaces and dashes with an optional leading plus.
+func validPhone(phone string) bool {
+	return len(phone) >= 6 && len(phone) <= 20 && strings.Trim(strings.TrimPrefix(phone, "+"), "0123456789- ") == ""
+}
+
+func validateWaitlistRequest(r *request.WaitlistRequest) (response.CustomResponse, int, error) {
+	invalid := func(reason string) (response.CustomResponse, int, error) {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", " + reason)
 		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", " + reason,
+		}, http.StatusBadRequest, fmt.Errorf("invalid waitlist request")
+	}
+	r.PartyName = strings.TrimSpace(r.PartyName)
+	r.Phone = strings.TrimSpace(r.Phone)
+	r.Note = strings.TrimSpace(r.Note)
+	if r.PartyName == "" || utf8.RuneCountInString(r.PartyName) > 100 {
+		return invalid("Party name must be between 1 and 100 characters.")
+	}
+	if !validPhone(r.Phone) {
+		return invalid("Phone must be 6 to 20 digits, spaces, dashes or a leading plus.")
+	}
+	if r.PartySize < 1 || r.PartySize > maxPartySize {
+		return invalid("Party size must be between 1 and " + fmt.Sprint(maxPartySize) + ".")
+	}
+	if utf8.RuneCountInString(r.Note) > 255 {
+		return invalid("Note must not exceed 255 characters.")
+	}
+	return response.CustomResponse{}, http.StatusOK, nil
+}
+
+// occupyWaitlistTable seats a waitlist party at a free table with enough seats, the best fitting free
+// table when tableId is 0, and returns the table. Other parties the table was suggested to go back
+// to waiting.
+func (s *RestaurantService) occupyWaitlistTable(entry *model.WaitlistEntry, tableId int, tx *sql.Tx) (int, response.CustomResponse, int, error) {
+	if tableId == 0 {
+		suggestion, err := s.suggestTables(entry.PartySize, "")
+		if err != nil {
+			log.Println("RestaurantService -> Error suggesting tables:", err)
+			return 0, response.CustomResponse{
+				Code:    enums.Error.GetCode(),
+				Message: enums.Error.GetMessage(),
+			}, http.StatusInternalServerError, err
+		}
+		if len(suggestion.Tables) == 0 {
+			log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", No free table seats the party.")
+			return 0, response.CustomResponse{
+				Code:    enums.InvalidTransition.GetCode(),
+				Message: enums.InvalidTransition.GetMessage() + ", No free table seats a party of " + fmt.Sprint(entry.PartySize) + ".",
+			}, http.StatusConflict, fmt.Errorf("no free table")
+		}
+		tableId = suggestion.Tables[0].TableId
+	}
+	tableStatus, capacity, err := s.RestaurantRepo.LockTable(tableId, tx)
+	if err != nil {
+		log.Println("RestaurantService -> Error locking table:", err)
+		return 0, response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError, err
+	}
+	if tableStatus == "" {
+		log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Table ID not found.")
+		return 0, response.CustomResponse{
 			Code:    enums.NotFound.GetCode(),
-			Message: enums.NotFound.GetMessage() + ", Order ID " + fmt.Sprint(r.OrderId) + " not found.",
-		}, http.StatusNotFound, fmt.Errorf("order id not found")
+			Message: enums.NotFound.GetMessage() + ", Table ID " + fmt.Sprint(tableId) + " not found.",
+		}, http.StatusNotFound, fmt.Errorf("table id not found")
+	}
+	if tableStatus != enums.TableAvailable {
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Table is " + tableStatus + ".")
+		return 0, response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Table ID " + fmt.Sprint(tableId) + " is " + tableStatus + ".",
+		}, http.StatusConflict, fmt.Errorf("table not available")
+	}
+	if capacity < entry.PartySize {
+		log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", Table is too small for the party.")
+		return 0, response.CustomResponse{
+			Code:    enums.InvalidTransition.GetCode(),
+			Message: enums.InvalidTransition.GetMessage() + ", Table ID " + fmt.Sprint(tableId) + " seats " + fmt.Sprint(capacity) + ", the party is " + fmt.Sprint(entry.PartySize) + ".",
+		}, http.StatusConflict, fmt.Errorf("table too small")
+	}
+	err = s.RestaurantRepo.UpdateTableWithTx(tableId, enums.TableOccupied, tx)
+	if err == nil {
+		err = s.RestaurantRepo.ReleaseNotifiedParties(tableId, entry.WaitlistId, tx)
+	}
+	if err != nil {
+		log.Println("RestaurantService -> Error seating waitlist party:", err)
+		return 0, response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError, err
+	}
+	return tableId, response.CustomResponse{}, http.StatusOK, nil
+}
+
+// freedTableResponse hands a table that just became available to reservations and the waitlist,
+// the response carries the waitlist party the table is suggested to, if any.
+func (s *RestaurantService) freedTableResponse(tableId int) (response.CustomResponse, int) {
+	nextParty, err := s.tableFreed(tableId)
+	if err != nil {
+		log.Println("RestaurantService -> Error handing over freed table:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError
+	}
+	resp := response.CustomResponse{
+		Code:    enums.Success.GetCode(),
+		Message: enums.Success.GetMessage(),
+	}
+	if nextParty != nil {
+		resp.Data = nextParty
+	}
+	return resp, http.StatusOK
+}
+
+const defaultTableCapacity = 4
+
+func validateTableLayout(r *request.TableRequest) (response.CustomResponse, int, error) {
+	invalid := func(reason string) (response.CustomResponse, int, error) {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", " + reason)
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", " + reason,
+		}, http.StatusBadRequest, fmt.Errorf("invalid table layout")
+	}
+	if r.Capacity < 1 || r.Capacity > maxPartySize {
+		return invalid("Capacity must be between 1 and " + fmt.Sprint(maxPartySize) + ".")
+	}
+	if !enums.IsTableZone(r.Zone) {
+		return invalid("Zone must be indoor, terrace or bar.")
+	}
+	if r.X < 0 || r.Y < 0 {
+		return invalid("Floor plan coordinates must not be negative.")
 	}
 	return response.CustomResponse{}, http.StatusOK, nil
 }
-func (s *RestaurantService) convertFileToBase64(filePath string) (string, error) {
-	fmt.Printf("Attempting to read file at: %s\n", filePath) // Debug print
-	data, err := os.ReadFile(filePath)                       // Read the file
+
+// suggestTables lists the free tables that seat partySize and are not booked within an average
+// sitting, and estimates the wait when there is none.
+func (s *RestaurantService) suggestTables(partySize int, zone string) (*model.TableSuggestion, error) {
+	dining, err := s.diningMinutes()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	base64Content := base64.StdEncoding.EncodeToString(data)
-	mimeType := http.DetectContentType(data)
-	return "data:" + mimeType + ";base64," + base64Content, nil
+	until := time.Now().Add(time.Duration(dining) * time.Minute)
+	tables, err := s.RestaurantRepo.GetTableSuggestions(partySize, zone, config.FormatTime(until))
+	if err != nil {
+		return nil, err
+	}
+	suggestion := &model.TableSuggestion{PartySize: partySize, Tables: tables}
+	if len(tables) > 0 {
+		return suggestion, nil
+	}
+	entries, err := s.RestaurantRepo.GetWaitlist()
+	if err != nil {
+		return nil, err
+	}
+	waits, err := s.estimateQueue(entries, partySize)
+	if err != nil {
+		return nil, err
+	}
+	suggestion.EstimatedWaitMinutes = max(waits[len(waits)-1], 0)
+	return suggestion, nil
+}
+
+// maxGroupTables is the most tables merged into one group at a time.
+const maxGroupTables = 10
+
+func validateTableGroupRequest(r *request.TableGroupRequest, merge bool) (response.CustomResponse, int, error) {
+	invalid := func(reason string) (response.CustomResponse, int, error) {
+		log.Println("RestaurantService -> " + enums.Invalid.GetMessage() + ", " + reason)
+		return response.CustomResponse{
+			Code:    enums.Invalid.GetCode(),
+			Message: enums.Invalid.GetMessage() + ", " + reason,
+		}, http.StatusBadRequest, fmt.Errorf("invalid table group request")
+	}
+	if r.TableId <= 0 {
+		return invalid("Table ID must be greater than 0.")
+	}
+	if merge && len(r.TableIds) == 0 {
+		return invalid("tableIds must not be empty.")
+	}
+	if len(r.TableIds) > maxGroupTables {
+		return invalid("At most " + fmt.Sprint(maxGroupTables) + " tables can be merged at a time.")
+	}
+	seen := map[int]bool{r.TableId: true}
+	for _, tableId := range r.TableIds {
+		if tableId <= 0 || seen[tableId] {
+			return invalid("tableIds must be distinct table IDs greater than 0, other than the main table.")
+		}
+		seen[tableId] = true
+	}
+	return response.CustomResponse{}, http.StatusOK, nil
+}
+
+// lockTableStates locks the tables of a transfer, merge or split, all of which must exist.
+func (s *RestaurantService) lockTableStates(tableIds []int, tx *sql.Tx) (map[int]model.TableState, response.CustomResponse, int, error) {
+	tables, err := s.RestaurantRepo.LockTableStates(tableIds, tx)
+	if err != nil {
+		log.Println("RestaurantService -> Error locking tables:", err)
+		return nil, response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError, err
+	}
+	for _, tableId := range tableIds {
+		if _, ok := tables[tableId]; !ok {
+			log.Println("RestaurantService -> " + enums.NotFound.GetMessage() + ", Table ID not found.")
+			return nil, response.CustomResponse{
+				Code:    enums.NotFound.GetCode(),
+				Message: enums.NotFound.GetMessage() + ", Table ID " + fmt.Sprint(tableId) + " not found.",
+			}, http.StatusNotFound, fmt.Errorf("table id not found")
+		}
+	}
+	return tables, response.CustomResponse{}, http.StatusOK, nil
+}
+
+// checkTableState makes sure a table is outside any merged group and, when tableStatus is set, in
+// that status. Without a status the table only must not be reserved.
+func checkTableState(table model.TableState, tableStatus string) (response.CustomResponse, int, error) {
+	reason := ""
+	switch {
+	case table.MergedInto != 0 || table.Members != 0:
+		reason = "Table ID " + fmt.Sprint(table.TableId) + " is part of merged tables, split them first."
+	case tableStatus != "" && table.TableStatus != tableStatus:
+		reason = "Table ID " + fmt.Sprint(table.TableId) + " is " + table.TableStatus + ", it must be " + tableStatus + "."
+	case tableStatus == "" && table.TableStatus == enums.TableReserved:
+		reason = "Table ID " + fmt.Sprint(table.TableId) + " is reserved."
+	default:
+		return response.CustomResponse{}, http.StatusOK, nil
+	}
+	log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", " + reason)
+	return response.CustomResponse{
+		Code:    enums.InvalidTransition.GetCode(),
+		Message: enums.InvalidTransition.GetMessage() + ", " + reason,
+	}, http.StatusConflict, fmt.Errorf("table state conflict")
+}
+
+// checkTableNotMerged refuses tables merged into another table, and with group set also the main
+// table of a group, whose status is managed by merge and split.
+func (s *RestaurantService) checkTableNotMerged(tableId int, group bool) (response.CustomResponse, int, error) {
+	mergedInto, members, err := s.RestaurantRepo.FindTableGroup(tableId)
+	if err != nil {
+		log.Println("RestaurantService -> Error finding table group:", err)
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError, err
+	}
+	reason := ""
+	if mergedInto != 0 {
+		reason = "Table ID " + fmt.Sprint(tableId) + " is merged into table ID " + fmt.Sprint(mergedInto) + "."
+	} else if group && members != 0 {
+		reason = "Table ID " + fmt.Sprint(tableId) + " has merged tables, split them first."
+	} else {
+		return response.CustomResponse{}, http.StatusOK, nil
+	}
+	log.Println("RestaurantService -> " + enums.InvalidTransition.GetMessage() + ", " + reason)
+	return response.CustomResponse{
+		Code:    enums.InvalidTransition.GetCode(),
+		Message: enums.InvalidTransition.GetMessage() + ", " + reason,
+	}, http.StatusConflict, fmt.Errorf("table is merged")
+}
+
+// moveTableSession starts a new QR session at the table a party moved to and hands it the table's
+// orders.
+func (s *RestaurantService) moveTableSession(tableId int, tx *sql.Tx) (*model.TableSession, error) {
+	tableSession, err := s.openTableSession(tableId, tx)
+	if err != nil {
+		return nil, err
+	}
+	err = s.RestaurantRepo.SetTableOrderSession(tableId, tableSession.SessionId, tx)
+	if err != nil {
+		return nil, err
+	}
+	return tableSession, nil
+}
+
+// freedTableMove reports a table a party moved away from, after offering it to bookings and the
+// waitlist. The move itself is committed, so a failure here is only logged.
+func (s *RestaurantService) freedTableMove(tableId int) model.TableMove {
+	nextParty, err := s.tableFreed(tableId)
+	if err != nil {
+		log.Println("RestaurantService -> Error handing over freed table:", err)
+	}
+	return model.TableMove{TableId: tableId, TableStatus: enums.TableAvailable, NextParty: nextParty}
+}
+
+// checkMenuItems makes sure every menu item is on the menu and available, and keeps it so until tx ends.
+func (s *RestaurantService) checkMenuItems(menuItems []request.MenuItem, tx *sql.Tx) (response.CustomResponse, int, error) {
+	notFoundItems, err := s.RestaurantRepo.FindMenuItemById(menuItems, tx)
+	if err != nil {
+		return response.CustomResponse{
+			Code:    enums.Error.GetCode(),
+			Message: enums.Error.GetMessage(),
+		}, http.StatusInternalServerError, err
+	}
+	if len(notFoundItems) > 0 {
+		joinIDS := joinWithComma(notFoundItems)
+		log.Println("RestaurantService -> "+enums.NotFound.GetMessage()+", MenuItem IDs not found: ", joinIDS)
+		return response.CustomResponse{
+			Code:    enums.NotFound.GetCode(),
+			Message: enums.NotFound.GetMessage() + ", MenuItem IDs not found: " + joinIDS,
+		}, http.StatusNotFound, fmt.Errorf("menu items not found")
+	}
+	return response.CustomResponse{}, http.StatusOK, nil
 }
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"Restaurant/internal/model"
+	"Restaurant/internal/payment"
+	"Restaurant/utils/enums"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReturnTendersPartialDecline(t *testing.T) {
+	fake := payment.NewFakeProvider()
+	s := &RestaurantService{PaymentProviders: payment.Registry{
+		enums.MethodCash:      &payment.CashProvider{},
+		enums.MethodCard:      fake,
+		enums.MethodPromptPay: fake,
+	}}
+	card, err := fake.Charge(context.Background(), payment.ChargeRequest{Method: enums.MethodCard, Amount: 10000})
+	if err != nil {
+		t.Fatalf("Charge() error = %v", err)
+	}
+	promptPay, err := fake.Charge(context.Background(), payment.ChargeRequest{Method: enums.MethodPromptPay, Amount: 5000})
+	if err != nil {
+		t.Fatalf("Charge() error = %v", err)
+	}
+	// A voided charge can no longer be refunded, so the PromptPay part is declined
+	if err := fake.Void(context.Background(), promptPay.Reference); err != nil {
+		t.Fatalf("Void() error = %v", err)
+	}
+	tenders := func() []model.Tender {
+		return []model.Tender{
+			{Method: enums.MethodCard, Amount: -40, Reference: card.Reference, RefundOf: 1, Status: enums.TenderPending},
+			{Method: enums.MethodPromptPay, Amount: -50, Reference: promptPay.Reference, RefundOf: 2, Status: enums.TenderPending},
+			{Method: enums.MethodCash, Amount: -10, RefundOf: 3, Status: enums.TenderPending},
+		}
+	}
+
+	returned := s.returnTenders(enums.RefundKindRefund, 7, tenders())
+	var statuses []string
+	for _, tender := range returned {
+		statuses = append(statuses, tender.Status)
+	}
+	want := []string{enums.TenderSettled, enums.TenderFailed, enums.TenderSettled}
+	if !reflect.DeepEqual(statuses, want) {
+		t.Fatalf("returnTenders() statuses = %v, want %v", statuses, want)
+	}
+	if !strings.HasPrefix(returned[0].Reference, "FAKE-refund-") {
+		t.Errorf("returnTenders() card reference = %q, want the refund reference", returned[0].Reference)
+	}
+	if returned[1].Reference != promptPay.Reference {
+		t.Errorf("returnTenders() declined reference = %q, want the charge %q", returned[1].Reference, promptPay.Reference)
+	}
+
+	// Asking again for a refund whose outcome was lost gives the card money back only once
+	retried := s.returnTenders(enums.RefundKindRefund, 7, tenders())
+	if retried[0].Status != enums.TenderSettled || retried[0].Reference != returned[0].Reference {
+		t.Errorf("returnTenders() retried card = %+v, want reference %q", retried[0], returned[0].Reference)
+	}
+	if retried[1].Status != enums.TenderFailed {
+		t.Errorf("returnTenders() retried PromptPay status = %q, want %q", retried[1].Status, enums.TenderFailed)
+	}
+	if _, err := fake.Refund(context.Background(), card.Reference, 6000, "rest"); err != nil {
+		t.Errorf("Refund() of what is left of the card charge error = %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"Restaurant/config"
+	"Restaurant/database"
+	"Restaurant/internal/model"
+	"Restaurant/utils/enums"
+	"log"
+	"math"
+	"time"
+)
+
+// minTurnMinutes is the least time an occupied table is expected to need before it frees up, even
+// when its party has already stayed longer than the average dining time.
+const minTurnMinutes = 5
+
+// diningMinutes is the average time a party spent at a table over the last 30 days, or the
+// reservation duration before there is any history.
+func (s *RestaurantService) diningMinutes() (int, error) {
+	since := time.Now().AddDate(0, 0, -30)
+	minutes, err := s.RestaurantRepo.GetAverageDiningMinutes(config.FormatTime(since))
+	if err != nil {
+		return 0, err
+	}
+	if minutes <= 0 {
+		return int(s.Reservations.Duration / time.Minute), nil
+	}
+	return int(math.Round(minutes)), nil
+}
+
+// estimateQueue estimates the wait in minutes of every waiting party on the list, in list order,
+// followed by the wait of a new party of newPartySize when it is not 0. A party no table seats
+// gets -1.
+func (s *RestaurantService) estimateQueue(entries []model.WaitlistEntry, newPartySize int) ([]int, error) {
+	tables, err := s.RestaurantRepo.GetTableOccupancy(config.FormatTime(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	dining, err := s.diningMinutes()
+	if err != nil {
+		return nil, err
+	}
+	// A table suggested to a notified party is about to be taken
+	held := map[int]bool{}
+	partySizes := []int{}
+	for _, entry := range entries {
+		if entry.Status == enums.WaitlistNotified {
+			held[entry.TableId] = true
+		} else {
+			partySizes = append(partySizes, entry.PartySize)
+		}
+	}
+	if newPartySize > 0 {
+		partySizes = append(partySizes, newPartySize)
+	}
+	return estimateWaits(tables, held, partySizes, dining), nil
+}
+
+// estimateWaits plays the queue forward. Every party takes the smallest table that seats it and
+// frees up first: a free table right away, an occupied one once it has been seated for the
+// average dining time and a reserved or held one after a full sitting.
+func estimateWaits(tables []model.TableOccupancy, held map[int]bool, partySizes []int, dining int) []int {
+	freeIn := make([]int, len(tables))
+	for i, table := range tables {
+		switch {
+		case held[table.TableId]:
+			freeIn[i] = dining
+		case table.TableStatus == enums.TableAvailable:
+			freeIn[i] = 0
+		case table.TableStatus == enums.TableOccupied && table.InSession:
+			freeIn[i] = max(dining-table.SeatedMinutes, minTurnMinutes)
+		default:
+			freeIn[i] = dining
+		}
+	}
+	waits := make([]int, len(partySizes))
+	for p, partySize := range partySizes {
+		// tables come smallest first, so the first of the earliest tables is the best fit
+		best := -1
+		for i, table := range tables {
+			if table.Capacity >= partySize && (best < 0 || freeIn[i] < freeIn[best]) {
+				best = i
+			}
+		}
+		if best < 0 {
+			waits[p] = -1
+			continue
+		}
+		waits[p] = freeIn[best]
+		freeIn[best] += dining
+	}
+	return waits
+}
+
+// fillWaitEstimates sets the queue position and estimated wait of the waiting parties on the list.
+func (s *RestaurantService) fillWaitEstimates(entries []model.WaitlistEntry) error {
+	waits, err := s.estimateQueue(entries, 0)
+	if err != nil {
+		return err
+	}
+	position := 0
+	for i := range entries {
+		if entries[i].Status != enums.WaitlistWaiting {
+			continue
+		}
+		entries[i].EstimatedWaitMinutes = max(waits[position], 0)
+		position++
+		entries[i].Position = position
+	}
+	return nil
+}
+
+// tableFreed is called once a table is back to available. It reserves the table when a booking is
+// due, and otherwise suggests the table to the longest waiting party it seats.
+func (s *RestaurantService) tableFreed(tableId int) (*model.WaitlistEntry, error) {
+	err := s.sweepReservations()
+	if err != nil {
+		return nil, err
+	}
+	return s.suggestNextParty(tableId)
+}
+
+// suggestNextParty notifies the next waiting party that fits the free table, it returns nil when the
+// table is not free or nobody waiting fits.
+func (s *RestaurantService) suggestNextParty(tableId int) (*model.WaitlistEntry, error) {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	tableStatus, capacity, err := s.RestaurantRepo.LockTable(tableId, tx)
+	if err != nil || tableStatus != enums.TableAvailable {
+		return nil, err
+	}
+	notified, err := s.RestaurantRepo.FindNotifiedParty(tableId, tx)
+	if err != nil || notified != nil {
+		return notified, err
+	}
+	next, err := s.RestaurantRepo.FindNextWaitingParty(capacity, tx)
+	if err != nil || next == nil {
+		return nil, err
+	}
+	err = s.RestaurantRepo.UpdateWaitlistStatus(next.WaitlistId, enums.WaitlistNotified, tableId, tx)
+	if err != nil {
+		return nil, err
+	}
+	next, err = s.RestaurantRepo.FindWaitlistEntryById(next.WaitlistId, tx)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	log.Printf("RestaurantService -> Suggested table %d to waitlist party %d", tableId, next.WaitlistId)
+	return next, nil
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"Restaurant/internal/model"
+	"Restaurant/utils/enums"
+	"reflect"
+	"testing"
+)
+
+func TestEstimateWaits(t *testing.T) {
+	floor := func(seatedMinutes int, inSession bool) []model.TableOccupancy {
+		return []model.TableOccupancy{
+			{TableId: 1, Capacity: 2, TableStatus: enums.TableAvailable},
+			{TableId: 2, Capacity: 4, TableStatus: enums.TableOccupied, InSession: inSession, SeatedMinutes: seatedMinutes},
+			{TableId: 3, Capacity: 6, TableStatus: enums.TableReserved},
+		}
+	}
+	tests := []struct {
+		name       string
+		tables     []model.TableOccupancy
+		held       map[int]bool
+		partySizes []int
+		want       []int
+	}{
+		{name: "free table seats right away", tables: floor(40, true), partySizes: []int{2}, want: []int{0}},
+		{name: "queue takes the table that frees first", tables: floor(40, true), partySizes: []int{2, 2, 2}, want: []int{0, 20, 60}},
+		{name: "long stay still needs the minimum turn", tables: floor(90, true), partySizes: []int{4}, want: []int{5}},
+		{name: "occupied without a session waits a full sitting", tables: floor(40, false), partySizes: []int{4}, want: []int{60}},
+		{name: "held table waits a full sitting", tables: floor(40, true), held: map[int]bool{1: true}, partySizes: []int{2}, want: []int{20}},
+		{name: "reserved table after a full sitting", tables: floor(40, true), partySizes: []int{5}, want: []int{60}},
+		{name: "party no table seats", tables: floor(40, true), partySizes: []int{8, 2}, want: []int{-1, 0}},
+		{name: "no tables", partySizes: []int{2}, want: []int{-1}},
+		{name: "nobody waiting", tables: floor(40, true), want: []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := estimateWaits(tt.tables, tt.held, tt.partySizes, 60); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("estimateWaits() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
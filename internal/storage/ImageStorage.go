@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ImageStorage stores menu images. LocalImageStorage keeps them on disk;
+// other backends (object storage, CDN) only need to implement this interface.
+type ImageStorage interface {
+	Save(fileName string, src io.Reader) error
+	Open(fileName string) (*ImageFile, error)
+	Delete(fileName string) error
+	URL(fileName string) string
+}
+
+type ImageFile struct {
+	Content io.ReadSeekCloser
+	ModTime time.Time
+	Size    int64
+}
+
+type LocalImageStorage struct {
+	Dir     string
+	BaseURL string
+}
+
+func (s *LocalImageStorage) Save(fileName string, src io.Reader) error {
+	path, err := s.path(fileName)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create image directory: %v", err)
+	}
+	dst, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create image file: %v", err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(path)
+		return fmt.Errorf("failed to write image file: %v", err)
+	}
+	return dst.Close()
+}
+
+func (s *LocalImageStorage) Open(fileName string) (*ImageFile, error) {
+	path, err := s.path(fileName)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, os.ErrNotExist
+	}
+	return &ImageFile{Content: file, ModTime: info.ModTime(), Size: info.Size()}, nil
+}
+
+func (s *LocalImageStorage) Delete(fileName string) error {
+	path, err := s.path(fileName)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// URL returns where the image is served, the file name is escaped as it may contain spaces.
+func (s *LocalImageStorage) URL(fileName string) string {
+	if fileName == "" {
+		return ""
+	}
+	return strings.TrimSuffix(s.BaseURL, "/") + "/" + url.PathEscape(fileName)
+}
+
+// path resolves fileName inside Dir and rejects anything that would escape it.
+func (s *LocalImageStorage) path(fileName string) (string, error) {
+	if fileName == "" || fileName != filepath.Base(fileName) || strings.HasPrefix(fileName, ".") {
+		return "", fmt.Errorf("invalid image file name: %q", fileName)
+	}
+	return filepath.Join(s.Dir, fileName), nil
+}
@@ -8,14 +8,51 @@ USE restaurant;
 -- ลบตาราง tables (โต๊ะ) ถ้ามีอยู่
 DROP TABLE IF EXISTS tables;
 
--- สร้างตาราง tables (โต๊ะ)
+-- สร้างตาราง tables (โต๊ะ) merged_into คือโต๊ะหลักเมื่อรวมโต๊ะเป็นบิลเดียวกัน
 CREATE TABLE tables (
                         table_id INT AUTO_INCREMENT PRIMARY KEY,
                         table_number INT UNIQUE NOT NULL,
-                        table_status ENUM('available', 'occupied') DEFAULT 'available',
+                        table_status ENUM('available', 'occupied', 'reserved') DEFAULT 'available',
+                        capacity INT NOT NULL DEFAULT 4,
+                        zone ENUM('indoor', 'terrace', 'bar') NOT NULL DEFAULT 'indoor',
+                        pos_x INT NOT NULL DEFAULT 0,
+                        pos_y INT NOT NULL DEFAULT 0,
+                        merged_into INT NULL,
                         is_deleted BOOLEAN DEFAULT FALSE,
                         created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-                        updated_at TIMESTAMP NULL DEFAULT NULL
+                        updated_at TIMESTAMP NULL DEFAULT NULL,
+                        FOREIGN KEY (merged_into) REFERENCES tables(table_id) ON DELETE SET NULL
+);
+
+-- ลบตาราง users (ผู้ใช้งาน) ถ้ามีอยู่
+DROP TABLE IF EXISTS users;
+
+-- สร้างตาราง users (ผู้ใช้งาน) บัญชี admin แรกถูกสร้างจาก ADMIN_USERNAME / ADMIN_PASSWORD ตอนเริ่มระบบ
+CREATE TABLE users (
+                       user_id INT AUTO_INCREMENT PRIMARY KEY,
+                       username VARCHAR(50) NOT NULL,
+                       password_hash VARCHAR(255) NOT NULL,
+                       role ENUM('admin', 'cashier', 'kitchen', 'table') NOT NULL,
+                       table_id INT NULL,
+                       is_active BOOLEAN DEFAULT TRUE,
+                       is_deleted BOOLEAN DEFAULT FALSE,
+                       created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                       updated_at TIMESTAMP NULL DEFAULT NULL,
+                       INDEX idx_users_username (username),
+                       FOREIGN KEY (table_id) REFERENCES tables(table_id) ON DELETE CASCADE
+);
+
+-- ลบตาราง categories (หมวดหมู่เมนู) ถ้ามีอยู่
+DROP TABLE IF EXISTS categories;
+
+-- สร้างตาราง categories (หมวดหมู่เมนู)
+CREATE TABLE categories (
+                            category_id INT AUTO_INCREMENT PRIMARY KEY,
+                            name VARCHAR(100) NOT NULL,
+                            sort_order INT NOT NULL DEFAULT 0,
+                            is_deleted BOOLEAN DEFAULT FALSE,
+                            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                            updated_at TIMESTAMP NULL DEFAULT NULL
 );
 
 -- ลบตาราง menu_items (เมนูอาหาร) ถ้ามีอยู่
@@ -28,23 +65,71 @@ CREATE TABLE menu_items (
                             description TEXT,
                             price DECIMAL(10, 2) NOT NULL,
                             file_path VARCHAR(255),
+                            category_id INT NULL,
                             is_available BOOLEAN DEFAULT TRUE,
                             is_deleted BOOLEAN DEFAULT FALSE,
-                            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+                            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                            updated_at TIMESTAMP NULL DEFAULT NULL,
+                            FOREIGN KEY (category_id) REFERENCES categories(category_id) ON DELETE SET NULL
+);
+
+-- ลบตาราง table_sessions (รอบการใช้โต๊ะของลูกค้า) ถ้ามีอยู่
+DROP TABLE IF EXISTS table_sessions;
+
+-- สร้างตาราง table_sessions (รอบการใช้โต๊ะของลูกค้า ใช้ยืนยัน QR token)
+CREATE TABLE table_sessions (
+                                session_id INT AUTO_INCREMENT PRIMARY KEY,
+                                table_id INT NOT NULL,
+                                expires_at TIMESTAMP NOT NULL,
+                                closed_at TIMESTAMP NULL DEFAULT NULL,
+                                created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                FOREIGN KEY (table_id) REFERENCES tables(table_id) ON DELETE CASCADE
+);
+
+-- ลบตาราง checkouts (ประวัติการเช็คเอาท์โต๊ะ) ถ้ามีอยู่
+DROP TABLE IF EXISTS checkouts;
+
+-- สร้างตาราง checkouts (ประวัติการเช็คเอาท์โต๊ะ) เก็บสรุปรอบการใช้โต๊ะ ออเดอร์ของรอบถูกผูกด้วย orders.checkout_id
+-- forced คือปิดโต๊ะทั้งที่ยังมีออเดอร์ค้างชำระ พร้อมเหตุผล
+CREATE TABLE checkouts (
+                           checkout_id INT AUTO_INCREMENT PRIMARY KEY,
+                           table_id INT NOT NULL,
+                           session_id INT NULL,
+                           started_at TIMESTAMP NULL DEFAULT NULL,
+                           order_count INT NOT NULL DEFAULT 0,
+                           item_count INT NOT NULL DEFAULT 0,
+                           total_amount DECIMAL(10, 2) NOT NULL DEFAULT 0,
+                           paid_amount DECIMAL(10, 2) NOT NULL DEFAULT 0,
+                           refunded_amount DECIMAL(10, 2) NOT NULL DEFAULT 0,
+                           unpaid_amount DECIMAL(10, 2) NOT NULL DEFAULT 0,
+                           forced BOOLEAN NOT NULL DEFAULT FALSE,
+                           reason VARCHAR(255) NULL,
+                           closed_by INT NULL,
+                           created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                           KEY idx_checkouts_table (table_id, created_at),
+                           FOREIGN KEY (table_id) REFERENCES tables(table_id) ON DELETE CASCADE,
+                           FOREIGN KEY (session_id) REFERENCES table_sessions(session_id) ON DELETE SET NULL,
+                           FOREIGN KEY (closed_by) REFERENCES users(user_id) ON DELETE SET NULL
 );
 
 -- ลบตาราง orders (ออเดอร์) ถ้ามีอยู่
 DROP TABLE IF EXISTS orders;
 
--- สร้างตาราง orders (ออเดอร์)
+-- สร้างตาราง orders (ออเดอร์) origin_table_id คือโต๊ะเดิมของออเดอร์ที่ย้ายมาตอนรวมโต๊ะ checkout_id ว่างคือออเดอร์ของลูกค้าที่ยังนั่งอยู่ที่โต๊ะ
 CREATE TABLE orders (
                         order_id INT AUTO_INCREMENT PRIMARY KEY,
                         table_id INT,
-                        status ENUM('created', 'prepare', 'canceled', 'completed', 'paid') DEFAULT 'created',
+                        session_id INT NULL,
+                        origin_table_id INT NULL,
+                        checkout_id INT NULL,
+                        status ENUM('created', 'prepare', 'canceled', 'completed', 'paid', 'refunded') DEFAULT 'created',
                         created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
                         updated_at TIMESTAMP NULL DEFAULT NULL,
                         is_deleted BOOLEAN DEFAULT FALSE,
-                        FOREIGN KEY (table_id) REFERENCES tables(table_id) ON DELETE CASCADE
+                        FOREIGN KEY (table_id) REFERENCES tables(table_id) ON DELETE CASCADE,
+                        FOREIGN KEY (session_id) REFERENCES table_sessions(session_id) ON DELETE SET NULL,
+                        FOREIGN KEY (origin_table_id) REFERENCES tables(table_id) ON DELETE SET NULL,
+                        FOREIGN KEY (checkout_id) REFERENCES checkouts(checkout_id) ON DELETE SET NULL
 );
 
 -- ลบตาราง order_items (รายการออเดอร์) ถ้ามีอยู่
@@ -57,6 +142,8 @@ CREATE TABLE order_items (
                              menu_item_id INT,
                              quantity INT NOT NULL,
                              price DECIMAL(10, 2) NOT NULL,
+                             item_status ENUM('queued', 'cooking', 'ready', 'served', 'voided') DEFAULT 'queued',
+                             updated_at TIMESTAMP NULL DEFAULT NULL,
                              FOREIGN KEY (order_id) REFERENCES orders(order_id) ON DELETE CASCADE,
                              FOREIGN KEY (menu_item_id) REFERENCES menu_items(menu_items_id) ON DELETE CASCADE
 );
@@ -64,59 +151,273 @@ CREATE TABLE order_items (
 -- ลบตาราง bills (บิล) ถ้ามีอยู่
 DROP TABLE IF EXISTS bills;
 
--- สร้างตาราง bills (บิล)
+-- สร้างตาราง bills (บิล) total_amount คือยอดสุทธิหลังส่วนลด ค่าบริการ VAT และการปัดเศษ
 CREATE TABLE bills (
                        id INT AUTO_INCREMENT PRIMARY KEY,
                        order_id INT,
                        table_id INT,
+                       subtotal DECIMAL(10, 2) NOT NULL DEFAULT 0,
+                       discount DECIMAL(10, 2) NOT NULL DEFAULT 0,
+                       service_charge_rate DECIMAL(5, 2) NOT NULL DEFAULT 0,
+                       service_charge DECIMAL(10, 2) NOT NULL DEFAULT 0,
+                       vat_rate DECIMAL(5, 2) NOT NULL DEFAULT 0,
+                       vat_inclusive BOOLEAN NOT NULL DEFAULT FALSE,
+                       vat_amount DECIMAL(10, 2) NOT NULL DEFAULT 0,
+                       rounding DECIMAL(10, 2) NOT NULL DEFAULT 0,
                        total_amount DECIMAL(10, 2) NOT NULL,
+                       paid_amount DECIMAL(10, 2) NOT NULL DEFAULT 0,
+                       refunded_amount DECIMAL(10, 2) NOT NULL DEFAULT 0,
+                       status ENUM('open', 'settled', 'refunded', 'voided') DEFAULT 'open',
                        bill_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                       settled_at TIMESTAMP NULL DEFAULT NULL,
+                       UNIQUE KEY uq_bills_order (order_id),
                        FOREIGN KEY (order_id) REFERENCES orders(order_id) ON DELETE CASCADE,
                        FOREIGN KEY (table_id) REFERENCES tables(table_id) ON DELETE CASCADE
 );
 
+-- ลบตาราง promotions (โปรโมชั่นและคูปอง) ถ้ามีอยู่
+DROP TABLE IF EXISTS promotions;
+
+-- สร้างตาราง promotions (โปรโมชั่นและคูปอง) value เป็นเปอร์เซ็นต์หรือบาทตาม rule_type
+CREATE TABLE promotions (
+                            promotion_id INT AUTO_INCREMENT PRIMARY KEY,
+                            name VARCHAR(100) NOT NULL,
+                            rule_type ENUM('percent', 'fixed', 'item_percent', 'item_fixed', 'bogo') NOT NULL,
+                            value DECIMAL(10, 2) NOT NULL DEFAULT 0,
+                            menu_item_id INT NULL,
+                            min_subtotal DECIMAL(10, 2) NOT NULL DEFAULT 0,
+                            starts_at DATETIME NULL,
+                            ends_at DATETIME NULL,
+                            happy_hour_start TIME NULL,
+                            happy_hour_end TIME NULL,
+                            coupon_code VARCHAR(30) NULL,
+                            usage_limit INT NOT NULL DEFAULT 0,
+                            used_count INT NOT NULL DEFAULT 0,
+                            is_active BOOLEAN DEFAULT TRUE,
+                            is_deleted BOOLEAN DEFAULT FALSE,
+                            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                            updated_at TIMESTAMP NULL DEFAULT NULL,
+                            INDEX idx_promotions_coupon (coupon_code),
+                            FOREIGN KEY (menu_item_id) REFERENCES menu_items(menu_items_id) ON DELETE CASCADE
+);
+
+-- ลบตาราง bill_promotions (ประวัติโปรโมชั่นที่ใช้กับบิล) ถ้ามีอยู่
+DROP TABLE IF EXISTS bill_promotions;
+
+-- สร้างตาราง bill_promotions (ประวัติโปรโมชั่นที่ใช้กับบิล) เก็บชื่อและส่วนลด ณ เวลาที่ใช้
+CREATE TABLE bill_promotions (
+                                 id INT AUTO_INCREMENT PRIMARY KEY,
+                                 bill_id INT NOT NULL,
+                                 promotion_id INT NOT NULL,
+                                 name VARCHAR(100) NOT NULL,
+                                 rule_type VARCHAR(20) NOT NULL,
+                                 coupon_code VARCHAR(30) NULL,
+                                 discount_amount DECIMAL(10, 2) NOT NULL,
+                                 applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                 FOREIGN KEY (bill_id) REFERENCES bills(id) ON DELETE CASCADE,
+                                 FOREIGN KEY (promotion_id) REFERENCES promotions(promotion_id) ON DELETE CASCADE
+);
+
+-- ลบตาราง bill_items (ยอดของแต่ละรายการในบิล) ถ้ามีอยู่
+DROP TABLE IF EXISTS bill_items;
+
+-- สร้างตาราง bill_items (ยอดของแต่ละรายการในบิล) ส่วนของยอดรวมบิลที่แต่ละรายการรับไว้ หลังหักส่วนลด รวมค่าบริการ ภาษีและการปัดเศษ
+CREATE TABLE bill_items (
+                            bill_id INT NOT NULL,
+                            order_item_id INT NOT NULL,
+                            amount DECIMAL(10, 2) NOT NULL,
+                            PRIMARY KEY (bill_id, order_item_id),
+                            FOREIGN KEY (bill_id) REFERENCES bills(id) ON DELETE CASCADE,
+                            FOREIGN KEY (order_item_id) REFERENCES order_items(id) ON DELETE CASCADE
+);
+
+-- ลบตาราง payments (ยอดชำระย่อยของบิล) ถ้ามีอยู่
+DROP TABLE IF EXISTS payments;
+
+-- สร้างตาราง payments (ยอดชำระย่อยของบิล) หนึ่งบิลแบ่งจ่ายได้หลายรายการ บิลปิดเมื่อชำระครบ การคืนเงินเป็นยอดติดลบ
+CREATE TABLE payments (
+                          payment_id INT AUTO_INCREMENT PRIMARY KEY,
+                          bill_id INT NOT NULL,
+                          label VARCHAR(50) NOT NULL,
+                          split_mode ENUM('full', 'item', 'even', 'custom', 'refund', 'void') NOT NULL,
+                          amount DECIMAL(10, 2) NOT NULL,
+                          status ENUM('pending', 'paid', 'canceled') DEFAULT 'pending',
+                          created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                          paid_at TIMESTAMP NULL DEFAULT NULL,
+                          FOREIGN KEY (bill_id) REFERENCES bills(id) ON DELETE CASCADE
+);
+
+-- ลบตาราง payment_items (รายการอาหารที่อยู่ในยอดชำระย่อย) ถ้ามีอยู่
+DROP TABLE IF EXISTS payment_items;
+
+-- สร้างตาราง payment_items (รายการอาหารที่อยู่ในยอดชำระย่อย) ใช้กับการแบ่งจ่ายตามรายการ
+CREATE TABLE payment_items (
+                               payment_id INT NOT NULL,
+                               order_item_id INT NOT NULL,
+                               PRIMARY KEY (payment_id, order_item_id),
+                               FOREIGN KEY (payment_id) REFERENCES payments(payment_id) ON DELETE CASCADE,
+                               FOREIGN KEY (order_item_id) REFERENCES order_items(id) ON DELETE CASCADE
+);
+
+-- ลบตาราง payment_tenders (วิธีชำระเงินของแต่ละยอด) ถ้ามีอยู่
+DROP TABLE IF EXISTS payment_tenders;
+
+-- สร้างตาราง payment_tenders (วิธีชำระเงินของแต่ละยอด) หนึ่งยอดจ่ายผสมได้หลายวิธี จำนวนเงินเก็บเป็นบาท เงินคืนที่ยังรอผลจากผู้ให้บริการมีสถานะ pending
+CREATE TABLE payment_tenders (
+                                 tender_id INT AUTO_INCREMENT PRIMARY KEY,
+                                 payment_id INT NOT NULL,
+                                 method ENUM('cash', 'card', 'promptpay') NOT NULL,
+                                 amount DECIMAL(10, 2) NOT NULL,
+                                 tendered DECIMAL(10, 2) NOT NULL,
+                                 change_amount DECIMAL(10, 2) NOT NULL DEFAULT 0,
+                                 reference VARCHAR(100) NULL,
+                                 provider VARCHAR(30) NOT NULL,
+                                 refund_of INT NULL,
+                                 status ENUM('pending', 'settled', 'failed') NOT NULL DEFAULT 'settled',
+                                 created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                                 FOREIGN KEY (payment_id) REFERENCES payments(payment_id) ON DELETE CASCADE,
+                                 FOREIGN KEY (refund_of) REFERENCES payment_tenders(tender_id) ON DELETE CASCADE
+);
+
+-- ลบตาราง refunds (การคืนเงินและยกเลิกบิลหลังชำระ) ถ้ามีอยู่
+DROP TABLE IF EXISTS refunds;
+
+-- สร้างตาราง refunds (การคืนเงินและยกเลิกบิลหลังชำระ) ผูกกับยอดชำระติดลบและผู้อนุมัติ
+CREATE TABLE refunds (
+                         refund_id INT AUTO_INCREMENT PRIMARY KEY,
+                         bill_id INT NOT NULL,
+                         payment_id INT NOT NULL,
+                         kind ENUM('refund', 'void') NOT NULL,
+                         reason_code ENUM('customer_complaint', 'wrong_item', 'quality', 'duplicate_charge', 'operator_error', 'other') NOT NULL,
+                         note VARCHAR(255) NULL,
+                         amount DECIMAL(10, 2) NOT NULL,
+                         authorized_by INT NOT NULL,
+                         created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                         FOREIGN KEY (bill_id) REFERENCES bills(id) ON DELETE CASCADE,
+                         FOREIGN KEY (payment_id) REFERENCES payments(payment_id) ON DELETE CASCADE,
+                         FOREIGN KEY (authorized_by) REFERENCES users(user_id)
+);
+
+-- ลบตาราง refund_items (รายการอาหารที่ถูกคืนเงิน) ถ้ามีอยู่
+DROP TABLE IF EXISTS refund_items;
+
+-- สร้างตาราง refund_items (รายการอาหารที่ถูกคืนเงิน) หนึ่งรายการคืนเงินได้ครั้งเดียว
+CREATE TABLE refund_items (
+                              refund_id INT NOT NULL,
+                              order_item_id INT NOT NULL,
+                              amount DECIMAL(10, 2) NOT NULL,
+                              PRIMARY KEY (refund_id, order_item_id),
+                              UNIQUE KEY uq_refund_items_order_item (order_item_id),
+                              FOREIGN KEY (refund_id) REFERENCES refunds(refund_id) ON DELETE CASCADE,
+                              FOREIGN KEY (order_item_id) REFERENCES order_items(id) ON DELETE CASCADE
+);
+
 -- ลบตาราง reviews (รีวิว) ถ้ามีอยู่
 DROP TABLE IF EXISTS reviews;
 
--- สร้างตาราง reviews (รีวิว)
+-- สร้างตาราง reviews (รีวิว) menu_item_id ว่างคือรีวิวทั้งออเดอร์ มีค่าคือรีวิวรายจาน แต่ละจานในออเดอร์รีวิวได้ครั้งเดียว ร้านตอบกลับ ซ่อนหรือลบรีวิวที่ไม่เหมาะสมได้
 CREATE TABLE reviews (
                          id INT AUTO_INCREMENT PRIMARY KEY,
-#                          menu_item_id INT,
+                         menu_item_id INT NULL,
                          order_id INT,
                          rating INT CHECK (rating >= 1 AND rating <= 5),
                          comment TEXT,
+                         reply TEXT NULL,
+                         replied_by INT NULL,
+                         replied_at TIMESTAMP NULL DEFAULT NULL,
+                         is_hidden BOOLEAN DEFAULT FALSE,
+                         moderation_note VARCHAR(255) NULL,
+                         moderated_by INT NULL,
+                         moderated_at TIMESTAMP NULL DEFAULT NULL,
                          is_deleted BOOLEAN DEFAULT FALSE,
                          review_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-#                          FOREIGN KEY (menu_item_id) REFERENCES menu_items(menu_items_id) ON DELETE CASCADE,
-                         FOREIGN KEY (order_id) REFERENCES orders(order_id) ON DELETE CASCADE
+                         UNIQUE KEY uq_reviews_order_menu_item (order_id, menu_item_id),
+                         FOREIGN KEY (menu_item_id) REFERENCES menu_items(menu_items_id) ON DELETE CASCADE,
+                         FOREIGN KEY (order_id) REFERENCES orders(order_id) ON DELETE CASCADE,
+                         FOREIGN KEY (replied_by) REFERENCES users(user_id) ON DELETE SET NULL,
+                         FOREIGN KEY (moderated_by) REFERENCES users(user_id) ON DELETE SET NULL
+);
+
+-- ลบตาราง reservations (การจองโต๊ะ) ถ้ามีอยู่
+DROP TABLE IF EXISTS reservations;
+
+-- สร้างตาราง reservations (การจองโต๊ะ) เวลาจองเก็บเป็นเวลาท้องถิ่น โต๊ะเป็น reserved ก่อนเวลาจองและถูกปล่อยเมื่อลูกค้าไม่มา
+CREATE TABLE reservations (
+                              reservation_id INT AUTO_INCREMENT PRIMARY KEY,
+                              guest_name VARCHAR(100) NOT NULL,
+                              phone VARCHAR(20) NOT NULL,
+                              party_size INT NOT NULL,
+                              table_id INT NULL,
+                              reserved_at DATETIME NOT NULL,
+                              duration_minutes INT NOT NULL,
+                              status ENUM('booked', 'seated', 'completed', 'canceled', 'no_show') NOT NULL DEFAULT 'booked',
+                              note VARCHAR(255) NULL,
+                              created_by INT NULL,
+                              created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                              updated_at TIMESTAMP NULL DEFAULT NULL,
+                              KEY idx_reservations_table_time (table_id, reserved_at),
+                              KEY idx_reservations_status_time (status, reserved_at),
+                              FOREIGN KEY (table_id) REFERENCES tables(table_id) ON DELETE SET NULL,
+                              FOREIGN KEY (created_by) REFERENCES users(user_id) ON DELETE SET NULL
+);
+
+-- ลบตาราง waitlist (คิวลูกค้า walk-in) ถ้ามีอยู่
+DROP TABLE IF EXISTS waitlist;
+
+-- สร้างตาราง waitlist (คิวลูกค้า walk-in) table_id คือโต๊ะที่ว่างและแนะนำให้คิวนี้ หรือโต๊ะที่ได้นั่ง
+CREATE TABLE waitlist (
+                          waitlist_id INT AUTO_INCREMENT PRIMARY KEY,
+                          party_name VARCHAR(100) NOT NULL,
+                          phone VARCHAR(20) NOT NULL,
+                          party_size INT NOT NULL,
+                          status ENUM('waiting', 'notified', 'seated', 'left') NOT NULL DEFAULT 'waiting',
+                          table_id INT NULL,
+                          quoted_wait_minutes INT NOT NULL DEFAULT 0,
+                          note VARCHAR(255) NULL,
+                          created_by INT NULL,
+                          created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                          notified_at TIMESTAMP NULL DEFAULT NULL,
+                          seated_at TIMESTAMP NULL DEFAULT NULL,
+                          updated_at TIMESTAMP NULL DEFAULT NULL,
+                          KEY idx_waitlist_status (status, created_at),
+                          FOREIGN KEY (table_id) REFERENCES tables(table_id) ON DELETE SET NULL,
+                          FOREIGN KEY (created_by) REFERENCES users(user_id) ON DELETE SET NULL
 );
 
 -- ข้อมูลตัวอย่างสำหรับตาราง tables
-INSERT INTO tables (table_number, is_deleted) VALUES
-                                                   (1, FALSE),
-                                                   (2, FALSE),
-                                                   (3, FALSE),
-                                                   (4, FALSE),
-                                                   (5, FALSE);
+-- pos_x / pos_y คือตำแหน่งโต๊ะบนผังร้าน
+INSERT INTO tables (table_number, capacity, zone, pos_x, pos_y, is_deleted) VALUES
+                                                                               (1, 2, 'bar', 1, 1, FALSE),
+                                                                               (2, 2, 'bar', 3, 1, FALSE),
+                                                                               (3, 4, 'indoor', 2, 4, FALSE),
+                                                                               (4, 4, 'indoor', 5, 4, FALSE),
+                                                                               (5, 6, 'terrace', 8, 6, FALSE);
+
+-- ข้อมูลตัวอย่างสำหรับตาราง categories
+INSERT INTO categories (name, sort_order) VALUES
+                                              ('Appetizers', 1),
+                                              ('Soups', 2),
+                                              ('Mains', 3),
+                                              ('Desserts', 4);
 
 -- ข้อมูลตัวอย่างสำหรับตาราง menu_items (ราคาจะเป็นบาท)
 -- เพิ่มเมนูใหม่ 15 รายการ
 INSERT INTO menu_items (name, description, price, file_path, is_available, is_deleted) VALUES
-                                                                    ('Spaghetti Carbonara', 'Classic Italian pasta with creamy sauce', 150.00, 'K:\\IdeaProjects\\GoLand\\26Sep\\Restaurant\\assets\\images\\Spaghetti Carbonara.jpg',true, false),
-                                                                    ('Margherita Pizza', 'Traditional pizza with tomato, mozzarella, and basil', 200.00, 'K:\\IdeaProjects\\GoLand\\26Sep\\Restaurant\\assets\\images\\Margherita Pizza.jpg', true, false),
-                                                                    ('Caesar Salad', 'Crispy romaine lettuce with Caesar dressing', 120.00, 'K:\\IdeaProjects\\GoLand\\26Sep\\Restaurant\\assets\\images\\Caesar Salad.jpg',true, false),
-                                                                    ('Grilled Salmon', 'Fresh salmon grilled with herbs and lemon', 350.00, 'K:\\IdeaProjects\\GoLand\\26Sep\\Restaurant\\assets\\images\\Grilled Salmon.jpg',true, false),
-                                                                    ('Chicken Parmesan', 'Crispy chicken breast with marinara and mozzarella', 250.00, 'K:\\IdeaProjects\\GoLand\\26Sep\\Restaurant\\assets\\images\\Chicken Parmesan.jpg',true, false),
-                                                                    ('Beef Burger', 'Juicy beef patty with cheese and lettuce', 180.00, 'K:\\IdeaProjects\\GoLand\\26Sep\\Restaurant\\assets\\images\\Beef Burger.jpg',true, false),
-                                                                    ('French Fries', 'Golden and crispy fries', 80.00, 'K:\\IdeaProjects\\GoLand\\26Sep\\Restaurant\\assets\\images\\French Fries.jpg',true,false),
-                                                                    ('Vegetable Stir Fry', 'Mixed vegetables stir-fried with soy sauce', 140.00, 'K:\\IdeaProjects\\GoLand\\26Sep\\Restaurant\\assets\\images\\Vegetable Stir Fry.jpg',true, false),
-                                                                    ('Pad Thai', 'Classic Thai stir-fried noodles with shrimp', 150.00, 'K:\\IdeaProjects\\GoLand\\26Sep\\Restaurant\\assets\\images\\Pad Thai.jpg',true, false),
-                                                                    ('Tom Yum Soup', 'Spicy and sour Thai soup with shrimp', 180.00, 'K:\\IdeaProjects\\GoLand\\26Sep\\Restaurant\\assets\\images\\Tom Yum Soup.jpg',true, false),
-                                                                    ('Chicken Tikka Masala', 'Spicy chicken in creamy tomato sauce', 220.00, 'K:\\IdeaProjects\\GoLand\\26Sep\\Restaurant\\assets\\images\\Chicken Tikka Masala.jpg',true, false),
-                                                                    ('Sushi Platter', 'Assorted sushi with fresh fish and vegetables', 300.00, 'K:\\IdeaProjects\\GoLand\\26Sep\\Restaurant\\assets\\images\\Sushi Platter.jpg',true, false),
-                                                                    ('Ramen', 'Japanese noodle soup with pork and egg', 180.00, 'K:\\IdeaProjects\\GoLand\\26Sep\\Restaurant\\assets\\images\\Ramen.jpg',true, false),
-                                                                    ('Pancakes', 'Fluffy pancakes with syrup and butter', 100.00, 'K:\\IdeaProjects\\GoLand\\26Sep\\Restaurant\\assets\\images\\Pancakes.jpg',true, false),
-                                                                    ('Chocolate Cake', 'Rich chocolate cake with fudge icing', 90.00, 'K:\\IdeaProjects\\GoLand\\26Sep\\Restaurant\\assets\\images\\Chocolate Cake.jpg',true, false);
+                                                                    ('Spaghetti Carbonara', 'Classic Italian pasta with creamy sauce', 150.00, 'Spaghetti Carbonara.jpg',true, false),
+                                                                    ('Margherita Pizza', 'Traditional pizza with tomato, mozzarella, and basil', 200.00, 'Margherita Pizza.jpg', true, false),
+                                                                    ('Caesar Salad', 'Crispy romaine lettuce with Caesar dressing', 120.00, 'Caesar Salad.jpg',true, false),
+                                                                    ('Grilled Salmon', 'Fresh salmon grilled with herbs and lemon', 350.00, 'Grilled Salmon.jpg',true, false),
+                                                                    ('Chicken Parmesan', 'Crispy chicken breast with marinara and mozzarella', 250.00, 'Chicken Parmesan.jpg',true, false),
+                                                                    ('Beef Burger', 'Juicy beef patty with cheese and lettuce', 180.00, 'Beef Burger.jpg',true, false),
+                                                                    ('French Fries', 'Golden and crispy fries', 80.00, 'French Fries.jpg',true,false),
+                                                                    ('Vegetable Stir Fry', 'Mixed vegetables stir-fried with soy sauce', 140.00, 'Vegetable Stir Fry.jpg',true, false),
+                                                                    ('Pad Thai', 'Classic Thai stir-fried noodles with shrimp', 150.00, 'Pad Thai.jpg',true, false),
+                                                                    ('Tom Yum Soup', 'Spicy and sour Thai soup with shrimp', 180.00, 'Tom Yum Soup.jpg',true, false),
+                                                                    ('Chicken Tikka Masala', 'Spicy chicken in creamy tomato sauce', 220.00, 'Chicken Tikka Masala.jpg',true, false),
+                                                                    ('Sushi Platter', 'Assorted sushi with fresh fish and vegetables', 300.00, 'Sushi Platter.jpg',true, false),
+                                                                    ('Ramen', 'Japanese noodle soup with pork and egg', 180.00, 'Ramen.jpg',true, false),
+                                                                    ('Pancakes', 'Fluffy pancakes with syrup and butter', 100.00, 'Pancakes.jpg',true, false),
+                                                                    ('Chocolate Cake', 'Rich chocolate cake with fudge icing', 90.00, 'Chocolate Cake.jpg',true, false);
 
 -- ทำการอัปเดตค่า is_available ให้เป็น false สำหรับ 5 เมนูแบบสุ่ม
 UPDATE menu_items SET is_available = false WHERE name IN (
@@ -126,3 +427,14 @@ UPDATE menu_items SET is_available = false WHERE name IN (
                                                           'Tom Yum Soup',
                                                           'Pancakes'
     );
+
+-- จัดหมวดหมู่ให้เมนูตัวอย่าง
+UPDATE menu_items SET category_id = (SELECT category_id FROM categories WHERE name = 'Appetizers')
+WHERE name IN ('Caesar Salad', 'French Fries');
+UPDATE menu_items SET category_id = (SELECT category_id FROM categories WHERE name = 'Soups')
+WHERE name IN ('Tom Yum Soup', 'Ramen');
+UPDATE menu_items SET category_id = (SELECT category_id FROM categories WHERE name = 'Mains')
+WHERE name IN ('Spaghetti Carbonara', 'Margherita Pizza', 'Grilled Salmon', 'Chicken Parmesan', 'Beef Burger',
+               'Vegetable Stir Fry', 'Pad Thai', 'Chicken Tikka Masala', 'Sushi Platter');
+UPDATE menu_items SET category_id = (SELECT category_id FROM categories WHERE name = 'Desserts')
+WHERE name IN ('Pancakes', 'Chocolate Cake');
@@ -0,0 +1,40 @@
+package enums
+
+const (
+	BillOpen     = "open"
+	BillSettled  = "settled"
+	BillRefunded = "refunded"
+	BillVoided   = "voided"
+)
+
+const (
+	PaymentPending  = "pending"
+	PaymentPaid     = "paid"
+	PaymentCanceled = "canceled"
+)
+
+// A refund tender is pending until its provider has given the money back or refused to.
+const (
+	TenderPending = "pending"
+	TenderSettled = "settled"
+	TenderFailed  = "failed"
+)
+
+const (
+	SplitFull   = "full"
+	SplitItem   = "item"
+	SplitEven   = "even"
+	SplitCustom = "custom"
+	SplitRefund = "refund"
+	SplitVoid   = "void"
+)
+
+// IsSplitMode reports whether mode can be requested when splitting a bill, "full" is only used by
+// PayOrder and "refund" and "void" by RefundOrder.
+func IsSplitMode(mode string) bool {
+	switch mode {
+	case SplitItem, SplitEven, SplitCustom:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package enums
+
+const (
+	OrderCreated   = "created"
+	OrderPrepare   = "prepare"
+	OrderCompleted = "completed"
+	OrderPaid      = "paid"
+	OrderCanceled  = "canceled"
+	OrderRefunded  = "refunded"
+)
+
+// orderTransitions lists, for each order status, the statuses it may move to.
+// Orders move created -> prepare -> completed -> paid and may only be canceled before completion,
+// a paid order becomes refunded once all of its payment was given back.
+var orderTransitions = map[string][]string{
+	OrderCreated:   {OrderPrepare, OrderCanceled},
+	OrderPrepare:   {OrderCompleted, OrderCanceled},
+	OrderCompleted: {OrderPaid},
+	OrderPaid:      {OrderRefunded},
+	OrderCanceled:  {},
+	OrderRefunded:  {},
+}
+
+func IsOrderStatus(status string) bool {
+	_, ok := orderTransitions[status]
+	return ok
+}
+
+func CanTransitionOrder(from string, to string) bool {
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	ItemQueued  = "queued"
+	ItemCooking = "cooking"
+	ItemReady   = "ready"
+	ItemServed  = "served"
+	ItemVoided  = "voided"
+)
+
+// itemTransitions follows a dish through the kitchen, any dish not yet served may be voided.
+var itemTransitions = map[string][]string{
+	ItemQueued:  {ItemCooking, ItemVoided},
+	ItemCooking: {ItemReady, ItemVoided},
+	ItemReady:   {ItemServed, ItemVoided},
+	ItemServed:  {},
+	ItemVoided:  {},
+}
+
+func IsItemStatus(status string) bool {
+	_, ok := itemTransitions[status]
+	return ok
+}
+
+func CanTransitionItem(from string, to string) bool {
+	for _, next := range itemTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// DeriveOrderStatus computes the order status from the statuses of its items:
+// every item voided cancels the order, every remaining item served completes it,
+// any item past the queue means the kitchen is preparing it.
+func DeriveOrderStatus(itemStatuses []string) string {
+	active, served, started := 0, 0, 0
+	for _, status := range itemStatuses {
+		switch status {
+		case ItemVoided:
+			continue
+		case ItemServed:
+			served++
+			started++
+		case ItemCooking, ItemReady:
+			started++
+		}
+		active++
+	}
+	switch {
+	case active == 0:
+		return OrderCanceled
+	case served == active:
+		return OrderCompleted
+	case started > 0:
+		return OrderPrepare
+	default:
+		return OrderCreated
+	}
+}
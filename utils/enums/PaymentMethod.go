@@ -0,0 +1,15 @@
+package enums
+
+const (
+	MethodCash      = "cash"
+	MethodCard      = "card"
+	MethodPromptPay = "promptpay"
+)
+
+func IsPaymentMethod(method string) bool {
+	switch method {
+	case MethodCash, MethodCard, MethodPromptPay:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package enums
+
+const (
+	PromotionPercent     = "percent"
+	PromotionFixed       = "fixed"
+	PromotionItemPercent = "item_percent"
+	PromotionItemFixed   = "item_fixed"
+	PromotionBogo        = "bogo"
+)
+
+func IsPromotionType(ruleType string) bool {
+	switch ruleType {
+	case PromotionPercent, PromotionFixed, PromotionItemPercent, PromotionItemFixed, PromotionBogo:
+		return true
+	}
+	return false
+}
+
+// IsItemPromotion reports whether the rule only discounts one menu item.
+func IsItemPromotion(ruleType string) bool {
+	switch ruleType {
+	case PromotionItemPercent, PromotionItemFixed, PromotionBogo:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package enums
+
+const (
+	RefundKindRefund = "refund"
+	RefundKindVoid   = "void"
+)
+
+const (
+	ReasonCustomerComplaint = "customer_complaint"
+	ReasonWrongItem         = "wrong_item"
+	ReasonQuality           = "quality"
+	ReasonDuplicateCharge   = "duplicate_charge"
+	ReasonOperatorError     = "operator_error"
+	ReasonOther             = "other"
+)
+
+func IsRefundKind(kind string) bool {
+	return kind == RefundKindRefund || kind == RefundKindVoid
+}
+
+func IsRefundReason(reason string) bool {
+	switch reason {
+	case ReasonCustomerComplaint, ReasonWrongItem, ReasonQuality, ReasonDuplicateCharge, ReasonOperatorError, ReasonOther:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,17 @@
+package enums
+
+const (
+	ReportByDay   = "day"
+	ReportByWeek  = "week"
+	ReportByMonth = "month"
+	ReportByHour  = "hour"
+	ReportByTable = "table"
+)
+
+func IsReportGroup(groupBy string) bool {
+	switch groupBy {
+	case ReportByDay, ReportByWeek, ReportByMonth, ReportByHour, ReportByTable:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package enums
+
+const (
+	ReservationBooked    = "booked"
+	ReservationSeated    = "seated"
+	ReservationCompleted = "completed"
+	ReservationCanceled  = "canceled"
+	ReservationNoShow    = "no_show"
+)
+
+// reservationTransitions lists, for each reservation status, the statuses it may move to.
+// A booking is seated, canceled or marked as a no-show, a seated party completes its visit.
+var reservationTransitions = map[string][]string{
+	ReservationBooked:    {ReservationSeated, ReservationCanceled, ReservationNoShow},
+	ReservationSeated:    {ReservationCompleted},
+	ReservationCompleted: {},
+	ReservationCanceled:  {},
+	ReservationNoShow:    {},
+}
+
+func IsReservationStatus(status string) bool {
+	_, ok := reservationTransitions[status]
+	return ok
+}
+
+func CanTransitionReservation(from string, to string) bool {
+	for _, next := range reservationTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,16 @@
+package enums
+
+const (
+	RoleAdmin   = "admin"
+	RoleCashier = "cashier"
+	RoleKitchen = "kitchen"
+	RoleTable   = "table"
+)
+
+func IsRole(role string) bool {
+	switch role {
+	case RoleAdmin, RoleCashier, RoleKitchen, RoleTable:
+		return true
+	}
+	return false
+}
@@ -6,10 +6,14 @@ type StatusCode struct {
 }
 
 var (
-	Success  = StatusCode{"S0000", "Success"}
-	Invalid  = StatusCode{"I0001", "Invalid request"}
-	NotFound = StatusCode{"I0004", "Data not found"}
-	Error    = StatusCode{"E9999", "The system has a problem. Please contact the system administrator."}
+	Success           = StatusCode{"S0000", "Success"}
+	Invalid           = StatusCode{"I0001", "Invalid request"}
+	Unauthorized      = StatusCode{"I0002", "Unauthorized"}
+	Forbidden         = StatusCode{"I0003", "Permission denied"}
+	NotFound          = StatusCode{"I0004", "Data not found"}
+	InvalidTransition = StatusCode{"I0005", "Invalid order status transition"}
+	PaymentDeclined   = StatusCode{"I0006", "Payment declined"}
+	Error             = StatusCode{"E9999", "The system has a problem. Please contact the system administrator."}
 )
 
 func (s StatusCode) GetMessage() string {
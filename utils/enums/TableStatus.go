@@ -0,0 +1,7 @@
+package enums
+
+const (
+	TableAvailable = "available"
+	TableOccupied  = "occupied"
+	TableReserved  = "reserved"
+)
@@ -0,0 +1,19 @@
+package enums
+
+const (
+	ZoneIndoor  = "indoor"
+	ZoneTerrace = "terrace"
+	ZoneBar     = "bar"
+)
+
+// TableZones lists the dining room zones in floor plan order.
+var TableZones = []string{ZoneIndoor, ZoneTerrace, ZoneBar}
+
+func IsTableZone(zone string) bool {
+	for _, z := range TableZones {
+		if z == zone {
+			return true
+		}
+	}
+	return false
+}
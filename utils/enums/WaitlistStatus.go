@@ -0,0 +1,31 @@
+package enums
+
+const (
+	WaitlistWaiting  = "waiting"
+	WaitlistNotified = "notified"
+	WaitlistSeated   = "seated"
+	WaitlistLeft     = "left"
+)
+
+// waitlistTransitions lists, for each waitlist status, the statuses it may move to. A waiting party
+// is notified when a suitable table frees up, and is seated or leaves either way.
+var waitlistTransitions = map[string][]string{
+	WaitlistWaiting:  {WaitlistNotified, WaitlistSeated, WaitlistLeft},
+	WaitlistNotified: {WaitlistSeated, WaitlistLeft},
+	WaitlistSeated:   {},
+	WaitlistLeft:     {},
+}
+
+func IsWaitlistStatus(status string) bool {
+	_, ok := waitlistTransitions[status]
+	return ok
+}
+
+func CanTransitionWaitlist(from string, to string) bool {
+	for _, next := range waitlistTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}